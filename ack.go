@@ -0,0 +1,200 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// ackMinBackoff is how long an AckQueue waits before retrying a value
+	// its handler first fails to handle.
+	ackMinBackoff = 10 * time.Millisecond
+
+	// ackMaxBackoff is the longest an AckQueue waits between retries, the
+	// wait doubling with each failure until it is reached.
+	ackMaxBackoff = 5 * time.Second
+)
+
+// AckQueue holds the values published to a topic subscribed with
+// SubscribeAck until its handler acknowledges each of them.
+type AckQueue struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	queue   []interface{}
+	busy    bool
+	closed  bool
+	done    chan struct{}
+	bus     *Bus
+	stats   *topicStats
+	h       func(v interface{}) error
+	retries uint64
+}
+
+// On queues the value for the handler. It never blocks.
+func (q *AckQueue) On(b *Bus, t, v interface{}) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.queue = append(q.queue, v)
+	q.cond.Broadcast()
+}
+
+// run passes each queued value to the handler in turn, retrying it until the
+// handler acknowledges it, until the queue is closed.
+func (q *AckQueue) run() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	backoff := ackMinBackoff
+	for {
+		for len(q.queue) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			return
+		}
+
+		v := q.queue[0]
+		q.busy = true
+		q.lock.Unlock()
+		err := q.h(v)
+		if err != nil && !q.wait(backoff) {
+			q.lock.Lock()
+			return
+		}
+		q.lock.Lock()
+		q.busy = false
+		if q.closed {
+			// Unsubscribed while the handler was running
+			return
+		}
+
+		if err != nil {
+			q.retries++
+			if backoff *= 2; backoff > ackMaxBackoff {
+				backoff = ackMaxBackoff
+			}
+			continue
+		}
+		backoff = ackMinBackoff
+		q.queue[0] = nil
+		q.queue = q.queue[1:]
+		q.cond.Broadcast()
+	}
+}
+
+// wait waits for d to elapse before a retry, returning false if the queue
+// is closed first.
+func (q *AckQueue) wait(d time.Duration) bool {
+	expired, stop := q.bus.after(d)
+	defer stop()
+
+	select {
+	case <-expired:
+		return true
+	case <-q.done:
+		return false
+	}
+}
+
+// Len returns the number of values waiting to be acknowledged, including
+// any the handler is currently handling.
+func (q *AckQueue) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.queue)
+}
+
+// Retries returns the number of times the handler has failed to handle a
+// value, each of which was retried.
+func (q *AckQueue) Retries() uint64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.retries
+}
+
+// Drain blocks until every queued value has been acknowledged, returning
+// ErrTimeout if that does not happen within the given timeout. Values
+// published while it is waiting are also waited for. It returns immediately
+// once the queue has been unsubscribed.
+func (q *AckQueue) Drain(timeout time.Duration) error {
+	expired := false
+	t := q.bus.clock.AfterFunc(timeout, func() {
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		expired = true
+		q.cond.Broadcast()
+	})
+	defer t.Stop()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for (len(q.queue) > 0 || q.busy) && !q.closed {
+		if expired {
+			return ErrTimeout
+		}
+		q.cond.Wait()
+	}
+	return nil
+}
+
+// close stops the queue, discarding any values still waiting to be
+// acknowledged.
+func (q *AckQueue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.done)
+	q.stats.drop(uint64(len(q.queue)))
+	q.queue = nil
+	q.cond.Broadcast()
+}
+
+// SubscribeAck causes h to be called with each value published to the named
+// topic on this Bus, from a goroutine dedicated to the subscription, until h
+// acknowledges the value by returning nil. A value h fails to handle is
+// retried, waiting between attempts for a backoff that starts at 10ms and
+// doubles with each failure up to 5s, and the values after it wait their
+// turn, so h sees every value in the order it was published. Values are
+// queued without limit, so publishers are never held up by h.
+//
+// The returned AckQueue reports how many values are waiting, and can be
+// drained before shutdown. The values are not waited for by Drain or Close,
+// which would otherwise never return while h keeps failing, but Close stops
+// the goroutine. Removing the subscription, whether by the returned function,
+// UnsubscribeID, RemoveTopic or Reset, also stops it, discarding any values
+// not yet acknowledged, which are counted as dropped in the Stats of the
+// topic.
+func (b *Bus) SubscribeAck(topic interface{}, h func(v interface{}) error) (*AckQueue, UnsubscribeFunc) {
+	if h == nil {
+		panic(ErrNilHandler.Error())
+	}
+	if !b.validTopic(topic) {
+		panic(ErrInvalidTopic.Error())
+	}
+	q := &AckQueue{
+		done:  make(chan struct{}),
+		bus:   b,
+		stats: &b.state(b.qualify(topic)).stats,
+		h:     h,
+	}
+	q.cond = sync.NewCond(&q.lock)
+
+	// The goroutine is only started once the topic has been accepted
+	unsub := b.subscribeStopping(topic, q, q.close)
+	go q.run()
+	return q, unsub
+}
+
+// SubscribeAck causes h to be called with each value published to the named
+// topic on the default Bus until it acknowledges the value by returning nil.
+func SubscribeAck(topic interface{}, h func(v interface{}) error) (*AckQueue, UnsubscribeFunc) {
+	return getDefaultBus().SubscribeAck(topic, h)
+}
@@ -0,0 +1,136 @@
+package bus
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitPending waits for the clock to have n timers pending.
+func waitPending(t *testing.T, clock *FakeClock, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for clock.Pending() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("clock has %d timers pending, want %d", clock.Pending(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubscribeAck(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	bus := NewBus(WithClock(clock))
+
+	var lock sync.Mutex
+	var got []interface{}
+	failures := 2
+	q, unsub := bus.SubscribeAck("test", func(v interface{}) error {
+		lock.Lock()
+		defer lock.Unlock()
+		got = append(got, v)
+		if v == 1 && failures > 0 {
+			failures--
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	for i := 1; i <= 3; i++ {
+		n, err := bus.Publish("test", i)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+
+	// The first value is retried after 10ms, then 20ms, holding up the rest
+	waitPending(t, clock, 1)
+	assert.Equal(t, 3, q.Len())
+	clock.Advance(10 * time.Millisecond)
+	waitPending(t, clock, 1)
+	clock.Advance(15 * time.Millisecond)
+	assert.Equal(t, 1, clock.Pending(), "backoff should double")
+	clock.Advance(5 * time.Millisecond)
+
+	assert.NoError(t, q.Drain(time.Second))
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, uint64(2), q.Retries())
+	lock.Lock()
+	assert.Equal(t, []interface{}{1, 1, 1, 2, 3}, got, "values should be delivered in order")
+	lock.Unlock()
+
+	assert.True(t, unsub())
+	assert.False(t, unsub())
+	bus.Publish("test", 4)
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestSubscribeAckDrainTimeout(t *testing.T) {
+	bus := NewBus()
+	q, unsub := bus.SubscribeAck("test", func(v interface{}) error {
+		return errors.New("unavailable")
+	})
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+
+	assert.Equal(t, ErrTimeout, q.Drain(20*time.Millisecond))
+	assert.Equal(t, 2, q.Len())
+	assert.NoError(t, bus.Close(), "Close should not wait for unacknowledged values")
+
+	unsub()
+	assert.Equal(t, 0, q.Len())
+	assert.Equal(t, uint64(2), bus.Stats()["test"].DroppedCount, "discarded values should be counted")
+	assert.NoError(t, q.Drain(time.Second), "a closed queue should drain at once")
+}
+
+func TestSubscribeAckUnsubscribeWhileHandling(t *testing.T) {
+	bus := NewBus()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	q, unsub := bus.SubscribeAck("test", func(v interface{}) error {
+		close(entered)
+		<-release
+		return nil
+	})
+	bus.Publish("test", 1)
+
+	<-entered
+	assert.True(t, unsub())
+	close(release)
+
+	assert.NoError(t, q.Drain(time.Second))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestSubscribeAckRemoveTopic(t *testing.T) {
+	bus := NewBus()
+	before := runtime.NumGoroutine()
+	q, _ := bus.SubscribeAck("test", func(v interface{}) error {
+		return errors.New("unavailable")
+	})
+	bus.Publish("test", 1)
+
+	assert.Equal(t, 1, bus.RemoveTopic("test"))
+	waitGoroutines(before)
+	assert.True(t, runtime.NumGoroutine() <= before, "removing the topic should stop the worker")
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestSubscribeAckRejected(t *testing.T) {
+	bus := NewBus(WithDeclaredTopics("test"))
+	before := runtime.NumGoroutine()
+	ack := func(v interface{}) error { return nil }
+
+	assert.PanicsWithValue(t, "bus: invalid topic", func() {
+		bus.SubscribeAck([]int{}, ack)
+	})
+	assert.Panics(t, func() {
+		bus.SubscribeAck("undeclared", ack)
+	})
+	waitGoroutines(before)
+	assert.True(t, runtime.NumGoroutine() <= before, "rejected subscriptions should not start a goroutine")
+	assert.NoError(t, bus.Close(), "the lock should not be held after the panics")
+}
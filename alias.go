@@ -0,0 +1,60 @@
+package bus
+
+// Alias causes values published to the topic from on this Bus to be
+// published to the topic to instead, and so delivered to to's handlers. It
+// allows a topic to be renamed without updating every publisher. Aliases may
+// be chained, but Alias fails with ErrAliasCycle if the alias would lead
+// back to from. Aliasing a topic already aliased replaces the alias.
+func (b *Bus) Alias(from, to interface{}) error {
+	from, to = b.qualify(from), b.qualify(to)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.resolveLocked(to) == from {
+		return ErrAliasCycle
+	}
+	if b.aliases == nil {
+		b.aliases = make(map[interface{}]interface{})
+	}
+	b.aliases[from] = to
+	return nil
+}
+
+// Unalias removes the alias for the topic from, if any, so that values
+// published to from are once again delivered to its own handlers. It
+// reports whether there was an alias to remove.
+func (b *Bus) Unalias(from interface{}) bool {
+	from = b.qualify(from)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	_, ok := b.aliases[from]
+	delete(b.aliases, from)
+	return ok
+}
+
+// resolveLocked follows the aliases for the given qualified topic, returning
+// the topic values published to it should be delivered to. Alias prevents
+// cycles, so resolution always ends. It must be called with the lock held.
+func (b *Bus) resolveLocked(topic interface{}) interface{} {
+	for {
+		to, ok := b.aliases[topic]
+		if !ok {
+			return topic
+		}
+		topic = to
+	}
+}
+
+// Alias causes values published to the topic from on the default Bus to be
+// published to the topic to instead.
+func Alias(from, to interface{}) error {
+	return getDefaultBus().Alias(from, to)
+}
+
+// Unalias removes the alias for the topic from on the default Bus.
+func Unalias(from interface{}) bool {
+	return getDefaultBus().Unalias(from)
+}
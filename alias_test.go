@@ -0,0 +1,64 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlias(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.SubscribeFunc("user.registered", func(b *Bus, tp, v interface{}) {
+		got = append(got, tp)
+	})
+	old := 0
+	bus.SubscribeFunc("user.signup", func(b *Bus, tp, v interface{}) {
+		old++
+	})
+
+	assert.NoError(t, bus.Alias("user.signup", "user.registered"))
+	n, err := bus.Publish("user.signup", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{"user.registered"}, got, "handlers should be passed the resolved topic")
+	assert.Equal(t, 0, old, "aliased topic's own handlers should not be called")
+
+	assert.True(t, bus.Unalias("user.signup"))
+	assert.False(t, bus.Unalias("user.signup"))
+	bus.Publish("user.signup", 2)
+	assert.Equal(t, 1, old)
+	assert.Len(t, got, 1)
+}
+
+func TestAliasChain(t *testing.T) {
+	bus := NewBus()
+	count := 0
+	bus.SubscribeFunc("c", func(b *Bus, tp, v interface{}) {
+		count++
+	})
+
+	assert.NoError(t, bus.Alias("a", "b"))
+	assert.NoError(t, bus.Alias("b", "c"))
+	bus.Publish("a", 1)
+	assert.Equal(t, 1, count)
+
+	assert.Equal(t, ErrAliasCycle, bus.Alias("c", "a"))
+	assert.Equal(t, ErrAliasCycle, bus.Alias("c", "c"))
+	bus.Publish("c", 2)
+	assert.Equal(t, 2, count, "rejected aliases should not be added")
+}
+
+func TestAliasNamespace(t *testing.T) {
+	bus := NewBus()
+	count := 0
+	bus.SubscribeFunc("ns.new", func(b *Bus, tp, v interface{}) {
+		count++
+	})
+
+	ns := bus.Namespace("ns")
+	assert.NoError(t, ns.Alias("old", "new"))
+	bus.Publish("ns.old", 1)
+	ns.Publish("old", 2)
+	assert.Equal(t, 2, count)
+}
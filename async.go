@@ -0,0 +1,305 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tracker counts in-flight asynchronous work so that it can be waited upon.
+// Unlike a sync.WaitGroup, work may be added at any time, including while
+// another goroutine is waiting, and waiting only covers work that was added
+// before the wait began.
+//
+// Work is grouped into epochs: each call to wait begins a new epoch, and
+// waits only for work belonging to earlier epochs to complete.
+type tracker struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	epoch  uint64
+	counts map[uint64]int
+}
+
+// add records the start of a unit of work, returning the epoch that must be
+// passed to done when the work completes.
+func (t *tracker) add() uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.counts == nil {
+		t.counts = make(map[uint64]int)
+	}
+	t.counts[t.epoch]++
+	return t.epoch
+}
+
+// done records the end of a unit of work started in the given epoch.
+func (t *tracker) done(epoch uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.counts[epoch]--; t.counts[epoch] == 0 {
+		delete(t.counts, epoch)
+		if t.cond != nil {
+			t.cond.Broadcast()
+		}
+	}
+}
+
+// wait blocks until all work added before it was called has completed,
+// returning the number of units of work it waited for.
+func (t *tracker) wait() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.cond == nil {
+		t.cond = sync.NewCond(&t.lock)
+	}
+
+	// Work added from now on belongs to a later epoch
+	last := t.epoch
+	t.epoch++
+
+	n := 0
+	for _, c := range t.counts {
+		n += c
+	}
+
+	for t.pending(last) {
+		t.cond.Wait()
+	}
+	return n
+}
+
+// pending reports whether any work remains from epochs up to and including
+// the given one. It must be called with the lock held.
+func (t *tracker) pending(last uint64) bool {
+	for e := range t.counts {
+		if e <= last {
+			return true
+		}
+	}
+	return false
+}
+
+// gauge counts the goroutines running asynchronously called handlers, so
+// that callers can wait for there to be none.
+type gauge struct {
+	n    atomic.Int64
+	lock sync.Mutex
+
+	// idle, if set, is closed once n next drops to zero.
+	idle chan struct{}
+}
+
+// inc records the start of a goroutine.
+func (g *gauge) inc() {
+	g.n.Add(1)
+}
+
+// dec records the end of a goroutine.
+func (g *gauge) dec() {
+	if g.n.Add(-1) == 0 {
+		g.lock.Lock()
+		if g.idle != nil {
+			close(g.idle)
+			g.idle = nil
+		}
+		g.lock.Unlock()
+	}
+}
+
+// wait returns a channel that is closed the next time the count drops to
+// zero, or nil if it already is zero.
+func (g *gauge) wait() <-chan struct{} {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.n.Load() == 0 {
+		return nil
+	}
+	if g.idle == nil {
+		g.idle = make(chan struct{})
+	}
+	return g.idle
+}
+
+// goAsync calls the handler in a new goroutine, or on a worker of the Bus's
+// pool if it has one with room for it, waiting first for a slot to become
+// available if the Bus or topic limits its concurrency.
+func (b *Bus) goAsync(h Handler, t, v interface{}) {
+	// The topic's limit is waited for first, so that a handler held up by
+	// it does not take up room under the Bus's limit
+	tsem := b.topicSem
+	if tsem != nil {
+		tsem <- struct{}{}
+	}
+	if b.sem != nil {
+		b.sem <- struct{}{}
+	}
+	b.inflight.inc()
+
+	j := asyncJob{bus: b, h: h, topic: t, value: v, epoch: b.async.add(), tsem: tsem}
+	if b.pool != nil && b.pool.submit(j) {
+		return
+	}
+	go j.run()
+}
+
+// asyncHandler marks a handler subscribed with SubscribeAsync, which is
+// always called in its own goroutine.
+type asyncHandler struct {
+	h Handler
+}
+
+func (h *asyncHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *asyncHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *asyncHandler) OnErr(b *Bus, t, v interface{}) error {
+	return call(b, h.h, t, v)
+}
+
+func (h *asyncHandler) unwrap() Handler {
+	return h.h
+}
+
+// alwaysAsync reports whether the handler, or any handler it wraps, was
+// subscribed with SubscribeAsync.
+func alwaysAsync(h Handler) bool {
+	for h != nil {
+		if _, ok := h.(*asyncHandler); ok {
+			return true
+		}
+		w, ok := h.(wrapper)
+		if !ok {
+			return false
+		}
+		h = w.unwrap()
+	}
+	return false
+}
+
+// deliverSync calls the handler in the publishing goroutine, unless it was
+// subscribed with SubscribeAsync and the value was not published with
+// PublishSync, in which case it is called in a new one.
+func (b *Bus) deliverSync(h Handler, t, v interface{}) error {
+	if !b.sync && alwaysAsync(h) {
+		b.goAsync(h, t, v)
+		return nil
+	}
+	return b.Deliver(h, t, v)
+}
+
+// SubscribeAsync causes the passed Handler to be called in a new goroutine
+// whenever data is published to the named topic on this Bus, as though each
+// value were published with the Async flag, while the topic's other handlers
+// are called as the publisher chose. Such handlers are counted by Publish
+// and waited for by Drain and Close. Custom Dispatchers decide for
+// themselves how to call them.
+func (b *Bus) SubscribeAsync(topic interface{}, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	return b.Subscribe(topic, &asyncHandler{h: h})
+}
+
+// SubscribeAsync causes the passed Handler to be called in a new goroutine
+// whenever data is published to the named topic on the default Bus.
+func SubscribeAsync(topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeAsync(topic, h)
+}
+
+// Drain blocks until all handlers called asynchronously before Drain was
+// called have returned, all values queued with OrderedAsync before then
+// have been delivered, and all publishes scheduled with PublishAfter before
+// then have been made, returning the number of handlers, queued values and
+// scheduled publishes it waited for.
+// Unlike Close, the Bus remains usable, and handlers called while Drain is
+// waiting are not waited for.
+func (b *Bus) Drain() int {
+	return b.async.wait()
+}
+
+// InFlightAsync returns the number of handlers called asynchronously on this
+// Bus, with the Async flag or subscribed with SubscribeAsync, that are
+// currently running in goroutines of their own or, with WithPooledAsync,
+// running or waiting to be run by the pool's workers. Handlers still waiting
+// for a slot under WithMaxConcurrency or a topic's MaxConcurrency are not
+// counted, and nor are values queued with OrderedAsync or SubscribeBuffered.
+// It is intended for debugging, such as for spotting handlers that never
+// return.
+func (b *Bus) InFlightAsync() int {
+	return int(b.inflight.n.Load())
+}
+
+// WaitUntilIdle blocks until InFlightAsync would return zero, returning
+// ErrTimeout if that does not happen within the given timeout. Unlike Drain,
+// it also waits for handlers started while it is waiting.
+func (b *Bus) WaitUntilIdle(timeout time.Duration) error {
+	expired, stop := b.after(timeout)
+	defer stop()
+
+	for {
+		idle := b.inflight.wait()
+		if idle == nil {
+			return nil
+		}
+		select {
+		case <-idle:
+		case <-expired:
+			return ErrTimeout
+		}
+	}
+}
+
+// Flush blocks until the Bus has no asynchronously called handlers running
+// and no values queued with OrderedAsync. Unlike Drain, it also waits for
+// handlers started by other handlers while it is waiting, so once it returns,
+// the effects of everything published before it was called, with any flags,
+// are complete. Flush may never return while other goroutines continue to
+// publish asynchronously. Unlike Close, the Bus remains usable.
+func (b *Bus) Flush() {
+	for b.async.wait() > 0 {
+	}
+}
+
+// PublishAsyncWG sends the given value to all handlers subscribed to the
+// named topic on this Bus, calling each in a separate goroutine as Publish
+// does with the Async flag. The WaitGroup is incremented for each handler
+// before it is started, and decremented when it returns, so that callers can
+// wait for the handlers of many publishes together. The caller is responsible
+// for the WaitGroup, which must not be reused until Wait has returned.
+func (b *Bus) PublishAsyncWG(wg *sync.WaitGroup, topic, value interface{}) (int, error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return 0, err
+	}
+	d.accepted = func() { wg.Add(1) }
+	d.delivered = wg.Done
+	return b.publish(d, Async)
+}
+
+// InFlightAsync returns the number of handlers called asynchronously on the
+// default Bus that are currently running.
+func InFlightAsync() int {
+	return getDefaultBus().InFlightAsync()
+}
+
+// WaitUntilIdle blocks until no handlers called asynchronously on the default
+// Bus are running, or the timeout expires.
+func WaitUntilIdle(timeout time.Duration) error {
+	return getDefaultBus().WaitUntilIdle(timeout)
+}
+
+// PublishAsyncWG sends the given value to all handlers subscribed to the
+// named topic on the default Bus, each in a separate goroutine, tracking them
+// with the given WaitGroup.
+func PublishAsyncWG(wg *sync.WaitGroup, topic, value interface{}) (int, error) {
+	return getDefaultBus().PublishAsyncWG(wg, topic, value)
+}
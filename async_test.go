@@ -0,0 +1,180 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrain(t *testing.T) {
+	bus := NewBus()
+	var c int32
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&c, 1)
+	})
+
+	for i := 0; i < 3; i++ {
+		bus.Publish("test", i, Async)
+	}
+	assert.Equal(t, 3, bus.Drain(), "drain should wait for three handlers")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&c))
+	assert.Equal(t, 0, bus.Drain(), "nothing should be left to drain")
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err, "bus should remain usable after drain")
+	assert.Equal(t, 1, n)
+}
+
+// TestDrainConcurrentPublish checks that Drain returns even while new
+// handlers are continually being started.
+func TestDrainConcurrentPublish(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		time.Sleep(time.Millisecond)
+	})
+
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				bus.Publish("test", nil, Async)
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		bus.Drain()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("drain waited for handlers started after it was called")
+	}
+	close(stop)
+	bus.Close()
+}
+
+func TestPublishAsyncWG(t *testing.T) {
+	bus := NewBus()
+	var c atomic.Int32
+	for i := 0; i < 3; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+			time.Sleep(5 * time.Millisecond)
+			c.Add(1)
+		})
+	}
+	bus.SubscribeFunc("other", func(b *Bus, tp, v interface{}) {
+		time.Sleep(5 * time.Millisecond)
+		c.Add(1)
+	})
+
+	var wg sync.WaitGroup
+	n, err := bus.PublishAsyncWG(&wg, "test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, _ = bus.PublishAsyncWG(&wg, "other", 2)
+	assert.Equal(t, 1, n)
+
+	wg.Wait()
+	assert.Equal(t, int32(4), c.Load())
+}
+
+func TestFlush(t *testing.T) {
+	bus := NewBus()
+	var got atomic.Value
+	bus.SubscribeFunc("first", func(b *Bus, tp, v interface{}) {
+		time.Sleep(5 * time.Millisecond)
+		b.Publish("second", v, Async)
+	})
+	bus.SubscribeFunc("second", func(b *Bus, tp, v interface{}) {
+		time.Sleep(5 * time.Millisecond)
+		b.Publish("third", v, OrderedAsync)
+	})
+	bus.SubscribeFunc("third", func(b *Bus, tp, v interface{}) {
+		time.Sleep(5 * time.Millisecond)
+		got.Store(v)
+	})
+
+	bus.Publish("first", "value", Async)
+	bus.Flush()
+	assert.Equal(t, "value", got.Load(), "chained handlers should complete before Flush returns")
+
+	n, err := bus.Publish("first", "again")
+	assert.NoError(t, err, "bus should remain usable after flush")
+	assert.Equal(t, 1, n)
+	bus.Flush()
+	assert.Equal(t, "again", got.Load())
+}
+
+func TestSubscribeAsync(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	var done atomic.Bool
+	bus.SubscribeAsync("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		<-release
+		done.Store(true)
+	}))
+	called := false
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		called = true
+	})
+
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n, "async handler should be counted")
+	assert.True(t, called, "other handlers should be called synchronously")
+	assert.False(t, done.Load())
+
+	close(release)
+	assert.Equal(t, 1, bus.Drain())
+	assert.True(t, done.Load())
+}
+
+func TestSubscribeAsyncSingle(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	bus.SubscribeAsync("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		<-release
+	}))
+
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err, "publish should not wait for the handler")
+	assert.Equal(t, 1, n)
+	close(release)
+	assert.NoError(t, bus.Close())
+}
+
+func TestInFlightAsync(t *testing.T) {
+	bus := NewBus(WithPanicPolicy(PanicRecover, nil))
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(2)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		started.Done()
+		<-release
+		if v == "panic" {
+			panic(v)
+		}
+	})
+	assert.Equal(t, 0, bus.InFlightAsync())
+	assert.NoError(t, bus.WaitUntilIdle(time.Second))
+
+	bus.Publish("test", "ok", Async)
+	bus.Publish("test", "panic", Async)
+	started.Wait()
+	assert.Equal(t, 2, bus.InFlightAsync())
+	assert.Equal(t, ErrTimeout, bus.WaitUntilIdle(10*time.Millisecond))
+
+	close(release)
+	assert.NoError(t, bus.WaitUntilIdle(time.Second))
+	assert.Equal(t, 0, bus.InFlightAsync(), "panicking handlers should be counted out")
+}
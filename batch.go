@@ -0,0 +1,130 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// batchHandler collects the values it receives, passing them on to its
+// function as a slice once enough have been collected or enough time has
+// passed.
+type batchHandler struct {
+	lock     sync.Mutex
+	maxCount int
+	maxWait  time.Duration
+	timer    Timer
+	gen      uint64
+	epoch    uint64
+	bus      *Bus
+	topic    interface{}
+	batch    []interface{}
+	closed   bool
+	async    *tracker
+	fn       func(b *Bus, t interface{}, batch []interface{})
+}
+
+func (h *batchHandler) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	if h.closed {
+		h.lock.Unlock()
+		return
+	}
+	if len(h.batch) == 0 {
+		h.bus, h.topic = b, t
+		if h.maxWait > 0 {
+			// Only a batch that will time out is waited for, as one
+			// limited by count alone may never fill up
+			h.epoch = h.async.add()
+			gen := h.gen
+			h.timer = b.clock.AfterFunc(h.maxWait, func() {
+				h.lock.Lock()
+				if h.gen != gen {
+					// The batch has already been flushed
+					h.lock.Unlock()
+					return
+				}
+				h.flushLocked()
+			})
+		}
+	}
+	h.batch = append(h.batch, v)
+	if h.maxCount > 0 && len(h.batch) >= h.maxCount {
+		h.flushLocked()
+		return
+	}
+	h.lock.Unlock()
+}
+
+// flushLocked passes the collected values to the function, if there are
+// any. It must be called with the lock held, which it releases.
+func (h *batchHandler) flushLocked() {
+	if len(h.batch) == 0 {
+		h.lock.Unlock()
+		return
+	}
+	b, t, batch, epoch, timed := h.bus, h.topic, h.batch, h.epoch, h.maxWait > 0
+	h.batch = nil
+	h.gen++
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	h.lock.Unlock()
+
+	if timed {
+		defer h.async.done(epoch)
+	}
+	h.fn(b, t, batch)
+}
+
+// close stops the handler, flushing any values already collected.
+func (h *batchHandler) close() {
+	h.lock.Lock()
+	if h.closed {
+		h.lock.Unlock()
+		return
+	}
+	h.closed = true
+	h.flushLocked()
+}
+
+// SubscribeBatch causes h to be called with the values published to the
+// named topic on this Bus in batches, so that they can be handled together,
+// such as by writing them to a database in one go. Values are collected
+// until maxCount of them have been published or maxWait has passed since the
+// first of them was, whichever comes first, and then passed to h in the order
+// they were published. A maxCount less than 1 limits batches by time only,
+// and a maxWait of 0 by count only; if neither is set, each value is passed
+// on in a batch of its own.
+//
+// A batch that fills up is passed to h by the publisher that filled it; one
+// that times out is passed to h from a goroutine of its own, so calls to h
+// may overlap. Values waiting in a batch limited by time are waited for by
+// Drain and Close; those in a batch limited by count alone are not, as it may
+// never fill up, but Close passes them to h once other handlers have
+// returned. Removing the subscription, whether by the returned function,
+// UnsubscribeID, RemoveTopic or Reset, passes any values already collected to
+// h before returning.
+func (b *Bus) SubscribeBatch(topic interface{}, maxCount int, maxWait time.Duration, h func(b *Bus, t interface{}, batch []interface{})) UnsubscribeFunc {
+	if h == nil {
+		panic(ErrNilHandler.Error())
+	}
+	if maxCount < 1 && maxWait <= 0 {
+		maxCount = 1
+	}
+	bh := &batchHandler{
+		maxCount: maxCount,
+		maxWait:  maxWait,
+		async:    &b.async,
+		fn:       h,
+	}
+
+	return b.subscribeStopping(topic, bh, bh.close)
+}
+
+// SubscribeBatch causes h to be called with the values published to the
+// named topic on the default Bus in batches of up to maxCount values, or
+// those published within maxWait of the first value in the batch.
+func SubscribeBatch(topic interface{}, maxCount int, maxWait time.Duration, h func(b *Bus, t interface{}, batch []interface{})) UnsubscribeFunc {
+	return getDefaultBus().SubscribeBatch(topic, maxCount, maxWait, h)
+}
@@ -0,0 +1,87 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchRecorder records the batches it is passed.
+type batchRecorder struct {
+	lock    sync.Mutex
+	batches [][]interface{}
+}
+
+func (r *batchRecorder) On(b *Bus, t interface{}, batch []interface{}) {
+	r.lock.Lock()
+	r.batches = append(r.batches, batch)
+	r.lock.Unlock()
+}
+
+func (r *batchRecorder) get() [][]interface{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([][]interface{}(nil), r.batches...)
+}
+
+func TestSubscribeBatchCount(t *testing.T) {
+	bus := NewBus()
+	r := &batchRecorder{}
+	bus.SubscribeBatch("test", 3, time.Hour, r.On)
+
+	for i := 1; i <= 7; i++ {
+		n, err := bus.Publish("test", i)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	assert.Equal(t, [][]interface{}{{1, 2, 3}, {4, 5, 6}}, r.get(), "full batches should be passed on immediately")
+}
+
+func TestSubscribeBatchWait(t *testing.T) {
+	bus := NewBus()
+	r := &batchRecorder{}
+	bus.SubscribeBatch("test", 10, 10*time.Millisecond, r.On)
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+	assert.Equal(t, 1, bus.Drain(), "drain should wait for the batch")
+	bus.Publish("test", 3)
+	bus.Drain()
+
+	assert.Equal(t, [][]interface{}{{1, 2}, {3}}, r.get())
+}
+
+func TestSubscribeBatchUnsubscribe(t *testing.T) {
+	bus := NewBus()
+	r := &batchRecorder{}
+	unsub := bus.SubscribeBatch("test", 10, time.Hour, r.On)
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+	assert.True(t, unsub())
+	assert.Equal(t, [][]interface{}{{1, 2}}, r.get(), "partial batch should be flushed")
+	assert.Equal(t, 0, bus.Drain())
+
+	bus.Publish("test", 3)
+	assert.Len(t, r.get(), 1)
+}
+
+func TestSubscribeBatchCountOnly(t *testing.T) {
+	bus := NewBus()
+	r := &batchRecorder{}
+	bus.SubscribeBatch("test", 3, 0, r.On)
+
+	bus.Publish("test", 1)
+	assert.Equal(t, 0, bus.Drain(), "drain should not wait for a batch that may never fill")
+
+	bus.Publish("test", 2)
+	assert.Equal(t, 1, bus.RemoveTopic("test"))
+	assert.Equal(t, [][]interface{}{{1, 2}}, r.get(), "removing the topic should flush the batch")
+
+	bus.SubscribeBatch("test", 3, 0, r.On)
+	bus.Publish("test", 3)
+	assert.NoError(t, bus.Close())
+	assert.Equal(t, [][]interface{}{{1, 2}, {3}}, r.get(), "closing should flush the batch")
+}
@@ -0,0 +1,32 @@
+package bus
+
+import (
+	"strings"
+)
+
+// bubbleLocked returns the subscriptions ss of the given topic followed by
+// those of each of its parent topics, nearest first. It must be called with
+// the lock held.
+func (b *Bus) bubbleLocked(topic interface{}, ss []*subscription) []*subscription {
+	s, ok := topic.(string)
+	if !ok {
+		return ss
+	}
+
+	copied := false
+	for i := strings.LastIndexByte(s, '.'); i >= 0; i = strings.LastIndexByte(s, '.') {
+		s = s[:i]
+		ps := b.topics[s]
+		if len(ps) == 0 {
+			continue
+		}
+		if !copied {
+			// Never append to the topic's own list
+			ss = append(ss[:len(ss):len(ss)], ps...)
+			copied = true
+		} else {
+			ss = append(ss, ps...)
+		}
+	}
+	return ss
+}
@@ -0,0 +1,42 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBubbling(t *testing.T) {
+	bus := NewBus(WithBubbling())
+	var got []string
+	for _, topic := range []string{"a", "a.b", "a.b.c", "a.x"} {
+		topic := topic
+		bus.SubscribeFunc(topic, func(b *Bus, tp, v interface{}) {
+			got = append(got, topic+":"+tp.(string))
+		})
+	}
+
+	n, err := bus.Publish("a.b.c", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n, "all levels should be counted")
+	assert.Equal(t, []string{"a.b.c:a.b.c", "a.b:a.b.c", "a:a.b.c"}, got)
+
+	got = nil
+	n, _ = bus.Publish("a.b.d", 2)
+	assert.Equal(t, 2, n, "parents should be notified without a handler at the leaf")
+	assert.Equal(t, []string{"a.b:a.b.d", "a:a.b.d"}, got)
+}
+
+func TestBubblingDisabled(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) {})
+	n, _ := bus.Publish("a.b", 1)
+	assert.Equal(t, 0, n)
+}
+
+func TestBubblingNonString(t *testing.T) {
+	bus := NewBus(WithBubbling())
+	bus.SubscribeFunc(1, func(b *Bus, tp, v interface{}) {})
+	n, _ := bus.Publish(1, 1)
+	assert.Equal(t, 1, n)
+}
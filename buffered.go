@@ -0,0 +1,232 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Overflow determines which value SubscribeBufferedOverflow drops when a
+// value is published while the buffer is full.
+type Overflow int
+
+const (
+	// DropNewest drops the value being published, keeping those already
+	// buffered.
+	DropNewest Overflow = iota
+
+	// DropOldest drops the value that has been buffered longest to make room
+	// for the value being published.
+	DropOldest
+)
+
+// bufferedValue is a value waiting to be passed to a buffered handler.
+type bufferedValue struct {
+	bus   *Bus
+	topic interface{}
+	value interface{}
+	epoch uint64
+}
+
+// bufferedHandler queues the values it receives, passing them on to its
+// handler in order from a goroutine of its own.
+type bufferedHandler struct {
+	lock     sync.Mutex
+	cond     *sync.Cond
+	queue    []bufferedValue
+	size     int
+	overflow Overflow
+	closed   bool
+	busy     bool
+	stats    *topicStats
+	async    *tracker
+	h        Handler
+
+	// dropped, if set, also counts the values dropped by this handler.
+	dropped *atomic.Uint64
+
+	// completed, if set, holds the Bus and topic to pass to the handler's
+	// OnComplete once the buffer has been emptied.
+	completed *bufferedValue
+}
+
+func (h *bufferedHandler) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed || h.completed != nil {
+		return
+	}
+	if len(h.queue) == h.size {
+		h.drop(1)
+		if h.overflow == DropNewest {
+			return
+		}
+		h.async.done(h.queue[0].epoch)
+		h.queue = h.queue[1:]
+	}
+	h.queue = append(h.queue, bufferedValue{bus: b, topic: t, value: v, epoch: h.async.add()})
+	h.cond.Broadcast()
+}
+
+func (h *bufferedHandler) unwrap() Handler {
+	return h.h
+}
+
+// run passes each buffered value to the handler in turn until the handler
+// is closed or completed.
+func (h *bufferedHandler) run() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for {
+		for len(h.queue) == 0 && !h.closed && h.completed == nil {
+			h.cond.Wait()
+		}
+		if h.closed {
+			return
+		}
+		if len(h.queue) == 0 {
+			// Buffer has been emptied since the topic was completed
+			h.closed = true
+			h.lock.Unlock()
+			complete(h.completed.bus, h.h, h.completed.topic)
+			h.lock.Lock()
+			return
+		}
+
+		bv := h.queue[0]
+		h.queue = h.queue[1:]
+		h.busy = true
+		h.lock.Unlock()
+		bv.bus.reportAsync(bv.bus.Deliver(h.h, bv.topic, bv.value))
+		h.async.done(bv.epoch)
+		h.lock.Lock()
+		h.busy = false
+		h.cond.Broadcast()
+	}
+}
+
+// drain waits until every buffered value has been passed to the handler and
+// the handler has returned, or the handler has been closed.
+func (h *bufferedHandler) drain() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for (len(h.queue) > 0 || h.busy) && !h.closed {
+		h.cond.Wait()
+	}
+}
+
+// drop counts n values as dropped by the handler.
+func (h *bufferedHandler) drop(n uint64) {
+	h.stats.drop(n)
+	if h.dropped != nil {
+		h.dropped.Add(n)
+	}
+}
+
+// close stops the handler, discarding any values still buffered.
+func (h *bufferedHandler) close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, bv := range h.queue {
+		h.drop(1)
+		h.async.done(bv.epoch)
+	}
+	h.queue = nil
+	h.cond.Broadcast()
+}
+
+// stop stops the handler once its subscription has been removed. A handler
+// whose topic has been completed is instead left to pass on the values
+// already buffered, after which it stops by itself.
+func (h *bufferedHandler) stop() {
+	h.lock.Lock()
+	completed := h.completed != nil
+	h.lock.Unlock()
+
+	if !completed {
+		h.close()
+	}
+}
+
+// SubscribeBuffered causes the passed Handler to be called with each value
+// published to the named topic on this Bus from a goroutine dedicated to the
+// subscription, so that a slow handler does not hold up publishers. Values
+// are buffered until the handler is ready for them, and passed to it in the
+// order they were published. Values published while bufSize values are
+// already buffered are dropped, and counted in the Stats of the topic.
+//
+// Buffered values are waited for by Drain and Close, after which Close stops
+// the goroutine. Removing the subscription, whether by the returned function,
+// UnsubscribeID, RemoveTopic or Reset, also stops it, discarding any values
+// still buffered.
+func (b *Bus) SubscribeBuffered(topic interface{}, bufSize int, h Handler) UnsubscribeFunc {
+	return b.SubscribeBufferedOverflow(topic, bufSize, DropNewest, h)
+}
+
+// SubscribeBufferedOverflow is like SubscribeBuffered, but overflow
+// determines whether the value being published or the oldest buffered value
+// is dropped when the buffer is full. A bufSize less than 1 is treated as 1.
+func (b *Bus) SubscribeBufferedOverflow(topic interface{}, bufSize int, overflow Overflow, h Handler) UnsubscribeFunc {
+	return b.subscribeBuffered(topic, bufSize, overflow, h, nil)
+}
+
+// subscribeBuffered subscribes a buffered handler as SubscribeBufferedOverflow
+// does, also counting the values it drops in dropped if set.
+func (b *Bus) subscribeBuffered(topic interface{}, bufSize int, overflow Overflow, h Handler, dropped *atomic.Uint64) UnsubscribeFunc {
+	mustHandler(h)
+	if !b.validTopic(topic) {
+		panic(ErrInvalidTopic.Error())
+	}
+	if bufSize < 1 {
+		bufSize = 1
+	}
+	bh := &bufferedHandler{
+		size:     bufSize,
+		overflow: overflow,
+		stats:    &b.state(b.qualify(topic)).stats,
+		async:    &b.async,
+		h:        h,
+		dropped:  dropped,
+	}
+	bh.cond = sync.NewCond(&bh.lock)
+
+	// The goroutine is only started once the topic has been accepted
+	unsub := b.subscribeStopping(topic, bh, bh.stop)
+	go bh.run()
+	return unsub
+}
+
+// SubscribeLatest causes the passed Handler to be called from a goroutine
+// dedicated to the subscription with the latest value published to the named
+// topic on this Bus. While the handler is busy, only the most recent value is
+// kept for it, so a handler still handling one value when two more are
+// published is next called with only the second of them. Superseded values
+// are counted as dropped in the Stats of the topic.
+//
+// It is equivalent to SubscribeBufferedOverflow with a bufSize of 1 and
+// DropOldest, and is waited for by Drain and Close in the same way.
+// Unsubscribing stops the goroutine, discarding any value waiting for it.
+func (b *Bus) SubscribeLatest(topic interface{}, h Handler) UnsubscribeFunc {
+	return b.SubscribeBufferedOverflow(topic, 1, DropOldest, h)
+}
+
+// SubscribeBuffered causes the passed Handler to be called with each value
+// published to the named topic on the default Bus from a goroutine dedicated
+// to the subscription.
+func SubscribeBuffered(topic interface{}, bufSize int, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeBuffered(topic, bufSize, h)
+}
+
+// SubscribeLatest causes the passed Handler to be called with the latest
+// value published to the named topic on the default Bus from a goroutine
+// dedicated to the subscription.
+func SubscribeLatest(topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeLatest(topic, h)
+}
@@ -0,0 +1,163 @@
+package bus
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingRecorder records the values it is passed, blocking each call until
+// release is closed. The first value it receives is signalled on entered.
+type blockingRecorder struct {
+	lock    sync.Mutex
+	got     []interface{}
+	entered chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func newBlockingRecorder() *blockingRecorder {
+	return &blockingRecorder{
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+func (h *blockingRecorder) On(b *Bus, t, v interface{}) {
+	h.once.Do(func() { close(h.entered) })
+	<-h.release
+	h.lock.Lock()
+	h.got = append(h.got, v)
+	h.lock.Unlock()
+}
+
+func (h *blockingRecorder) values() []interface{} {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]interface{}(nil), h.got...)
+}
+
+func TestSubscribeBuffered(t *testing.T) {
+	bus := NewBus()
+	h := newBlockingRecorder()
+	unsub := bus.SubscribeBuffered("test", 2, h)
+
+	// The first value is taken by the handler, the next two are buffered
+	// and the rest are dropped
+	bus.Publish("test", 1)
+	<-h.entered
+	for i := 2; i <= 5; i++ {
+		n, err := bus.Publish("test", i)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n, "publish should not wait for the handler")
+	}
+	close(h.release)
+	bus.Drain()
+
+	assert.Equal(t, []interface{}{1, 2, 3}, h.values())
+	assert.Equal(t, uint64(2), bus.Stats()["test"].DroppedCount)
+
+	assert.True(t, unsub())
+	bus.Publish("test", 6)
+	bus.Drain()
+	assert.Len(t, h.values(), 3, "unsubscribed handler should not be called")
+}
+
+func TestSubscribeBufferedDropOldest(t *testing.T) {
+	bus := NewBus()
+	h := newBlockingRecorder()
+	bus.SubscribeBufferedOverflow("test", 2, DropOldest, h)
+
+	bus.Publish("test", 1)
+	<-h.entered
+	for i := 2; i <= 5; i++ {
+		bus.Publish("test", i)
+	}
+	close(h.release)
+	bus.Drain()
+
+	assert.Equal(t, []interface{}{1, 4, 5}, h.values())
+	assert.Equal(t, uint64(2), bus.Stats()["test"].DroppedCount)
+}
+
+func TestSubscribeBufferedUnsubscribeDiscards(t *testing.T) {
+	bus := NewBus()
+	h := newBlockingRecorder()
+	unsub := bus.SubscribeBuffered("test", 4, h)
+
+	bus.Publish("test", 1)
+	<-h.entered
+	bus.Publish("test", 2)
+	bus.Publish("test", 3)
+	assert.True(t, unsub())
+	close(h.release)
+	bus.Drain()
+
+	assert.Equal(t, []interface{}{1}, h.values())
+	assert.Equal(t, uint64(2), bus.Stats()["test"].DroppedCount)
+}
+
+func TestSubscribeBufferedRemoved(t *testing.T) {
+	h := HandlerFunc(func(b *Bus, t, v interface{}) {})
+	for _, tc := range []struct {
+		name   string
+		remove func(b *Bus)
+	}{
+		{"UnsubscribeID", func(b *Bus) { b.UnsubscribeID(b.ListSubscriptions()[0].ID) }},
+		{"RemoveTopic", func(b *Bus) { b.RemoveTopic("test") }},
+		{"Reset", func(b *Bus) { b.Reset() }},
+		{"Close", func(b *Bus) { b.Close() }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bus := NewBus()
+			before := runtime.NumGoroutine()
+			bus.SubscribeBuffered("test", 1, h)
+
+			tc.remove(bus)
+			waitGoroutines(before)
+			assert.True(t, runtime.NumGoroutine() <= before, "removing the subscription should stop the worker")
+		})
+	}
+}
+
+func TestSubscribeLatest(t *testing.T) {
+	bus := NewBus()
+	h := newBlockingRecorder()
+	unsub := bus.SubscribeLatest("test", h)
+
+	// The handler is busy with the first value, so only the last of the
+	// others is kept for it
+	bus.Publish("test", 1)
+	<-h.entered
+	for i := 2; i <= 4; i++ {
+		n, _ := bus.Publish("test", i)
+		assert.Equal(t, 1, n, "publish should not wait for the handler")
+	}
+	close(h.release)
+	bus.Drain()
+
+	assert.Equal(t, []interface{}{1, 4}, h.values())
+	assert.Equal(t, uint64(2), bus.Stats()["test"].DroppedCount, "superseded values should be counted")
+
+	assert.True(t, unsub())
+	bus.Publish("test", 5)
+	bus.Drain()
+	assert.Len(t, h.values(), 2)
+}
+
+func TestSubscribeBufferedRejected(t *testing.T) {
+	bus := NewBus(WithDeclaredTopics("test"))
+	before := runtime.NumGoroutine()
+
+	assert.PanicsWithValue(t, "bus: invalid topic", func() {
+		bus.SubscribeBuffered([]int{}, 1, &mockHandler{})
+	})
+	assert.Panics(t, func() {
+		bus.SubscribeBuffered("undeclared", 1, &mockHandler{})
+	})
+	waitGoroutines(before)
+	assert.True(t, runtime.NumGoroutine() <= before, "rejected subscriptions should not start a goroutine")
+	assert.NoError(t, bus.Close(), "the lock should not be held after the panics")
+}
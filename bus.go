@@ -1,7 +1,13 @@
 package bus
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type PublishFlag int
@@ -9,6 +15,11 @@ type PublishFlag int
 const (
 	// Async causes each handler to be triggered in a separate Goroutine
 	Async PublishFlag = 1 << 0
+
+	// OrderedAsync causes the value to be queued for delivery by a single
+	// goroutine per topic, which calls the handlers synchronously in the
+	// order values were published. It takes precedence over Async.
+	OrderedAsync PublishFlag = 1 << 1
 )
 
 // Handler is called whenever a value is sent on a particular topic.
@@ -25,56 +36,647 @@ func (h HandlerFunc) On(b *Bus, t, v interface{}) {
 	h(b, t, v)
 }
 
-// UnsubscribeFunc unsubscribes a handler.
+// ErrHandler is a Handler that can fail. When delivering a value
+// synchronously, the Bus calls OnErr in place of On, and Publish returns any
+// error it reports wrapped in a HandlerError.
+type ErrHandler interface {
+	Handler
+
+	// OnErr is called each time a value is received on a particular topic,
+	// returning an error if the value could not be handled.
+	OnErr(b *Bus, t, v interface{}) error
+}
+
+// ErrHandlerFunc is an adaptor that allows a handler function that can fail
+// to act as an ErrHandler.
+type ErrHandlerFunc func(b *Bus, t, v interface{}) error
+
+func (h ErrHandlerFunc) On(b *Bus, t, v interface{}) {
+	h(b, t, v)
+}
+
+func (h ErrHandlerFunc) OnErr(b *Bus, t, v interface{}) error {
+	return h(b, t, v)
+}
+
+// call delivers the value to the handler, returning the error reported by
+// the handler if it is an ErrHandler. MetaHandlers are passed the publish
+// metadata carried by b, if any, and TryHandlers that cannot accept a value
+// passed by TryPublish return errBackPressure.
+func call(b *Bus, h Handler, t, v interface{}) error {
+	hb := b
+	if !forwards(h) {
+		hb = b.handlerView()
+	}
+	if th, ok := h.(TryHandler); ok && b.try {
+		if !th.TryOn(hb, t, v) {
+			return errBackPressure
+		}
+		return nil
+	}
+	if ch, ok := h.(ContextHandler); ok && b.ctx != nil {
+		ch.OnContext(b.ctx, hb, t, v)
+		return nil
+	}
+	if mh, ok := h.(MetaHandler); ok && b.meta != nil {
+		mh.OnMeta(hb, *b.meta, t, v)
+		return nil
+	}
+	if eh, ok := h.(ErrHandler); ok {
+		return eh.OnErr(hb, t, v)
+	}
+	h.On(hb, t, v)
+	return nil
+}
+
+// forwards reports whether h is one of the handlers that pass values on to
+// other handlers through call, and so must be told how the value is being
+// delivered.
+func forwards(h Handler) bool {
+	switch h.(type) {
+	case wrapper, chainHandler, *teeHandler, *transformHandler, *pipeHandler:
+		return true
+	}
+	return false
+}
+
+// handlerView returns the view of the Bus to pass to a handler itself,
+// without the state that only applies to the publish delivering the value,
+// so that the handler's own publishes are made as any other.
+func (b *Bus) handlerView() *Bus {
+	if !b.try && b.ctx == nil && b.origin == nil {
+		return b
+	}
+	return &Bus{core: b.core, prefix: b.prefix, teed: b.teed, meta: b.meta, sync: b.sync, topicSem: b.topicSem}
+}
+
+// acceptor is implemented by handlers that may decline a value before it is
+// delivered to them. Declined values are not counted as deliveries.
+type acceptor interface {
+	accept(b *Bus, t, v interface{}) bool
+}
+
+// filterHandler passes on only those values that satisfy its filter.
+type filterHandler struct {
+	filter func(v interface{}) bool
+	h      Handler
+}
+
+func (h *filterHandler) accept(b *Bus, t, v interface{}) bool {
+	return h.filter(v)
+}
+
+func (h *filterHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *filterHandler) OnErr(b *Bus, t, v interface{}) error {
+	return call(b, h.h, t, v)
+}
+
+func (h *filterHandler) unwrap() Handler {
+	return h.h
+}
+
+// UnsubscribeFunc unsubscribes a handler. It removes only the subscription
+// it was returned for, even if the same handler has been subscribed more than
+// once, and returns false if that subscription has already been removed.
 type UnsubscribeFunc func() bool
 
+// SubscriptionID is an opaque token identifying a single subscription.
+type SubscriptionID uint64
+
+// subscription records a handler's registration on a Bus.
+type subscription struct {
+	id      SubscriptionID
+	topic   interface{}
+	handler Handler
+
+	// list, if set, is the list of handlers not subscribed to a specific
+	// topic that this subscription belongs to, such as b.globals.
+	list *[]*subscription
+
+	// meta is set if the handler is a MetaHandler.
+	meta bool
+
+	// tag, if set, is the tag the handler was subscribed with by
+	// SubscribeTagged.
+	tag string
+
+	// created is the time the subscription was made.
+	created time.Time
+
+	// stop, if set, stops the goroutine or pending values kept for the
+	// handler. It is called once the subscription is removed, however that
+	// happens, and when the Bus is closed.
+	stop func()
+}
+
+// is reports whether the subscription is of the given handler.
+func (s *subscription) is(h Handler) bool {
+	return isHandler(s.handler, h)
+}
+
+// subscribed returns the handler as it was passed to Subscribe, without the
+// wrappers the Bus adds internally.
+func (s *subscription) subscribed() Handler {
+	return subscribed(s.handler)
+}
+
+// handlerFor returns the handler to deliver a value published to the given
+// topic to. MetaHandlers subscribed to a pattern, parent topic or prefix
+// matching the topic are wrapped to tell them which of them it matched.
+func (s *subscription) handlerFor(topic interface{}) Handler {
+	if !s.meta || s.topic == topic || (s.list != nil && s.topic == nil) {
+		return s.handler
+	}
+	return &matchedHandler{topic: s.topic, h: s.handler}
+}
+
+// isHandler reports whether the subscribed handler sh is the handler h,
+// looking through the wrappers added to handlers subscribed via a namespace
+// and to those delivered a value matching their topic.
+func isHandler(sh, h Handler) bool {
+	if mh, ok := sh.(*matchedHandler); ok {
+		sh = mh.h
+	}
+	if nh, ok := sh.(*nsHandler); ok {
+		return sameHandler(nh.h, h)
+	}
+	return sameHandler(sh, h)
+}
+
+// sameHandler reports whether a and b are the same handler, without
+// panicking if they are of an uncomparable type.
+func sameHandler(a, b Handler) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+	return a == b
+}
+
 var defaultBus *Bus
-var once sync.Once
+var defaultLock sync.RWMutex
 
 // getDefaultBus returns the default bus, creating it if necessary.
 func getDefaultBus() *Bus {
-	once.Do(func() {
+	defaultLock.RLock()
+	b := defaultBus
+	defaultLock.RUnlock()
+	if b != nil {
+		return b
+	}
+
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+	if defaultBus == nil {
 		defaultBus = NewBus()
-	})
+	}
 	return defaultBus
 }
 
+// SetDefaultBus replaces the Bus used by the package-level functions, such as
+// Subscribe and Publish, with b. It is intended for tests, which can use it
+// to give each test a Bus of its own. The previous default Bus is left as it
+// is. If b is nil, a new Bus is created the next time one is needed.
+func SetDefaultBus(b *Bus) {
+	defaultLock.Lock()
+	defer defaultLock.Unlock()
+
+	defaultBus = b
+}
+
+// ResetDefaultBus replaces the Bus used by the package-level functions with
+// a new Bus, without any of the subscriptions of the previous one.
+func ResetDefaultBus() {
+	SetDefaultBus(NewBus())
+}
+
 // Bus is an in-memory event bus that simplifies communication between
 // otherwise distinct components. A bus contains a number of topics, each
 // of which has a number of handlers. When a value is published onto a topic,
 // each of that topic's handlers are called with that value.
+//
+// Topics may be any comparable value, and are compared as map keys are, so
+// topics of different types are always distinct even if their underlying
+// values are equal. Packages can therefore avoid colliding with each other's
+// topics by defining their own topic type, such as type OrderTopic string,
+// and RegisterTopicType can be used to check the values published to them.
+//
+// If the OnNoSubscribers field is set, it is called by Publish whenever a
+// value is published to a topic without any handlers, allowing orphaned
+// values to be logged or rerouted.
 type Bus struct {
-	lock   sync.RWMutex
-	topics map[interface{}][]Handler
+	*core
+
+	// prefix is prepended to string topics by buses returned by Namespace.
+	prefix string
+
+	// teed lists the topics a value has been forwarded from by Tee handlers
+	// on its way to the handlers this Bus is passed to.
+	teed []interface{}
+
+	// origin, if set, is the topic a value forwarded by Tee,
+	// SubscribeTransform or PipeTo was first published to.
+	origin interface{}
+
+	// meta, if set, describes the publish of the value being delivered to
+	// the handlers this Bus is passed to.
+	meta *PublishMeta
+
+	// try is set if the value is being delivered by TryPublish.
+	try bool
+
+	// ctx, if set, is the context of the publish made by PublishCancelable
+	// that the value is being delivered by.
+	ctx context.Context
+
+	// sync is set if the value is being delivered by PublishSync.
+	sync bool
+
+	// topicSem, if set, limits the concurrency of the topic's
+	// asynchronously called handlers.
+	topicSem chan struct{}
+}
+
+// core holds the state of a Bus, which is shared with its namespaces.
+type core struct {
+	// OnNoSubscribers, if set, is called by Publish whenever a value is
+	// published to a topic without any handlers. It is called synchronously,
+	// even when publishing with the Async flag, and should be set before the
+	// Bus is used.
+	OnNoSubscribers func(topic, value interface{})
+
+	// lock guards the fields below it that are not otherwise synchronized.
+	// Handlers, observers and other callbacks are never called while it is
+	// held, so that they may use the Bus; the only exception is the
+	// TopicMatcher, which must not.
+	lock         sync.RWMutex
+	topics       map[interface{}][]*subscription
+	globals      []*subscription
+	fallbacks    []*subscription
+	prefixed     []*subscription
+	ids          map[SubscriptionID]*subscription
+	lastID       SubscriptionID
+	states       map[interface{}]*topicState
+	aliases      map[interface{}]interface{}
+	closedTopics map[interface{}]struct{}
+	declared     map[interface{}]struct{}
+	strict       bool
+	dedup        bool
+	rejectNil    bool
+	skipNil      bool
+	eventLog     *eventLog
+	seqPolicy    SeqPolicy
+	ttl          time.Duration
+	sweepTimer   Timer
+	clock        Clock
+	scheduled    map[*scheduledPublish]struct{}
+	lastSchedID  ScheduleID
+	closed       bool
+
+	async        tracker
+	inflight     gauge
+	workers      int
+	poolQueue    int
+	pool         *asyncPool
+	sem          chan struct{}
+	serial       bool
+	noSubsErr    bool
+	bubbling     bool
+	order        Order
+	globalsFirst bool
+	clone        func(v interface{}) interface{}
+	timer        func(topic interface{}, h Handler, d time.Duration)
+	slow         time.Duration
+	slowWarn     func(topic interface{}, h Handler, d time.Duration)
+	panics       PanicPolicy
+	panicLog     func(topic interface{}, h Handler, recovered interface{})
+	metrics      MetricsSink
+	matcher      TopicMatcher
+	asyncErr     func(topic, value interface{}, err error)
+	lastErr      atomic.Pointer[HandlerError]
+	observers    []Observer
+	events       []observerEvent
+	stopping     []func()
+	seq          atomic.Uint64
+	maxDepth     int
+	depths       depths
+	dispatcher   Dispatcher
+
+	// opts holds the options the Bus was created with, for Clone.
+	opts []BusOption
+}
+
+// topicState holds per-topic data that lives independently of the topic's
+// handlers, such as delivery statistics.
+type topicState struct {
+	stats topicStats
+
+	// reserve is the capacity to allocate for the topic's handlers when
+	// the first is subscribed, as set by ReserveTopic.
+	reserve int
+
+	// serial is held during synchronous delivery when the Bus is created
+	// with WithSerialTopics, or serialize is set.
+	serial sync.Mutex
+
+	// serialize and sem hold the topic's TopicSettings. They are guarded by
+	// the Bus lock, and copied into each delivery.
+	serialize bool
+	sem       chan struct{}
+
+	// transform, if set, rewrites each value published to the topic. It is
+	// guarded by the Bus lock.
+	transform func(v interface{}) interface{}
+
+	// ordered, if set, queues values published with OrderedAsync. It is
+	// guarded by the Bus lock.
+	ordered *orderedQueue
+
+	// retain is set if the topic retains the last value published to it,
+	// which is held by retained once hasRetained is set. They are guarded by
+	// the Bus lock.
+	retain      bool
+	retained    interface{}
+	hasRetained bool
+
+	// typ, if set, is the type values published to the topic must be
+	// assignable to. It is guarded by the Bus lock.
+	typ reflect.Type
+
+	// history, if set, records the last values published to the topic. It
+	// is guarded by the Bus lock.
+	history *history
+
+	// limiter, if set, limits the rate of publishes to the topic. It is
+	// guarded by the Bus lock.
+	limiter *tokenBucket
+
+	// lastSeq is the greatest sequence number published to the topic with
+	// PublishSeq, if hasSeq is set. They are guarded by the Bus lock.
+	lastSeq uint64
+	hasSeq  bool
+
+	// idle is the time the topic was last found without handlers, or zero
+	// if it has any. It is guarded by the Bus lock.
+	idle time.Time
+}
+
+// NewBus creates and returns a new Bus, configured with the given options.
+func NewBus(opts ...BusOption) *Bus {
+	b := &Bus{core: &core{
+		topics: make(map[interface{}][]*subscription),
+		ids:    make(map[SubscriptionID]*subscription),
+		states: make(map[interface{}]*topicState),
+
+		dispatcher: DefaultDispatcher,
+		clock:      realClock{},
+		opts:       append([]BusOption(nil), opts...),
+	}}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.ttl > 0 {
+		b.startSweeper()
+	}
+	if b.workers > 0 {
+		b.pool = newAsyncPool(b.workers, b.poolQueue)
+	}
+	return b
+}
+
+// Clone returns a new Bus configured with the same options as this one, and
+// the same OnNoSubscribers function, but without any of its subscriptions,
+// retained values or statistics. The options are applied afresh, so the new
+// Bus shares no state with this one. Cloning a namespace returns a clone of
+// the underlying Bus.
+func (b *Bus) Clone() *Bus {
+	c := NewBus(b.opts...)
+	c.OnNoSubscribers = b.OnNoSubscribers
+	return c
+}
+
+// state returns the state of the given topic, creating it if necessary. It
+// panics if the topic is invalid, without holding the lock.
+func (b *Bus) state(topic interface{}) *topicState {
+	if !validTopic(topic) {
+		panic(ErrInvalidTopic.Error())
+	}
+	if st := b.loadState(topic); st != nil {
+		return st
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.stateLocked(topic)
+}
+
+// loadState returns the state of the given topic, or nil if it has none.
+func (b *Bus) loadState(topic interface{}) *topicState {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.states[topic]
 }
 
-// NewBus creates and returns a new Bus.
-func NewBus() *Bus {
-	return &Bus{
-		topics: make(map[interface{}][]Handler),
+// stateLocked is like state, but must be called with the write lock held.
+func (b *Bus) stateLocked(topic interface{}) *topicState {
+	st := b.states[topic]
+	if st == nil {
+		st = &topicState{}
+		if b.metrics != nil {
+			st.stats.bind(b.metrics, topic)
+		}
+		b.states[topic] = st
 	}
+	return st
 }
 
 // Subscribe causes the passed Handler to be called when data is published
 // to the named topic on this Bus. It returns a function that can be called to
-// unsubscribe the handler.
+// unsubscribe the handler. It panics if h is nil, the topic cannot be used
+// as a map key or, with WithDeclaredTopics, the topic has not been declared;
+// use SubscribeSafe to get an error instead.
 func (b *Bus) Subscribe(topic interface{}, h Handler) UnsubscribeFunc {
 	b.lock.Lock()
-	defer b.lock.Unlock()
+	defer b.unlock()
 
-	// Add handler to topic, creating topic if not there already
-	if hs, ok := b.topics[topic]; !ok {
-		b.topics[topic] = []Handler{h}
-	} else {
-		b.topics[topic] = append(hs, h)
+	return b.unsubscribeFunc(b.subscribeLocked(topic, h))
+}
+
+// SubscribeID causes the passed Handler to be called when data is published
+// to the named topic on this Bus, returning an identifier that can be passed
+// to UnsubscribeID to unsubscribe the handler. It fails with ErrBusClosed if
+// the Bus has been closed, and with ErrInvalidTopic if the topic cannot be
+// used as a map key.
+func (b *Bus) SubscribeID(topic interface{}, h Handler) (SubscriptionID, error) {
+	if !b.validTopic(topic) {
+		return 0, ErrInvalidTopic
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	if b.closed {
+		return 0, ErrBusClosed
+	}
+	if err := b.checkDeclaredLocked(b.qualify(topic)); err != nil {
+		return 0, err
+	}
+	return b.subscribeLocked(topic, h).id, nil
+}
+
+// SubscribeSafe is like Subscribe, but fails with ErrNilHandler,
+// ErrInvalidTopic or ErrUnknownTopic instead of panicking if h is nil or the
+// topic cannot be used, and with ErrBusClosed if the Bus has been closed.
+func (b *Bus) SubscribeSafe(topic interface{}, h Handler) (UnsubscribeFunc, error) {
+	if isNilHandler(h) {
+		return nil, ErrNilHandler
+	}
+	if !b.validTopic(topic) {
+		return nil, ErrInvalidTopic
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+	if err := b.checkDeclaredLocked(b.qualify(topic)); err != nil {
+		return nil, err
+	}
+	return b.unsubscribeFunc(b.subscribeLocked(topic, h)), nil
+}
+
+// isNilHandler reports whether h is nil, a nil pointer, or a pointer to a nil
+// HandlerFunc, none of which can be called.
+func isNilHandler(h Handler) bool {
+	if h == nil {
+		return true
+	}
+	v := reflect.ValueOf(h)
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return true
+		}
+		e := v.Elem()
+		return e.Kind() == reflect.Func && e.IsNil()
+	case reflect.Func, reflect.Map, reflect.Chan, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+// mustHandler panics if h is nil, so that the mistake is reported where the
+// handler is subscribed rather than when a value is later published.
+func mustHandler(h Handler) {
+	if isNilHandler(h) {
+		panic(ErrNilHandler.Error())
+	}
+}
+
+// subscribeLocked adds a handler to a topic, creating the topic if not there
+// already. It must be called with the write lock held, and panics if h is
+// nil or the topic is invalid or undeclared.
+func (b *Bus) subscribeLocked(topic interface{}, h Handler) *subscription {
+	mustHandler(h)
+	if !b.validTopic(topic) {
+		panic(ErrInvalidTopic.Error())
+	}
+	topic = b.qualify(topic)
+	if err := b.checkDeclaredLocked(topic); err != nil {
+		panic(err.Error())
+	}
+	if b.dedup {
+		for _, s := range b.topics[topic] {
+			if s.is(h) {
+				return s
+			}
+		}
 	}
+	if b.prefix != "" {
+		h = &nsHandler{b: b, h: h}
+	}
+	st := b.stateLocked(topic)
+	st.idle = time.Time{}
+
+	b.lastID++
+	s := &subscription{id: b.lastID, topic: topic, handler: h, meta: wantsMeta(h), created: b.clock.Now()}
+	ss := b.topics[topic]
+	if ss == nil && st.reserve > 0 {
+		ss = make([]*subscription, 0, st.reserve)
+	}
+	b.topics[topic] = append(ss, s)
+	b.ids[s.id] = s
+	b.observeLocked(true, s)
+	return s
+}
+
+// subscribeStopping subscribes h to the topic as Subscribe does, calling stop
+// once the subscription is removed or the Bus is closed, so that a handler
+// with a goroutine of its own never outlives its subscription.
+func (b *Bus) subscribeStopping(topic interface{}, h Handler, stop func()) UnsubscribeFunc {
+	b.lock.Lock()
+	defer b.unlock()
+
+	s := b.subscribeLocked(topic, h)
+	s.stop = stop
+	return b.unsubscribeFunc(s)
+}
 
-	// Unsubscribe function
+// unsubscribeFunc returns a function that removes exactly the given
+// subscription.
+func (b *Bus) unsubscribeFunc(s *subscription) UnsubscribeFunc {
 	return func() bool {
-		return b.Unsubscribe(topic, h)
+		return b.UnsubscribeID(s.id)
 	}
 }
 
+// SubscribeAll causes the passed Handler to be called whenever data is
+// published to any topic on this Bus. Such handlers are called after the
+// handlers subscribed to the specific topic, unless the Bus was created with
+// WithGlobalOrder(true), and are passed the topic the
+// data was published to. It returns a function that can be called to
+// unsubscribe the handler.
+func (b *Bus) SubscribeAll(h Handler) UnsubscribeFunc {
+	b.lock.Lock()
+	defer b.unlock()
+
+	return b.unsubscribeFunc(b.subscribeListLocked(&b.globals, h))
+}
+
+// SubscribeFallback causes the passed Handler to be called whenever data is
+// published to a topic on this Bus that no handler subscribed to that
+// specific topic receives, such as a topic with no subscribers. Fallback
+// handlers are called in place of the topic's own handlers, before any
+// handlers subscribed with SubscribeAll, and are counted as deliveries by
+// Publish. It returns a function that can be called to unsubscribe the
+// handler.
+func (b *Bus) SubscribeFallback(h Handler) UnsubscribeFunc {
+	b.lock.Lock()
+	defer b.unlock()
+
+	return b.unsubscribeFunc(b.subscribeListLocked(&b.fallbacks, h))
+}
+
+// subscribeListLocked adds a handler to a list of handlers that are not
+// subscribed to a specific topic. It must be called with the write lock held.
+func (b *Bus) subscribeListLocked(list *[]*subscription, h Handler) *subscription {
+	mustHandler(h)
+	b.lastID++
+	s := &subscription{id: b.lastID, handler: h, list: list, meta: wantsMeta(h), created: b.clock.Now()}
+	*list = append(*list, s)
+	b.ids[s.id] = s
+	b.observeLocked(true, s)
+	return s
+}
+
 // SubscribeFunc registers the handler function on the given topic, returning
 // a function that can be called to deregister itself.
 func (b *Bus) SubscribeFunc(topic interface{}, h func(b *Bus, t, v interface{})) UnsubscribeFunc {
@@ -82,6 +684,45 @@ func (b *Bus) SubscribeFunc(topic interface{}, h func(b *Bus, t, v interface{}))
 	return b.Subscribe(topic, &hf)
 }
 
+// SubscribeFuncErr registers the handler function on the given topic, as
+// SubscribeFunc does, but the function may fail, with the error reported by
+// Publish as for any other ErrHandler. It returns a function that can be
+// called to deregister itself.
+func (b *Bus) SubscribeFuncErr(topic interface{}, h func(b *Bus, t, v interface{}) error) UnsubscribeFunc {
+	hf := ErrHandlerFunc(h)
+	return b.Subscribe(topic, &hf)
+}
+
+// SubscribeFilter causes the passed Handler to be called when data is
+// published to the named topic on this Bus, but only if filter returns true
+// for the published value. Values rejected by the filter are not counted as
+// deliveries by Publish. The filter and handler are subscribed together, so
+// the returned function must be used to unsubscribe them.
+func (b *Bus) SubscribeFilter(topic interface{}, filter func(v interface{}) bool, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	return b.Subscribe(topic, &filterHandler{filter: filter, h: h})
+}
+
+// SubscribeGated causes the passed Handler to be called when data is
+// published to the named topic on this Bus, but only while gate returns true.
+// The gate is called for each delivery, so it may track a condition held
+// elsewhere, such as a feature flag. Values published while the gate is
+// closed are skipped and not counted as deliveries by Publish.
+func (b *Bus) SubscribeGated(topic interface{}, gate func() bool, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	return b.Subscribe(topic, &filterHandler{filter: func(interface{}) bool { return gate() }, h: h})
+}
+
+// Once causes the passed Handler to be called with the next value published
+// to the named topic on this Bus, after which it is unsubscribed. It is
+// called exactly once even when values are published concurrently or with
+// the Async flag. The returned function unsubscribes the handler before a
+// value is published.
+func (b *Bus) Once(topic interface{}, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	return b.SubscribeN(topic, 1, h)
+}
+
 // OnceFunc registers the handler function on the given topic, returning
 // a function that can be called to deregister itself. It will ensure that
 // the passed handler function is called at most exactly once and deregisters
@@ -99,113 +740,1222 @@ func (b *Bus) OnceFunc(topic interface{}, h func(b *Bus, t, v interface{})) Unsu
 }
 
 // Unsubscribe removes the specified handler from the given topic on this Bus,
-// returning true on success (i.e. the handler was found and removed)
+// returning true on success (i.e. the handler was found and removed). If the
+// handler was subscribed more than once, only the earliest subscription is
+// removed. Handlers of uncomparable types, such as bare HandlerFunc values,
+// can never be found this way, and must be removed using the function
+// returned by Subscribe.
 func (b *Bus) Unsubscribe(topic interface{}, h Handler) bool {
-	b.lock.Lock()
-	defer b.lock.Unlock()
-
-	// Find and remove handler from topic
-	a := b.topics[topic]
-	for i, h2 := range a {
-		if h2 == h {
-			b.topics[topic] = append(a[:i], a[i+1:]...)
+	if !b.validTopic(topic) {
+		return false
+	}
 
-			// Remove topic if no handlers are subscribed to it
-			if len(b.topics[topic]) == 0 {
-				delete(b.topics, topic)
-			}
+	b.lock.Lock()
+	defer b.unlock()
 
-			return true
+	for _, s := range b.topics[b.qualify(topic)] {
+		if s.is(h) {
+			return b.removeLocked(s)
 		}
 	}
 
 	return false
 }
 
-func (b *Bus) publish(hs []Handler, t, v interface{}, flags ...PublishFlag) (int, error) {
-	var fs PublishFlag = 0
-	for _, flag := range flags {
-		fs = fs | flag
+// UnsubscribeReturning removes the specified handler from the given topic on
+// this Bus, as Unsubscribe does, returning the handler as it was subscribed
+// and true if it was found and removed.
+func (b *Bus) UnsubscribeReturning(topic interface{}, h Handler) (Handler, bool) {
+	if !b.validTopic(topic) {
+		return nil, false
 	}
 
-	if fs&Async != 0 {
-		// Call each handler in a separate Goroutine
-		for _, h := range hs {
-			go h.On(b, t, v)
-		}
-	} else {
-		for _, h := range hs {
-			h.On(b, t, v)
+	b.lock.Lock()
+	defer b.unlock()
+
+	for _, s := range b.topics[b.qualify(topic)] {
+		if s.is(h) {
+			return s.subscribed(), b.removeLocked(s)
 		}
 	}
-	return len(hs), nil
+	return nil, false
 }
 
-// Publish sends the given value to all handlers subscribed to the named
-// topic on this Bus. If the `Async` flag is passed, this function will call
-// each handler in a separate goroutine and return without blocking.
-func (b *Bus) Publish(topic interface{}, value interface{}, flags ...PublishFlag) (int, error) {
-	b.lock.RLock()
-	hs := b.topics[topic]
-	b.lock.RUnlock()
+// MoveToFront moves the specified handler to the front of the handlers of the
+// given topic on this Bus, so that it is called before the others, returning
+// true if the handler was found. If the handler was subscribed more than
+// once, only the earliest subscription is moved. Publishes already in
+// progress are unaffected, as they call a copy of the topic's handlers.
+func (b *Bus) MoveToFront(topic interface{}, h Handler) bool {
+	return b.move(topic, h, true)
+}
 
-	return b.publish(hs, topic, value, flags...)
+// MoveToBack moves the specified handler to the back of the handlers of the
+// given topic on this Bus, as MoveToFront does, so that it is called after
+// the others.
+func (b *Bus) MoveToBack(topic interface{}, h Handler) bool {
+	return b.move(topic, h, false)
 }
 
-// PublishAll sends the given value to all handlers registered on all topics
-// on this Bus. If the same Handler is registered on multiple topics or buses,
-// the handler will be called multiple times. Returns the number of handlers
-// fired.
-func (b *Bus) PublishAll(value interface{}, flags ...PublishFlag) (int, error) {
-	b.lock.RLock()
-	defer b.lock.RUnlock()
+// move moves the earliest subscription of the handler to the front or back of
+// the topic's handlers.
+func (b *Bus) move(topic interface{}, h Handler, front bool) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
 
-	c := 0
-	for t, hs := range b.topics {
-		if cc, err := b.publish(hs, t, value, flags...); err != nil {
-			return c, err
+	ss := b.topics[b.qualify(topic)]
+	for i, s := range ss {
+		if !s.is(h) {
+			continue
+		}
+		if front {
+			copy(ss[1:i+1], ss[:i])
+			ss[0] = s
 		} else {
-			c += cc
+			copy(ss[i:], ss[i+1:])
+			ss[len(ss)-1] = s
 		}
+		return true
 	}
-
-	return c, nil
+	return false
 }
 
-// Subscribe causes the passed Handler to be called when data is published
-// to the named topic on the default Bus. It returns a function that can be
-// called to unsubscribe the handler.
-func Subscribe(topic interface{}, h Handler) UnsubscribeFunc {
-	return getDefaultBus().Subscribe(topic, h)
-}
+// UnsubscribeID removes the subscription with the given identifier from this
+// Bus, returning true on success (i.e. the subscription was found and
+// removed).
+func (b *Bus) UnsubscribeID(id SubscriptionID) bool {
+	b.lock.Lock()
+	defer b.unlock()
 
-// SubscribeFunc registers the handler function on the given topic of the
-// default Bus, returning a function that can be called to deregister itself.
-func SubscribeFunc(topic interface{}, fn func(b *Bus, t, v interface{})) UnsubscribeFunc {
-	return getDefaultBus().SubscribeFunc(topic, fn)
+	s, ok := b.ids[id]
+	if !ok {
+		return false
+	}
+	return b.removeLocked(s)
 }
 
-// OnceFunc registers the handler function on the given topic of the default
-// Bus, returning a function that can be called to deregister itself. It will
-// ensure that the passed handler function is called exactly once.
-func OnceFunc(topic interface{}, h func(b *Bus, t, v interface{})) UnsubscribeFunc {
-	return getDefaultBus().OnceFunc(topic, h)
-}
+// removeLocked removes the given subscription from this Bus, returning true
+// if it was found. It must be called with the write lock held.
+func (b *Bus) removeLocked(s *subscription) bool {
+	delete(b.ids, s.id)
 
-// Publish sends the given value to all handlers subscribed to the named
-// topic on the default Bus.
-func Publish(topic interface{}, value interface{}, flags ...PublishFlag) (int, error) {
-	return getDefaultBus().Publish(topic, value, flags...)
+	if s.list != nil {
+		a := *s.list
+		for i, s2 := range a {
+			if s2 == s {
+				*s.list = append(a[:i:i], a[i+1:]...)
+				b.observeLocked(false, s)
+				b.stopLocked(s)
+				return true
+			}
+		}
+		return false
+	}
+
+	// Find and remove subscription from topic
+	a := b.topics[s.topic]
+	for i, s2 := range a {
+		if s2 == s {
+			b.topics[s.topic] = append(a[:i], a[i+1:]...)
+
+			// Remove topic if no handlers are subscribed to it
+			if len(b.topics[s.topic]) == 0 {
+				delete(b.topics, s.topic)
+				b.idleLocked(s.topic)
+			}
+
+			b.observeLocked(false, s)
+			b.stopLocked(s)
+			return true
+		}
+	}
+
+	return false
 }
 
-// PublishAll sends the given value to all handlers on the default Bus.
-func PublishAll(value interface{}, flags ...PublishFlag) (int, error) {
-	return getDefaultBus().PublishAll(value, flags...)
+// stopLocked arranges for the stop function of a removed subscription, if it
+// has one, to be called once the write lock is released by unlock. It must be
+// called with the write lock held.
+func (b *Bus) stopLocked(s *subscription) {
+	if s.stop != nil {
+		b.stopping = append(b.stopping, s.stop)
+	}
 }
 
-// Unsubscribe removes the specified handler from the given topic on the
-// default Bus, returning true on success (i.e. the handler was found and
+// RemoveTopic unsubscribes all handlers from the given topic on this Bus,
+// returning the number of handlers that were removed.
+func (b *Bus) RemoveTopic(topic interface{}) int {
+	if !b.validTopic(topic) {
+		return 0
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	return len(b.removeTopicLocked(b.qualify(topic)))
+}
+
+// RemoveTopicReturning unsubscribes all handlers from the given topic on this
+// Bus, as RemoveTopic does, returning the handlers that were removed in the
+// order they were called, as they were subscribed.
+func (b *Bus) RemoveTopicReturning(topic interface{}) []Handler {
+	if !b.validTopic(topic) {
+		return nil
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	ss := b.removeTopicLocked(b.qualify(topic))
+	hs := make([]Handler, len(ss))
+	for i, s := range ss {
+		hs[i] = s.subscribed()
+	}
+	return hs
+}
+
+// ReplaceHandlers unsubscribes all handlers from the given topic on this Bus
+// and subscribes the given handlers in their place, in order, returning the
+// handlers that were removed as RemoveTopicReturning does. The swap is made
+// under a single lock, so every publish sees either the old handlers or the
+// new ones, never a mixture. Any UnsubscribeFuncs or SubscriptionIDs of the
+// old handlers have no effect afterwards. Like Subscribe, it panics if any of
+// the handlers is nil or the topic is invalid, in which case no handlers are
+// replaced.
+func (b *Bus) ReplaceHandlers(topic interface{}, handlers []Handler) []Handler {
+	for _, h := range handlers {
+		mustHandler(h)
+	}
+	if !b.validTopic(topic) {
+		panic(ErrInvalidTopic.Error())
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	if err := b.checkDeclaredLocked(b.qualify(topic)); err != nil {
+		panic(err.Error())
+	}
+	ss := b.removeTopicLocked(b.qualify(topic))
+	for _, h := range handlers {
+		b.subscribeLocked(topic, h)
+	}
+
+	hs := make([]Handler, len(ss))
+	for i, s := range ss {
+		hs[i] = s.subscribed()
+	}
+	return hs
+}
+
+// removeTopicLocked removes all subscriptions to the qualified topic,
+// returning them. It must be called with the write lock held.
+func (b *Bus) removeTopicLocked(topic interface{}) []*subscription {
+	ss := b.topics[topic]
+	for _, s := range ss {
+		delete(b.ids, s.id)
+		b.observeLocked(false, s)
+		b.stopLocked(s)
+	}
+	delete(b.topics, topic)
+	b.idleLocked(topic)
+	return ss
+}
+
+// ReserveTopic allocates room for n handlers to be subscribed to the named
+// topic on this Bus, so that subscribing many handlers to one topic does not
+// repeatedly reallocate its list of handlers. The reservation also applies
+// whenever the topic's last handler has been removed and another is
+// subscribed. It only affects allocation, not delivery.
+func (b *Bus) ReserveTopic(topic interface{}, n int) {
+	topic = b.qualify(topic)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.stateLocked(topic).reserve = n
+	if ss := b.topics[topic]; ss != nil && cap(ss) < n {
+		b.topics[topic] = append(make([]*subscription, 0, n), ss...)
+	}
+}
+
+// Has reports whether at least one handler is subscribed to the given topic
+// on this Bus, excluding those subscribed with SubscribeAll. It can be used
+// to avoid building a value that nobody will receive.
+func (b *Bus) Has(topic interface{}) bool {
+	if !b.validTopic(topic) {
+		return false
+	}
+	topic = b.qualify(topic)
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return len(b.topics[topic]) > 0
+}
+
+// SubscriberCount returns the number of handlers subscribed to the given
+// topic on this Bus, excluding those subscribed with SubscribeAll.
+func (b *Bus) SubscriberCount(topic interface{}) int {
+	if !b.validTopic(topic) {
+		return 0
+	}
+	topic = b.qualify(topic)
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return len(b.topics[topic])
+}
+
+// TotalSubscribers returns the number of handlers subscribed to all topics on
+// this Bus, excluding those subscribed with SubscribeAll.
+func (b *Bus) TotalSubscribers() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	n := 0
+	for _, ss := range b.topics {
+		n += len(ss)
+	}
+	return n
+}
+
+// Reset unsubscribes all handlers from all topics on this Bus, including
+// those subscribed with SubscribeAll and SubscribeFallback.
+func (b *Bus) Reset() {
+	b.lock.Lock()
+	defer b.unlock()
+
+	for _, s := range b.ids {
+		b.observeLocked(false, s)
+		b.stopLocked(s)
+	}
+	b.topics = make(map[interface{}][]*subscription)
+	b.globals = nil
+	b.prefixed = nil
+	b.fallbacks = nil
+	b.ids = make(map[SubscriptionID]*subscription)
+}
+
+// appendHandlers appends the handler of each subscription to hs for delivery
+// of a value published to the given topic, also reporting whether any of the
+// handlers is a MetaHandler. Subscriptions without a handler are skipped.
+func appendHandlers(hs []Handler, ss []*subscription, topic interface{}) ([]Handler, bool) {
+	meta := false
+	for _, s := range ss {
+		if s == nil || s.handler == nil {
+			continue
+		}
+		hs = append(hs, s.handlerFor(topic))
+		meta = meta || s.meta
+	}
+	return hs, meta
+}
+
+// SetTransform causes each value published to the named topic on this Bus to
+// be passed through fn before delivery, with the value it returns delivered
+// to handlers in its place. If fn returns nil, the publish is suppressed and
+// no handlers are called. Passing a nil fn removes the topic's transform.
+func (b *Bus) SetTransform(topic interface{}, fn func(v interface{}) interface{}) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.stateLocked(b.qualify(topic)).transform = fn
+}
+
+// delivery holds everything needed to deliver a single published value. The
+// handlers are resolved once, when the value is published, and the same set
+// is then used for dispatch, error aggregation, statistics and observer
+// notification, so none of them need to look the topic up again.
+type delivery struct {
+	topic interface{}
+	value interface{}
+	state *topicState
+
+	// handlers holds the handlers subscribed to the topic followed by those
+	// subscribed to all topics, with the first specific of them belonging
+	// to the topic itself.
+	handlers []Handler
+	specific int
+
+	// single, if set, is the only handler to call, in which case handlers
+	// and fallbacks are empty.
+	single Handler
+
+	// fallbacks holds the handlers to call if none of the topic's own
+	// handlers accept the value.
+	fallbacks []Handler
+
+	// skip, if set, is a handler not to deliver to.
+	skip Handler
+
+	// accepted, if set, is called for each handler that accepts the value,
+	// before the handler is called.
+	accepted func()
+
+	// delivered, if set, is called after each handler has returned.
+	delivered func()
+
+	// meta, if set, describes the publish to MetaHandlers.
+	meta *PublishMeta
+
+	// result, if set, records the outcome for each handler, including
+	// whether it failed if resultErrs is set.
+	result     *resultRecorder
+	resultErrs bool
+
+	// serial and sem hold the topic's settings at the time of the publish.
+	serial bool
+	sem    chan struct{}
+
+	// seq is the number of the publish.
+	seq uint64
+
+	// cancel, if set, is the cancelable publish the delivery is made by.
+	cancel *CancelablePublish
+
+	// refused, if set, counts the handlers that refuse a value published by
+	// TryPublish.
+	refused *atomic.Int64
+
+	// reduction, if set, holds the value passed from handler to handler by
+	// PublishReduce.
+	reduction *reduction
+
+	// nils is the number of subscriptions without a handler that were
+	// skipped when resolving the handlers.
+	nils int
+}
+
+// flagsOf combines the given flags into one.
+func flagsOf(flags []PublishFlag) PublishFlag {
+	var fs PublishFlag = 0
+	for _, flag := range flags {
+		fs = fs | flag
+	}
+	return fs
+}
+
+// prepare resolves the handlers and state needed to publish a value to the
+// given topic. If produce is not nil, it is called to build the value only
+// if there is something to receive it. It returns false if the publish
+// should not proceed.
+func (b *Bus) prepare(topic, value interface{}, produce func() interface{}) (delivery, bool, error) {
+	if !b.validTopic(topic) {
+		return delivery{}, false, ErrInvalidTopic
+	}
+	topic = b.qualify(topic)
+
+	b.lock.RLock()
+	if b.closed {
+		b.lock.RUnlock()
+		return delivery{}, false, ErrBusClosed
+	}
+	if err := b.checkDeclaredLocked(topic); err != nil {
+		b.lock.RUnlock()
+		return delivery{}, false, err
+	}
+	if _, ok := b.closedTopics[topic]; ok {
+		b.lock.RUnlock()
+		return delivery{}, false, ErrTopicClosed
+	}
+	published := topic
+	if b.origin != nil {
+		published = b.origin
+	}
+	topic = b.resolveLocked(topic)
+	if _, ok := b.closedTopics[topic]; ok {
+		// The topic is an alias of one that has been closed
+		b.lock.RUnlock()
+		return delivery{}, false, ErrTopicClosed
+	}
+	d := b.deliveryLocked(topic, value)
+	var transform func(v interface{}) interface{}
+	var typ reflect.Type
+	var limiter *tokenBucket
+	record := false
+	if d.state != nil {
+		transform = d.state.transform
+		typ = d.state.typ
+		limiter = d.state.limiter
+		record = d.state.retain || d.state.history != nil
+	}
+	b.lock.RUnlock()
+
+	if d.state == nil {
+		d.state = b.state(topic)
+	}
+
+	orphan := d.resolved() == 0
+	if produce != nil && (!orphan || record || b.OnNoSubscribers != nil) {
+		d.value = produce()
+	}
+
+	if typ != nil && !assignable(d.value, typ) {
+		return delivery{}, false, fmt.Errorf("%w: topic %v requires %v, not %T", ErrPayloadType, topic, typ, d.value)
+	}
+
+	if transform != nil {
+		if d.value = transform(d.value); d.value == nil {
+			return delivery{}, false, nil
+		}
+	}
+
+	// Only values that will be published take a token
+	if limiter != nil {
+		if err := b.limit(limiter); err != nil {
+			return delivery{}, false, err
+		}
+	}
+
+	if record {
+		// Record the value and snapshot the handlers again in one go, so
+		// that handlers subscribing with SubscribeSticky or SubscribeReplay
+		// meanwhile are passed either the recorded value or the published
+		// one
+		b.lock.Lock()
+		st, v := d.state, d.value
+		if st.retain {
+			st.retained, st.hasRetained = v, true
+		}
+		if st.history != nil {
+			st.history.add(v)
+		}
+		d = b.deliveryLocked(topic, v)
+		d.state = st
+		b.lock.Unlock()
+	}
+
+	if d.meta != nil {
+		d.meta.OriginalTopic = published
+	}
+
+	if orphan {
+		if b.OnNoSubscribers != nil {
+			b.OnNoSubscribers(topic, d.value)
+		}
+		if b.noSubsErr {
+			return delivery{}, false, ErrNoSubscribers
+		}
+	}
+
+	return d, true, nil
+}
+
+// deliveryLocked returns a delivery of the value to the handlers of the given
+// topic. It must be called with the lock held.
+func (b *Bus) deliveryLocked(topic, value interface{}) delivery {
+	d, meta := b.resolveHandlersLocked(topic)
+	d.value = value
+	if d.state != nil {
+		d.serial, d.sem = d.state.serialize, d.state.sem
+	}
+
+	// Every publish is numbered, but the time is only needed by MetaHandlers
+	d.seq = b.seq.Add(1)
+	if meta {
+		d.meta = &PublishMeta{
+			Seq:           d.seq,
+			Time:          b.clock.Now(),
+			Values:        make(map[interface{}]interface{}),
+			OriginalTopic: topic,
+			MatchedTopic:  topic,
+		}
+	}
+	return d
+}
+
+// resolveHandlersLocked returns a delivery to the handlers of the given topic
+// in the order they are called: the topic's own handlers (including those
+// of its parents when bubbling), then those subscribed with SubscribeAll,
+// then the fallback handlers. It also reports whether any of the handlers is
+// a MetaHandler. It must be called with the lock held.
+func (b *Bus) resolveHandlersLocked(topic interface{}) (delivery, bool) {
+	// Copy the handlers so that they can be called without holding the lock,
+	// leaving other goroutines free to (un)subscribe during delivery.
+	ss := b.topics[topic]
+	if b.matcher != nil {
+		ss = b.matchLocked(topic)
+	}
+	if b.bubbling {
+		ss = b.bubbleLocked(topic, ss)
+	}
+	if len(b.prefixed) > 0 {
+		ss = b.prefixLocked(topic, ss)
+	}
+	d := delivery{
+		topic:    topic,
+		state:    b.states[topic],
+		specific: len(ss),
+	}
+
+	var meta, gmeta, fmeta bool
+	lone := b.globals
+	if len(ss) == 1 {
+		lone = ss
+	}
+	if len(ss)+len(b.globals) == 1 && len(b.fallbacks) == 0 && lone[0] != nil && lone[0].handler != nil {
+		// A lone handler can be called without copying it into a slice
+		d.single, meta = lone[0].handlerFor(topic), lone[0].meta
+	} else {
+		d.handlers = make([]Handler, 0, len(ss)+len(b.globals))
+		d.handlers, meta = appendHandlers(d.handlers, ss, topic)
+		d.specific = len(d.handlers)
+		d.handlers, gmeta = appendHandlers(d.handlers, b.globals, topic)
+		if len(b.fallbacks) > 0 {
+			d.fallbacks, fmeta = appendHandlers(make([]Handler, 0, len(b.fallbacks)), b.fallbacks, topic)
+		}
+		d.nils = len(ss) + len(b.globals) + len(b.fallbacks) - d.resolved()
+		if b.order == LIFO {
+			reverseHandlers(d.handlers[:d.specific])
+			reverseHandlers(d.handlers[d.specific:])
+			reverseHandlers(d.fallbacks)
+		}
+	}
+	return d, meta || gmeta || fmeta
+}
+
+// resolveHandlers returns the handlers a value published to the given topic
+// would be delivered to, following aliases.
+func (b *Bus) resolveHandlers(topic interface{}) delivery {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	d, _ := b.resolveHandlersLocked(b.resolveLocked(topic))
+	return d
+}
+
+// Resolve returns the handlers that a value published to the given topic on
+// this Bus would be delivered to, in the order they would be called.
+// Handlers subscribed to the topic come first, followed by those subscribed
+// with SubscribeAll (or the other way around with WithGlobalOrder) and
+// finally the fallback handlers, which are only called if none of the others
+// accept the value. Aliases are followed. The returned
+// slice is a copy, so later subscriptions do not affect it. It returns nil
+// for an invalid topic.
+func (b *Bus) Resolve(topic interface{}) []Handler {
+	if !b.validTopic(topic) {
+		return nil
+	}
+	d := b.resolveHandlers(b.qualify(topic))
+
+	hs := make([]Handler, 0, d.resolved())
+	if d.single != nil {
+		d.handlers = []Handler{d.single}
+	}
+	specific, globals := d.handlers[:d.specific], d.handlers[d.specific:]
+	if b.globalsFirst {
+		specific, globals = globals, specific
+	}
+	for _, h := range specific {
+		hs = append(hs, subscribed(h))
+	}
+	for _, h := range globals {
+		hs = append(hs, subscribed(h))
+	}
+	for _, h := range d.fallbacks {
+		hs = append(hs, subscribed(h))
+	}
+	return hs
+}
+
+// resolved returns the number of handlers the delivery was resolved to,
+// including fallbacks, before any of them have declined the value.
+func (d *delivery) resolved() int {
+	if d.single != nil {
+		return 1
+	}
+	return len(d.handlers) + len(d.fallbacks)
+}
+
+// reverseHandlers reverses the order of hs in place.
+func reverseHandlers(hs []Handler) {
+	for i, j := 0, len(hs)-1; i < j; i, j = i+1, j-1 {
+		hs[i], hs[j] = hs[j], hs[i]
+	}
+}
+
+// publish delivers a prepared value to its handlers, returning the number of
+// handlers called, and tells any observers about it.
+func (b *Bus) publish(d delivery, fs PublishFlag) (int, error) {
+	b.logPublish(&d)
+	n, err := b.publishDelivery(d, fs)
+	for _, o := range b.observers {
+		o.OnPublish(d.topic, d.value, n)
+	}
+	return n, err
+}
+
+// publishDelivery delivers a prepared value to its handlers, returning the
+// number of handlers called.
+func (b *Bus) publishDelivery(d delivery, fs PublishFlag) (int, error) {
+	st, t, v := d.state, d.topic, d.value
+
+	if b.maxDepth > 0 && fs&(Async|OrderedAsync) == 0 {
+		g := goroutineID()
+		defer b.depths.leave(g)
+		if b.depths.enter(g) > b.maxDepth {
+			return 0, ErrMaxDepth
+		}
+	}
+
+	if (b.serial || d.serial) && fs&(Async|OrderedAsync) == 0 {
+		st.serial.Lock()
+		defer st.serial.Unlock()
+	}
+
+	// Handlers are passed a view of the Bus carrying the publish metadata
+	// and the topic's concurrency limit, replacing that of any publish this
+	// one is nested in
+	db := b
+	if d.meta != nil || d.sem != nil || b.topicSem != nil {
+		db = &Bus{core: b.core, prefix: b.prefix, teed: b.teed, origin: b.origin, meta: d.meta, try: b.try, sync: b.sync, ctx: b.ctx, topicSem: d.sem}
+	}
+
+	if d.single != nil {
+		if _, ok := b.dispatcher.(defaultDispatcher); ok && d.delivered == nil && d.skip == nil && d.result == nil && d.cancel == nil && d.refused == nil && d.reduction == nil && fs&OrderedAsync == 0 {
+			return b.publishSingle(d, db, fs)
+		}
+	}
+	hs := b.acceptAll(d)
+
+	if fs&OrderedAsync != 0 {
+		if err := b.enqueue(st, db, hs, t, v); err != nil {
+			return 0, err
+		}
+		st.stats.publish()
+		st.stats.delivered.Add(uint64(len(hs)))
+		return len(hs), b.nilHandlerErr(d)
+	}
+
+	n, err := b.dispatcher.Dispatch(db, hs, t, v, fs&Async != 0)
+
+	st.stats.publish()
+	st.stats.delivered.Add(uint64(n))
+	if nerr := b.nilHandlerErr(d); nerr != nil {
+		err = errors.Join(err, nerr)
+	}
+	return n, err
+}
+
+// nilHandlerErr returns ErrNilHandler naming the topic if nil handlers were
+// skipped when resolving the delivery, unless the Bus skips them silently.
+func (b *Bus) nilHandlerErr(d delivery) error {
+	if d.nils == 0 || b.skipNil {
+		return nil
+	}
+	return fmt.Errorf("%w: topic %v", ErrNilHandler, d.topic)
+}
+
+// publishSingle delivers a value to the single handler of its delivery as
+// DefaultDispatcher would, but without needing a slice of handlers.
+func (b *Bus) publishSingle(d delivery, db *Bus, fs PublishFlag) (int, error) {
+	st, t, v := d.state, d.topic, d.value
+	st.stats.publish()
+
+	if a, ok := d.single.(acceptor); ok && !a.accept(b, t, v) {
+		st.stats.drop(1)
+		return 0, nil
+	}
+
+	var err error
+	if fs&Async != 0 {
+		db.goAsync(d.single, t, v)
+	} else if herr := db.deliverSync(d.single, t, v); herr != nil {
+		err = errors.Join(herr)
+	}
+	st.stats.delivered.Add(1)
+	return 1, err
+}
+
+// acceptAll returns the handlers to deliver the value to, skipping handlers
+// that decline it, and calling the fallback handlers if none of the topic's
+// own handlers accept it.
+func (b *Bus) acceptAll(d delivery) []Handler {
+	if d.single != nil {
+		d.handlers = []Handler{d.single}
+	}
+
+	if b.globalsFirst {
+		hs := b.accept(d, d.handlers[d.specific:])
+		return append(hs, b.acceptSpecific(d)...)
+	}
+	hs := b.acceptSpecific(d)
+	return append(hs, b.accept(d, d.handlers[d.specific:])...)
+}
+
+// acceptSpecific returns the topic's own handlers that accept the delivered
+// value, or the fallback handlers that accept it if none of them do.
+func (b *Bus) acceptSpecific(d delivery) []Handler {
+	hs := b.accept(d, d.handlers[:d.specific])
+	if len(hs) == 0 && len(d.fallbacks) > 0 {
+		hs = b.accept(d, d.fallbacks)
+	}
+	return hs
+}
+
+// accept returns those handlers that accept the delivered value. The handlers
+// are filtered in place, so must be owned by the delivery.
+func (b *Bus) accept(d delivery, hs []Handler) []Handler {
+	accepted := hs[:0]
+	for _, h := range hs {
+		if d.skip != nil && isHandler(h, d.skip) {
+			continue
+		}
+		if a, ok := h.(acceptor); ok && !a.accept(b, d.topic, d.value) {
+			d.state.stats.drop(1)
+			if d.result != nil {
+				d.result.record(h, Skipped)
+			}
+			continue
+		}
+		if d.accepted != nil {
+			d.accepted()
+		}
+		if d.refused != nil {
+			h = &tryHandler{h: h, refused: d.refused, stats: &d.state.stats}
+		}
+		if d.reduction != nil {
+			h = &reduceStep{h: h, r: d.reduction}
+		}
+		if d.cancel != nil {
+			h = &cancelHandler{h: h, p: d.cancel}
+		}
+		if d.delivered != nil {
+			h = &notifyHandler{h: h, fn: d.delivered}
+		}
+		if d.result != nil {
+			i := d.result.record(h, Delivered)
+			if d.resultErrs {
+				h = &resultHandler{h: h, result: d.result, i: i}
+			}
+		}
+		accepted = append(accepted, h)
+	}
+	return accepted
+}
+
+// Publish sends the given value to all handlers subscribed to the named
+// topic on this Bus. If the `Async` flag is passed, this function will call
+// each handler in a separate goroutine and return without blocking. If the
+// `OrderedAsync` flag is passed, the value is queued for delivery after any
+// values previously published to the topic with that flag, and the returned
+// count is the number of handlers it was queued for. A handler called from
+// the topic's queue that publishes to its own topic with OrderedAsync while
+// the queue is full fails with ErrOrderedQueueFull rather than waiting for
+// itself.
+//
+// Errors reported by ErrHandlers called synchronously are returned wrapped in
+// HandlerErrors, joined together if more than one handler fails. Publishing
+// to a topic that cannot be used as a map key fails with ErrInvalidTopic,
+// and publishing to an undeclared topic on a Bus created with
+// WithDeclaredTopics fails with ErrUnknownTopic.
+//
+// Handlers are called with a copy of the topic's handlers taken when Publish
+// is called, and no lock is held while they run. Slow handlers therefore
+// never prevent other goroutines from subscribing or unsubscribing, and
+// handlers subscribed or unsubscribed during delivery, including by the
+// handlers themselves, take effect from the next publish.
+func (b *Bus) Publish(topic interface{}, value interface{}, flags ...PublishFlag) (int, error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return 0, err
+	}
+	return b.publish(d, flagsOf(flags))
+}
+
+// PublishSync publishes the value to the named topic on this Bus as Publish
+// does without flags, but is guaranteed never to start a goroutine: every
+// handler, including those subscribed with SubscribeAsync, has been called
+// and has returned in the calling goroutine by the time PublishSync returns.
+// It is intended for tests and other code that needs delivery to be
+// deterministic. Handlers that hand values on to goroutines of their own,
+// such as those subscribed with SubscribeBuffered, still do so.
+func (b *Bus) PublishSync(topic, value interface{}) (int, error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return 0, err
+	}
+	sb := &Bus{core: b.core, prefix: b.prefix, teed: b.teed, sync: true}
+	return sb.publish(d, 0)
+}
+
+// PublishFunc publishes the value returned by produce to the named topic on
+// this Bus, as Publish does, but only calls produce if the topic has handlers
+// to receive the value. This avoids building expensive values that nobody is
+// listening for. If the topic is retained or has a history, or the Bus has an
+// OnNoSubscribers function, produce is always called.
+func (b *Bus) PublishFunc(topic interface{}, produce func() interface{}, flags ...PublishFlag) (int, error) {
+	d, ok, err := b.prepare(topic, nil, produce)
+	if !ok {
+		return 0, err
+	}
+	return b.publish(d, flagsOf(flags))
+}
+
+// PublishExcept sends the given value to all handlers subscribed to the named
+// topic on this Bus, as Publish does, except for the given handler, which is
+// identified as Unsubscribe identifies handlers. It is typically used by a
+// handler to republish a value without receiving it again itself. The
+// returned count excludes the skipped handler.
+func (b *Bus) PublishExcept(topic, value interface{}, skip Handler, flags ...PublishFlag) (int, error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return 0, err
+	}
+	d.skip = skip
+	return b.publish(d, flagsOf(flags))
+}
+
+// PublishRoute publishes the value, as Publish does, to trueTopic if the
+// predicate reports true for it and to falseTopic otherwise. The predicate is
+// called once, before anything is published, and the returned count is that
+// of the topic the value was published to.
+func (b *Bus) PublishRoute(value interface{}, predicate func(v interface{}) bool, trueTopic, falseTopic interface{}, flags ...PublishFlag) (int, error) {
+	topic := falseTopic
+	if predicate(value) {
+		topic = trueTopic
+	}
+	return b.Publish(topic, value, flags...)
+}
+
+// PublishTimeout sends the given value to all handlers subscribed to the
+// named topic on this Bus, calling each in turn as Publish does. If the
+// handlers have not all returned within the given timeout, it returns
+// ErrTimeout along with the number of handlers that did return in time; the
+// remaining handlers continue to be called in the background.
+func (b *Bus) PublishTimeout(topic, value interface{}, timeout time.Duration) (int, error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return 0, err
+	}
+
+	var completed atomic.Int64
+	d.delivered = func() {
+		completed.Add(1)
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := b.publish(d, 0)
+		done <- result{n, err}
+	}()
+
+	expired, stop := b.after(timeout)
+	defer stop()
+
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-expired:
+		return int(completed.Load()), ErrTimeout
+	}
+}
+
+// PublishAll sends the given value to all handlers registered on all topics
+// on this Bus. If the same Handler is registered on multiple topics or buses,
+// the handler will be called multiple times. Returns the number of handlers
+// fired.
+//
+// Each topic is published to as if by Publish with the same flags, subject
+// to its type, transform, rate limit and so on, and recorded by its
+// retained value and history. Handlers are called from a snapshot taken
+// beforehand, without holding any lock, and errors from all topics, such as
+// ErrTopicClosed or ErrRateLimited, are joined together and returned.
+// Topics that are aliases of others are skipped, as Publish never reaches
+// their handlers. Without the Async flag, every handler has returned by the
+// time PublishAll does.
+func (b *Bus) PublishAll(value interface{}, flags ...PublishFlag) (int, error) {
+	b.lock.RLock()
+	if b.closed {
+		b.lock.RUnlock()
+		return 0, ErrBusClosed
+	}
+
+	topics := make([]interface{}, 0, len(b.topics))
+	for t := range b.topics {
+		if b.resolveLocked(t) == t {
+			topics = append(topics, t)
+		}
+	}
+	b.lock.RUnlock()
+
+	// The topics are already qualified, so are prepared from the root of
+	// the Bus
+	rb := *b
+	rb.prefix = ""
+
+	fs := flagsOf(flags)
+	c := 0
+	var errs []error
+	for _, t := range topics {
+		d, ok, err := rb.prepare(t, value, nil)
+		if ok {
+			var n int
+			n, err = b.publish(d, fs)
+			c += n
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return c, errors.Join(errs...)
+}
+
+// Close closes this Bus, causing subsequent publishes to fail with
+// ErrBusClosed and cancelling those scheduled with PublishAfter, then waits
+// for all asynchronously called handlers to return, and stops the goroutines
+// of handlers subscribed with functions such as SubscribeBuffered.
+// It returns ErrBusClosed if the Bus has already been closed.
+func (b *Bus) Close() error {
+	if err := b.markClosed(); err != nil {
+		return err
+	}
+	b.shutdown()
+	return nil
+}
+
+// CloseTimeout is like Close, but waits at most timeout for asynchronously
+// called handlers to return. If they do not return in time, it returns
+// ErrTimeout, leaving the Bus closed and the handlers running.
+func (b *Bus) CloseTimeout(timeout time.Duration) error {
+	if err := b.markClosed(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		b.shutdown()
+		close(done)
+	}()
+
+	expired, stop := b.after(timeout)
+	defer stop()
+
+	select {
+	case <-done:
+		return nil
+	case <-expired:
+		return ErrTimeout
+	}
+}
+
+// markClosed marks the Bus as closed and cancels scheduled publishes,
+// returning ErrBusClosed if it already was.
+func (b *Bus) markClosed() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return ErrBusClosed
+	}
+	b.closed = true
+	b.cancelScheduledLocked()
+	if b.sweepTimer != nil {
+		b.sweepTimer.Stop()
+	}
+	return nil
+}
+
+// shutdown waits for asynchronous handlers to return and for ordered queues
+// to be delivered once the Bus has been closed, then stops the goroutines of
+// its subscriptions and the async pool.
+func (b *Bus) shutdown() {
+	b.async.wait()
+	b.stopAll()
+	b.closeOrdered()
+	if b.pool != nil {
+		b.pool.close()
+	}
+}
+
+// stopAll calls the stop function of every subscription that has one.
+func (b *Bus) stopAll() {
+	b.lock.RLock()
+	var stops []func()
+	for _, s := range b.ids {
+		if s.stop != nil {
+			stops = append(stops, s.stop)
+		}
+	}
+	b.lock.RUnlock()
+
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// Subscribe causes the passed Handler to be called when data is published
+// to the named topic on the default Bus. It returns a function that can be
+// called to unsubscribe the handler.
+func Subscribe(topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().Subscribe(topic, h)
+}
+
+// SubscribeAll causes the passed Handler to be called whenever data is
+// published to any topic on the default Bus. It returns a function that can
+// be called to unsubscribe the handler.
+func SubscribeAll(h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeAll(h)
+}
+
+// SubscribeFallback causes the passed Handler to be called whenever data is
+// published to a topic on the default Bus that no handler subscribed to that
+// specific topic receives.
+func SubscribeFallback(h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeFallback(h)
+}
+
+// SubscribeFunc registers the handler function on the given topic of the
+// default Bus, returning a function that can be called to deregister itself.
+func SubscribeFunc(topic interface{}, fn func(b *Bus, t, v interface{})) UnsubscribeFunc {
+	return getDefaultBus().SubscribeFunc(topic, fn)
+}
+
+// SubscribeFuncErr registers the handler function, which may fail, on the
+// given topic of the default Bus, returning a function that can be called to
+// deregister itself.
+func SubscribeFuncErr(topic interface{}, fn func(b *Bus, t, v interface{}) error) UnsubscribeFunc {
+	return getDefaultBus().SubscribeFuncErr(topic, fn)
+}
+
+// SubscribeFilter causes the passed Handler to be called when data matching
+// filter is published to the named topic on the default Bus. It returns a
+// function that can be called to unsubscribe the handler.
+func SubscribeFilter(topic interface{}, filter func(v interface{}) bool, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeFilter(topic, filter, h)
+}
+
+// SubscribeGated causes the passed Handler to be called when data is
+// published to the named topic on the default Bus while gate returns true.
+func SubscribeGated(topic interface{}, gate func() bool, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeGated(topic, gate, h)
+}
+
+// Once causes the passed Handler to be called with the next value published
+// to the named topic on the default Bus, after which it is unsubscribed.
+func Once(topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().Once(topic, h)
+}
+
+// OnceFunc registers the handler function on the given topic of the default
+// Bus, returning a function that can be called to deregister itself. It will
+// ensure that the passed handler function is called exactly once.
+func OnceFunc(topic interface{}, h func(b *Bus, t, v interface{})) UnsubscribeFunc {
+	return getDefaultBus().OnceFunc(topic, h)
+}
+
+// Publish sends the given value to all handlers subscribed to the named
+// topic on the default Bus.
+func Publish(topic interface{}, value interface{}, flags ...PublishFlag) (int, error) {
+	return getDefaultBus().Publish(topic, value, flags...)
+}
+
+// Has reports whether at least one handler is subscribed to the given topic
+// on the default Bus.
+func Has(topic interface{}) bool {
+	return getDefaultBus().Has(topic)
+}
+
+// PublishFunc publishes the value returned by produce to the named topic on
+// the default Bus, only calling produce if the topic has handlers.
+func PublishFunc(topic interface{}, produce func() interface{}, flags ...PublishFlag) (int, error) {
+	return getDefaultBus().PublishFunc(topic, produce, flags...)
+}
+
+// PublishExcept sends the given value to all handlers subscribed to the named
+// topic on the default Bus, except for the given handler.
+func PublishExcept(topic, value interface{}, skip Handler, flags ...PublishFlag) (int, error) {
+	return getDefaultBus().PublishExcept(topic, value, skip, flags...)
+}
+
+// PublishRoute publishes the value on the default Bus to trueTopic if the
+// predicate reports true for it and to falseTopic otherwise.
+func PublishRoute(value interface{}, predicate func(v interface{}) bool, trueTopic, falseTopic interface{}, flags ...PublishFlag) (int, error) {
+	return getDefaultBus().PublishRoute(value, predicate, trueTopic, falseTopic, flags...)
+}
+
+// PublishSync publishes the value to the named topic on the default Bus,
+// calling every handler in the calling goroutine.
+func PublishSync(topic, value interface{}) (int, error) {
+	return getDefaultBus().PublishSync(topic, value)
+}
+
+// PublishAll sends the given value to all handlers on the default Bus.
+func PublishAll(value interface{}, flags ...PublishFlag) (int, error) {
+	return getDefaultBus().PublishAll(value, flags...)
+}
+
+// SubscribeID causes the passed Handler to be called when data is published
+// to the named topic on the default Bus, returning an identifier that can be
+// passed to UnsubscribeID.
+func SubscribeID(topic interface{}, h Handler) (SubscriptionID, error) {
+	return getDefaultBus().SubscribeID(topic, h)
+}
+
+// Resolve returns the handlers that a value published to the given topic on
+// the default Bus would be delivered to, in the order they would be called.
+func Resolve(topic interface{}) []Handler {
+	return getDefaultBus().Resolve(topic)
+}
+
+// SubscribeSafe is like Subscribe, but fails with ErrNilHandler instead of
+// panicking if h is nil.
+func SubscribeSafe(topic interface{}, h Handler) (UnsubscribeFunc, error) {
+	return getDefaultBus().SubscribeSafe(topic, h)
+}
+
+// UnsubscribeID removes the subscription with the given identifier from the
+// default Bus, returning true on success.
+func UnsubscribeID(id SubscriptionID) bool {
+	return getDefaultBus().UnsubscribeID(id)
+}
+
+// RemoveTopic unsubscribes all handlers from the given topic on the default
+// Bus, returning the number of handlers that were removed.
+func RemoveTopic(topic interface{}) int {
+	return getDefaultBus().RemoveTopic(topic)
+}
+
+// RemoveTopicReturning unsubscribes all handlers from the given topic on the
+// default Bus, returning the handlers that were removed.
+func RemoveTopicReturning(topic interface{}) []Handler {
+	return getDefaultBus().RemoveTopicReturning(topic)
+}
+
+// ReplaceHandlers replaces all handlers of the given topic on the default Bus
+// with the given handlers, returning those that were removed.
+func ReplaceHandlers(topic interface{}, handlers []Handler) []Handler {
+	return getDefaultBus().ReplaceHandlers(topic, handlers)
+}
+
+// UnsubscribeReturning removes the specified handler from the given topic on
+// the default Bus, returning the removed handler and true on success.
+func UnsubscribeReturning(topic interface{}, h Handler) (Handler, bool) {
+	return getDefaultBus().UnsubscribeReturning(topic, h)
+}
+
+// Unsubscribe removes the specified handler from the given topic on the
+// default Bus, returning true on success (i.e. the handler was found and
 // removed)
 func Unsubscribe(topic interface{}, h Handler) {
 	getDefaultBus().Unsubscribe(topic, h)
 }
+
+// MoveToFront moves the specified handler to the front of the handlers of the
+// given topic on the default Bus, returning true if it was found.
+func MoveToFront(topic interface{}, h Handler) bool {
+	return getDefaultBus().MoveToFront(topic, h)
+}
+
+// MoveToBack moves the specified handler to the back of the handlers of the
+// given topic on the default Bus, returning true if it was found.
+func MoveToBack(topic interface{}, h Handler) bool {
+	return getDefaultBus().MoveToBack(topic, h)
+}
@@ -1,8 +1,15 @@
 package bus
 
 import (
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // TestEmpty checks the behaviour of a Bus with no listeners
@@ -29,6 +36,24 @@ func TestDefaultBus(t *testing.T) {
 	assert.NoError(t, err, "publishing to single subscriber")
 }
 
+func TestSetDefaultBus(t *testing.T) {
+	prev := getDefaultBus()
+	defer SetDefaultBus(prev)
+
+	bus := NewBus()
+	SetDefaultBus(bus)
+	SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	assert.True(t, bus.Has("test"), "package functions should use the new bus")
+	assert.False(t, prev.Has("test"))
+
+	ResetDefaultBus()
+	assert.False(t, Has("test"), "reset bus should have no subscriptions")
+	assert.NotSame(t, bus, getDefaultBus())
+
+	SetDefaultBus(nil)
+	assert.NotNil(t, getDefaultBus(), "a bus should be created when needed")
+}
+
 func TestPublish(t *testing.T) {
 	bus := NewBus()
 	c1 := 0
@@ -87,6 +112,76 @@ func TestPublishAll(t *testing.T) {
 	assert.Equal(t, 1, c)
 }
 
+func TestPublishSync(t *testing.T) {
+	bus := NewBus()
+	id := goroutineID()
+	calls := 0
+	record := func(b *Bus, tp, v interface{}) {
+		assert.Equal(t, id, goroutineID(), "handler should be called by the publisher")
+		calls++
+	}
+	bus.SubscribeFunc("test", record)
+	bus.SubscribeAsync("test", HandlerFunc(record))
+
+	before := runtime.NumGoroutine()
+	n, err := bus.PublishSync("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, calls, "all handlers should have returned")
+	assert.Equal(t, before, runtime.NumGoroutine(), "no goroutines should be started")
+	assert.Equal(t, 0, bus.Drain())
+}
+
+// TestPublishAllSync checks that, without the Async flag, PublishAll calls
+// every handler of every topic in the publishing goroutine before returning.
+func TestPublishAllChecks(t *testing.T) {
+	bus := NewBus(WithClock(NewFakeClock(time.Unix(0, 0))))
+	handlers := map[string]*mockHandler{}
+	for _, topic := range []string{"closed", "typed", "limited", "retained", "plain"} {
+		handlers[topic] = &mockHandler{}
+	}
+	assert.NoError(t, bus.CloseTopic("closed"))
+	bus.RegisterTopicType("typed", reflect.TypeOf(0))
+	bus.SetTopicRateLimit("limited", 1, 1)
+	bus.Publish("limited", 0)
+	bus.Retain("retained")
+	for topic, h := range handlers {
+		bus.Subscribe(topic, h)
+	}
+
+	n, err := bus.PublishAll("v")
+	assert.Equal(t, 2, n)
+	assert.True(t, errors.Is(err, ErrTopicClosed), "closed topics should be refused")
+	assert.True(t, errors.Is(err, ErrPayloadType), "values of the wrong type should be refused")
+	assert.True(t, errors.Is(err, ErrRateLimited), "rate limits should apply")
+	for _, topic := range []string{"closed", "typed", "limited"} {
+		assert.Nil(t, handlers[topic].v, topic)
+	}
+	assert.Equal(t, "v", handlers["plain"].v)
+	v, ok := bus.GetRetained("retained")
+	assert.True(t, ok, "the value should be retained")
+	assert.Equal(t, "v", v)
+}
+
+func TestPublishAllSync(t *testing.T) {
+	bus := NewBus()
+	id := goroutineID()
+	var got []interface{}
+	for _, topic := range []string{"a", "b", "c"} {
+		for i := 0; i < 2; i++ {
+			bus.SubscribeFunc(topic, func(b *Bus, tp, v interface{}) {
+				assert.Equal(t, id, goroutineID(), "handler should be called by the publisher")
+				got = append(got, tp)
+			})
+		}
+	}
+
+	n, err := bus.PublishAll(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.ElementsMatch(t, []interface{}{"a", "a", "b", "b", "c", "c"}, got)
+}
+
 // TestPublishAsync asserts that the `Async` flag does not block `Publish`.
 func TestPublishAsync(t *testing.T) {
 	c := make(chan int)
@@ -123,6 +218,31 @@ func TestOnce(t *testing.T) {
 	assert.Equal(t, 1, cnt)
 }
 
+func TestOnceHandler(t *testing.T) {
+	cnt := 0
+	defer Once("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		cnt++
+		assert.Equal(t, 1, cnt)
+	}))()
+
+	n, err := Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, cnt)
+
+	n, err = Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, cnt)
+
+	unsub := Once("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		cnt++
+	}))
+	assert.True(t, unsub(), "pending handler should be cancelled")
+	Publish("test", "hello")
+	assert.Equal(t, 1, cnt)
+}
+
 func TestOnceAync(t *testing.T) {
 	c := make(chan int)
 	cnt := 0
@@ -171,3 +291,1202 @@ func TestHandler(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "world", h.v)
 }
+
+func TestSubscribeFilter(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	dereg := bus.SubscribeFilter("test", func(v interface{}) bool {
+		return v == "pass"
+	}, h)
+
+	n, err := bus.Publish("test", "pass")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "matching value should be delivered")
+	assert.Equal(t, "pass", h.v)
+
+	n, err = bus.Publish("test", "fail")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "filtered value should not be counted")
+	assert.Equal(t, "pass", h.v, "filtered value should not be delivered")
+
+	assert.True(t, dereg(), "filter handler should unsubscribe")
+	n, err = bus.Publish("test", "pass")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestSubscribeFilterAsync(t *testing.T) {
+	bus := NewBus()
+	c := make(chan interface{}, 2)
+	defer bus.SubscribeFilter("test", func(v interface{}) bool {
+		return v == "pass"
+	}, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		c <- v
+	}))()
+
+	n, err := bus.Publish("test", "fail", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = bus.Publish("test", "pass", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "pass", <-c)
+}
+
+func TestSubscribeGated(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	var open atomic.Bool
+	dereg := bus.SubscribeGated("test", open.Load, h)
+
+	n, err := bus.Publish("test", "closed")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "value published while gate is closed should not be counted")
+	assert.Nil(t, h.v)
+
+	open.Store(true)
+	n, err = bus.Publish("test", "open")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "open", h.v)
+
+	open.Store(false)
+	n, _ = bus.Publish("test", "closed")
+	assert.Equal(t, 0, n, "gate should be evaluated on each delivery")
+	assert.Equal(t, "open", h.v)
+
+	assert.True(t, dereg())
+}
+
+func TestRemoveTopic(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("test", &mockHandler{})
+	bus.Subscribe("test", &mockHandler{})
+	bus.Subscribe("other", &mockHandler{})
+
+	assert.Equal(t, 2, bus.RemoveTopic("test"))
+	assert.Equal(t, 0, bus.RemoveTopic("test"), "topic should already be removed")
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = bus.Publish("other", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "other topics should be unaffected")
+}
+
+func TestReset(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("a", &mockHandler{})
+	bus.Subscribe("b", &mockHandler{})
+	bus.Reset()
+
+	n, err := bus.PublishAll("hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "no handlers should remain after reset")
+}
+
+func TestClose(t *testing.T) {
+	bus := NewBus()
+	done := false
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		time.Sleep(10 * time.Millisecond)
+		done = true
+	})
+
+	n, err := bus.Publish("test", "hello", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	assert.NoError(t, bus.Close())
+	assert.True(t, done, "close should wait for async handlers")
+
+	n, err = bus.Publish("test", "hello")
+	assert.Equal(t, ErrBusClosed, err)
+	assert.Equal(t, 0, n)
+
+	n, err = bus.PublishAll("hello")
+	assert.Equal(t, ErrBusClosed, err)
+	assert.Equal(t, 0, n)
+
+	assert.Equal(t, ErrBusClosed, bus.Close(), "second close should fail")
+}
+
+func TestCloseTimeout(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		<-release
+	})
+	bus.Publish("test", 1, Async)
+
+	assert.Equal(t, ErrTimeout, bus.CloseTimeout(10*time.Millisecond))
+	_, err := bus.Publish("test", 2)
+	assert.Equal(t, ErrBusClosed, err, "bus should be left closed")
+	assert.Equal(t, ErrBusClosed, bus.CloseTimeout(time.Second))
+	close(release)
+
+	bus = NewBus()
+	done := false
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		time.Sleep(10 * time.Millisecond)
+		done = true
+	})
+	bus.Publish("test", 1, Async)
+	assert.NoError(t, bus.CloseTimeout(time.Second))
+	assert.True(t, done, "close should wait for async handlers")
+}
+
+// TestSlowHandlerDoesNotBlock checks that a slow synchronous handler does not
+// prevent other goroutines from subscribing or unsubscribing.
+func TestSlowHandlerDoesNotBlock(t *testing.T) {
+	bus := NewBus()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		close(entered)
+		<-release
+	})
+
+	go bus.Publish("test", "hello")
+	<-entered
+
+	done := make(chan struct{})
+	go func() {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("subscribe blocked by slow handler")
+	}
+	close(release)
+}
+
+// TestConcurrentSubscribePublish exercises concurrent subscribers and
+// publishers, and is intended to be run with -race.
+func TestConcurrentSubscribePublish(t *testing.T) {
+	bus := NewBus()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, err := bus.Publish("test", j)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkPublish(b *testing.B) {
+	bus := NewBus()
+	for i := 0; i < 10; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bus.Publish("test", i)
+	}
+}
+
+func benchmarkPublishHandlers(b *testing.B, n int) {
+	bus := NewBus()
+	for i := 0; i < n; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bus.Publish("test", "value")
+	}
+}
+
+func BenchmarkPublish1(b *testing.B)   { benchmarkPublishHandlers(b, 1) }
+func BenchmarkPublish10(b *testing.B)  { benchmarkPublishHandlers(b, 10) }
+func BenchmarkPublish100(b *testing.B) { benchmarkPublishHandlers(b, 100) }
+
+func benchmarkSubscribeMany(b *testing.B, reserve bool) {
+	hf := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bus := NewBus()
+		if reserve {
+			bus.ReserveTopic("test", 1000)
+		}
+		for j := 0; j < 1000; j++ {
+			bus.Subscribe("test", &hf)
+		}
+	}
+}
+
+func BenchmarkSubscribeMany(b *testing.B)         { benchmarkSubscribeMany(b, false) }
+func BenchmarkSubscribeManyReserved(b *testing.B) { benchmarkSubscribeMany(b, true) }
+
+func TestReserveTopic(t *testing.T) {
+	bus := NewBus()
+	hf := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	bus.ReserveTopic("test", 100)
+	assert.False(t, bus.Has("test"), "reserving should not subscribe")
+	n, _ := bus.PublishAll(1)
+	assert.Equal(t, 0, n)
+
+	allocs := testing.AllocsPerRun(1, func() {
+		bus.Reset()
+		for i := 0; i < 100; i++ {
+			bus.Subscribe("test", &hf)
+		}
+	})
+	n, _ = bus.Publish("test", 1)
+	assert.Equal(t, 100, n)
+
+	unreserved := testing.AllocsPerRun(1, func() {
+		bus.Reset()
+		for i := 0; i < 100; i++ {
+			bus.Subscribe("other", &hf)
+		}
+	})
+	assert.Less(t, allocs, unreserved, "reserved topic should not reallocate")
+
+	bus.Subscribe("grown", &hf)
+	bus.ReserveTopic("grown", 10)
+	n, _ = bus.Publish("grown", 1)
+	assert.Equal(t, 1, n, "reserving should keep existing handlers")
+}
+
+func TestSubscribeAll(t *testing.T) {
+	bus := NewBus()
+	var order []string
+	bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) {
+		order = append(order, "a")
+	})
+	all := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		order = append(order, "all:"+tp.(string))
+	})
+	unsub := bus.SubscribeAll(&all)
+
+	n, err := bus.Publish("a", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n, "count should include global handler")
+	assert.Equal(t, []string{"a", "all:a"}, order, "global handlers run last")
+
+	order = nil
+	n, err = bus.Publish("b", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "global handler should receive unsubscribed topics")
+	assert.Equal(t, []string{"all:b"}, order)
+
+	order = nil
+	n, err = bus.PublishAll("hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"a", "all:a"}, order)
+
+	assert.True(t, unsub())
+	assert.False(t, unsub())
+	n, err = bus.Publish("b", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestSubscribeAllAsync(t *testing.T) {
+	bus := NewBus()
+	c := make(chan interface{})
+	all := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		c <- tp
+	})
+	defer bus.SubscribeAll(&all)()
+
+	n, err := bus.Publish("test", "hello", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "test", <-c)
+}
+
+func TestOnNoSubscribers(t *testing.T) {
+	bus := NewBus()
+	var orphans []interface{}
+	bus.OnNoSubscribers = func(topic, value interface{}) {
+		orphans = append(orphans, topic, value)
+	}
+
+	n, err := bus.Publish("nobody", "hello", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, []interface{}{"nobody", "hello"}, orphans)
+
+	orphans = nil
+	defer bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})()
+	bus.Publish("test", "hello")
+	assert.Nil(t, orphans, "callback should not fire when handlers exist")
+
+	all := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	defer bus.SubscribeAll(&all)()
+	bus.Publish("nobody", "hello")
+	assert.Nil(t, orphans, "global handlers count as subscribers")
+}
+
+func TestSetTransform(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	defer bus.Subscribe("test", h)()
+
+	calls := 0
+	bus.SetTransform("test", func(v interface{}) interface{} {
+		calls++
+		if v == "secret" {
+			return nil
+		}
+		return strings.ToUpper(v.(string))
+	})
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "HELLO", h.v, "handler should receive transformed value")
+
+	n, err = bus.Publish("test", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "nil transform result should suppress publish")
+	assert.Equal(t, "HELLO", h.v)
+
+	n, err = bus.PublishAll("world")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "WORLD", h.v, "transform should apply to PublishAll")
+	assert.Equal(t, 3, calls, "transform should run once per publish")
+
+	bus.SetTransform("test", nil)
+	bus.Publish("test", "hello")
+	assert.Equal(t, "hello", h.v, "transform should be removable")
+}
+
+func TestSubscribeID(t *testing.T) {
+	bus := NewBus()
+	c := 0
+	id, err := bus.SubscribeID("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		c++
+	}))
+	assert.NoError(t, err)
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, c)
+
+	assert.True(t, bus.UnsubscribeID(id), "subscription should be removed by id")
+	assert.False(t, bus.UnsubscribeID(id), "subscription should already be removed")
+
+	n, err = bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	bus.Close()
+	_, err = bus.SubscribeID("test", &mockHandler{})
+	assert.Equal(t, ErrBusClosed, err)
+}
+
+// TestUnsubscribeUncomparable checks that handlers of uncomparable types can
+// be subscribed and unsubscribed without panicking.
+func TestUnsubscribeUncomparable(t *testing.T) {
+	bus := NewBus()
+	h := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	unsub := bus.Subscribe("test", h)
+
+	assert.False(t, bus.Unsubscribe("test", h), "uncomparable handler cannot be found")
+	assert.True(t, unsub(), "unsubscribe func should remove handler")
+	assert.False(t, unsub())
+}
+
+func TestPublishTimeout(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		<-release
+	})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		close(finished)
+	})
+
+	n, err := bus.PublishTimeout("test", "hello", 10*time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+	assert.Equal(t, 1, n, "only the first handler should complete in time")
+
+	close(release)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Error("remaining handlers should run in the background")
+	}
+}
+
+func TestPublishTimeoutComplete(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+
+	n, err := bus.PublishTimeout("test", "hello", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestSubscriberCount(t *testing.T) {
+	bus := NewBus()
+	assert.Equal(t, 0, bus.SubscriberCount("test"))
+	assert.Equal(t, 0, bus.TotalSubscribers())
+
+	unsub := bus.Subscribe("test", &mockHandler{})
+	bus.Subscribe("test", &mockHandler{})
+	bus.Subscribe("other", &mockHandler{})
+	assert.Equal(t, 2, bus.SubscriberCount("test"))
+	assert.Equal(t, 3, bus.TotalSubscribers())
+
+	unsub()
+	assert.Equal(t, 1, bus.SubscriberCount("test"))
+	assert.Equal(t, 2, bus.TotalSubscribers())
+
+	allocs := testing.AllocsPerRun(100, func() {
+		bus.SubscriberCount("test")
+		bus.TotalSubscribers()
+	})
+	assert.Equal(t, 0.0, allocs, "counting subscribers should not allocate")
+}
+
+// TestPublishAllReentrant checks that handlers called by PublishAll may use
+// the bus without deadlocking.
+func TestPublishAllReentrant(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) {
+		b.SubscribeFunc("b", func(b *Bus, tp, v interface{}) {})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		n, err := bus.PublishAll("hello")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("PublishAll deadlocked")
+	}
+	assert.Equal(t, 1, bus.SubscriberCount("b"))
+}
+
+func TestPublishAllAsync(t *testing.T) {
+	bus := NewBus()
+	c := make(chan interface{}, 2)
+	bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) {
+		c <- tp
+	})
+	bus.SubscribeFunc("b", func(b *Bus, tp, v interface{}) {
+		c <- tp
+	})
+
+	n, err := bus.PublishAll("hello", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	bus.Drain()
+	assert.Len(t, c, 2)
+}
+
+// TestUnsubscribeFuncIdentity checks that each UnsubscribeFunc removes only
+// the subscription it was created for.
+func TestUnsubscribeFuncIdentity(t *testing.T) {
+	bus := NewBus()
+	c := 0
+	hf := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		c++
+	})
+	unsub1 := bus.Subscribe("test", &hf)
+	unsub2 := bus.Subscribe("test", &hf)
+
+	n, _ := bus.Publish("test", "hello")
+	assert.Equal(t, 2, n)
+
+	assert.True(t, unsub1())
+	assert.False(t, unsub1(), "second call should not remove another subscription")
+	n, _ = bus.Publish("test", "hello")
+	assert.Equal(t, 1, n, "second subscription should remain")
+
+	assert.True(t, unsub2())
+	assert.False(t, unsub2())
+	n, _ = bus.Publish("test", "hello")
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 3, c)
+}
+
+func TestUnsubscribeReturning(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	bus.Namespace("ns").Subscribe("test", h)
+
+	removed, ok := bus.UnsubscribeReturning("ns.test", h)
+	assert.True(t, ok)
+	assert.Same(t, h, removed, "handler should be returned as subscribed")
+	removed, ok = bus.UnsubscribeReturning("ns.test", h)
+	assert.False(t, ok)
+	assert.Nil(t, removed)
+}
+
+func TestRemoveTopicReturning(t *testing.T) {
+	bus := NewBus()
+	h1, h2 := &mockHandler{}, &mockHandler{}
+	bus.Subscribe("test", h1)
+	bus.Subscribe("test", h2)
+
+	hs := bus.RemoveTopicReturning("test")
+	assert.Equal(t, []Handler{h1, h2}, hs)
+	assert.Equal(t, 0, bus.SubscriberCount("test"))
+	assert.Empty(t, bus.RemoveTopicReturning("test"))
+
+	// Migrate the removed handlers to another topic
+	for _, h := range hs {
+		bus.Subscribe("other", h)
+	}
+	n, _ := bus.Publish("other", 1)
+	assert.Equal(t, 2, n)
+}
+
+func TestReplaceHandlers(t *testing.T) {
+	bus := NewBus()
+	old1, old2 := &mockHandler{}, &mockHandler{}
+	unsub := bus.Subscribe("test", old1)
+	bus.Subscribe("test", old2)
+
+	new1, new2 := &mockHandler{}, &mockHandler{}
+	hs := bus.ReplaceHandlers("test", []Handler{new1, new2})
+	assert.Equal(t, []Handler{old1, old2}, hs)
+	assert.Equal(t, []Handler{new1, new2}, bus.Resolve("test"))
+	assert.False(t, unsub(), "old unsubscribe funcs should have no effect")
+	assert.Equal(t, 2, bus.SubscriberCount("test"))
+
+	n, _ := bus.Publish("test", 1)
+	assert.Equal(t, 2, n)
+
+	assert.Panics(t, func() {
+		bus.ReplaceHandlers("test", []Handler{new1, nil})
+	})
+	assert.Equal(t, []Handler{new1, new2}, bus.Resolve("test"), "nothing should be replaced after a panic")
+
+	assert.Equal(t, []Handler{new1, new2}, bus.ReplaceHandlers("test", nil))
+	assert.Equal(t, 0, bus.SubscriberCount("test"))
+}
+
+// TestReplaceHandlersAtomic checks that concurrent publishes see either the
+// old handlers or the new ones.
+func TestReplaceHandlersAtomic(t *testing.T) {
+	bus := NewBus()
+	a := []Handler{HandlerFunc(func(b *Bus, tp, v interface{}) {}), HandlerFunc(func(b *Bus, tp, v interface{}) {})}
+	c := []Handler{HandlerFunc(func(b *Bus, tp, v interface{}) {}), HandlerFunc(func(b *Bus, tp, v interface{}) {}), HandlerFunc(func(b *Bus, tp, v interface{}) {})}
+	bus.ReplaceHandlers("test", a)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if i%2 == 0 {
+				bus.ReplaceHandlers("test", c)
+			} else {
+				bus.ReplaceHandlers("test", a)
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		n, _ := bus.Publish("test", 1)
+		if n != 2 && n != 3 {
+			t.Fatalf("publish saw %d handlers", n)
+		}
+	}
+}
+
+func TestMoveHandler(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	handlers := make([]HandlerFunc, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		name := name
+		handlers[i] = func(b *Bus, tp, v interface{}) {
+			got = append(got, name)
+		}
+		bus.Subscribe("test", &handlers[i])
+	}
+
+	assert.True(t, bus.MoveToFront("test", &handlers[2]))
+	bus.Publish("test", 1)
+	assert.Equal(t, []string{"c", "a", "b"}, got)
+
+	got = nil
+	assert.True(t, bus.MoveToBack("test", &handlers[0]))
+	bus.Publish("test", 1)
+	assert.Equal(t, []string{"c", "b", "a"}, got)
+
+	assert.False(t, bus.MoveToFront("test", &mockHandler{}), "unknown handler should not be found")
+	assert.False(t, bus.MoveToBack("other", &handlers[0]))
+}
+
+func TestMoveHandlerDuringPublish(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	var first, second HandlerFunc
+	first = func(b *Bus, tp, v interface{}) {
+		got = append(got, "first")
+		b.MoveToFront("test", &second)
+	}
+	second = func(b *Bus, tp, v interface{}) {
+		got = append(got, "second")
+	}
+	bus.Subscribe("test", &first)
+	bus.Subscribe("test", &second)
+
+	bus.Publish("test", 1)
+	assert.Equal(t, []string{"first", "second"}, got, "publish in progress should be unaffected")
+	got = nil
+	bus.Publish("test", 2)
+	assert.Equal(t, []string{"second", "first"}, got)
+}
+
+func TestDedupSubscriptions(t *testing.T) {
+	for _, tc := range []struct {
+		opts  []BusOption
+		count int
+	}{
+		{nil, 2},
+		{[]BusOption{WithDedupSubscriptions()}, 1},
+	} {
+		bus := NewBus(tc.opts...)
+		h := &mockHandler{}
+		unsub1 := bus.Subscribe("test", h)
+		unsub2 := bus.Subscribe("test", h)
+		bus.Subscribe("other", h)
+
+		n, _ := bus.Publish("test", "hello")
+		assert.Equal(t, tc.count, n)
+		assert.Equal(t, tc.count, bus.SubscriberCount("test"))
+		assert.Equal(t, 1, bus.SubscriberCount("other"), "other topics should be unaffected")
+
+		assert.True(t, unsub2())
+		n, _ = bus.Publish("test", "hello")
+		assert.Equal(t, tc.count-1, n)
+		assert.Equal(t, tc.count == 2, unsub1(), "first function should only remove a remaining subscription")
+		n, _ = bus.Publish("test", "hello")
+		assert.Equal(t, 0, n)
+	}
+}
+
+// TestUnsubscribeDuringPublish checks that a handler unsubscribing itself
+// does not cause later handlers to be skipped.
+func TestUnsubscribeDuringPublish(t *testing.T) {
+	bus := NewBus()
+	var order []int
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		order = append(order, 1)
+	})
+	var unsub UnsubscribeFunc
+	unsub = bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		order = append(order, 2)
+		unsub()
+	})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		order = append(order, 3)
+	})
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int{1, 2, 3}, order, "all handlers should fire")
+
+	order = nil
+	n, err = bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int{1, 3}, order, "removal should apply to later publishes")
+}
+
+func TestSubscribeFallback(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	fallback := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, tp)
+	})
+	unsub := bus.SubscribeFallback(&fallback)
+	bus.SubscribeFilter("filtered", func(v interface{}) bool {
+		return v == "pass"
+	}, &mockHandler{})
+	bus.Subscribe("handled", &mockHandler{})
+
+	orphaned := false
+	bus.OnNoSubscribers = func(topic, value interface{}) {
+		orphaned = true
+	}
+
+	n, err := bus.Publish("unknown", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "fallback should count as a delivery")
+	assert.False(t, orphaned, "fallback handlers count as subscribers")
+
+	n, err = bus.Publish("handled", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = bus.Publish("filtered", "pass")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = bus.Publish("filtered", "fail")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "fallback should fire when no handler accepts")
+
+	assert.Equal(t, []interface{}{"unknown", "filtered"}, got)
+
+	assert.True(t, unsub())
+	n, _ = bus.Publish("unknown", "hello")
+	assert.Equal(t, 0, n)
+	assert.True(t, orphaned)
+}
+
+func TestSubscribeFallbackOrder(t *testing.T) {
+	bus := NewBus()
+	var order []string
+	fallback := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		order = append(order, "fallback")
+	})
+	all := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		order = append(order, "all")
+	})
+	bus.SubscribeAll(&all)
+	bus.SubscribeFallback(&fallback)
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"fallback", "all"}, order)
+}
+
+func TestPublishFunc(t *testing.T) {
+	bus := NewBus()
+	produced := 0
+	produce := func() interface{} {
+		produced++
+		return "value"
+	}
+
+	n, err := bus.PublishFunc("test", produce)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 0, produced, "value should not be produced without handlers")
+
+	var got interface{}
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		got = v
+	})
+	n, err = bus.PublishFunc("test", produce)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, produced)
+	assert.Equal(t, "value", got)
+}
+
+func TestPublishFuncOnNoSubscribers(t *testing.T) {
+	bus := NewBus()
+	var orphan interface{}
+	bus.OnNoSubscribers = func(topic, value interface{}) {
+		orphan = value
+	}
+
+	bus.PublishFunc("test", func() interface{} { return "value" })
+	assert.Equal(t, "value", orphan)
+}
+
+func TestPublishSingleHandler(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	unsub := bus.SubscribeAll(HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "lone global handler should be called")
+	unsub()
+
+	bus.SubscribeFilter("test", func(v interface{}) bool { return v != 2 }, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	n, _ = bus.Publish("test", 2)
+	assert.Equal(t, 0, n, "declined value should not be counted")
+	n, _ = bus.Publish("test", 3, Async)
+	assert.Equal(t, 1, n)
+	bus.Drain()
+
+	assert.Equal(t, []interface{}{1, 3}, got)
+	assert.Equal(t, TopicStats{PublishCount: 3, DeliverCount: 2, DroppedCount: 1}, bus.Stats()["test"])
+}
+
+func TestHas(t *testing.T) {
+	bus := NewBus()
+	assert.False(t, bus.Has("test"))
+
+	unsub := bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	assert.True(t, bus.Has("test"))
+	assert.False(t, bus.Has("other"))
+
+	unsub()
+	assert.False(t, bus.Has("test"))
+}
+
+func TestDeliveryOrder(t *testing.T) {
+	for _, tc := range []struct {
+		order Order
+		want  []string
+	}{
+		{FIFO, []string{"1", "2", "3", "all"}},
+		{LIFO, []string{"3", "2", "1", "all"}},
+	} {
+		bus := NewBus(WithDeliveryOrder(tc.order))
+		var got []string
+		for _, name := range []string{"1", "2", "3"} {
+			name := name
+			bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+				got = append(got, name)
+			})
+		}
+		bus.SubscribeAll(HandlerFunc(func(b *Bus, tp, v interface{}) {
+			got = append(got, "all")
+		}))
+
+		n, _ := bus.Publish("test", 1)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, tc.want, got)
+	}
+}
+
+func TestGlobalOrder(t *testing.T) {
+	for _, tc := range []struct {
+		opts []BusOption
+		want []string
+	}{
+		{nil, []string{"1", "2", "all1", "all2"}},
+		{[]BusOption{WithGlobalOrder(false)}, []string{"1", "2", "all1", "all2"}},
+		{[]BusOption{WithGlobalOrder(true)}, []string{"all1", "all2", "1", "2"}},
+		{[]BusOption{WithGlobalOrder(true), WithDeliveryOrder(LIFO)}, []string{"all2", "all1", "2", "1"}},
+	} {
+		bus := NewBus(tc.opts...)
+		var got []string
+		record := func(name string) HandlerFunc {
+			return func(b *Bus, tp, v interface{}) {
+				got = append(got, name)
+			}
+		}
+		bus.Subscribe("test", record("1"))
+		bus.SubscribeAll(record("all1"))
+		bus.Subscribe("test", record("2"))
+		bus.SubscribeAll(record("all2"))
+
+		n, err := bus.Publish("test", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n, "order should not affect the count")
+		assert.Equal(t, tc.want, got)
+		assert.Len(t, bus.Resolve("test"), 4)
+	}
+}
+
+func TestPublishExcept(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	var self HandlerFunc
+	self = func(b *Bus, tp, v interface{}) {
+		got = append(got, "self")
+		if v == 1 {
+			b.PublishExcept(tp, 2, &self)
+		}
+	}
+	bus.Subscribe("test", &self)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		got = append(got, "other")
+	})
+
+	n, _ := bus.Publish("test", 1)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"self", "other", "other"}, got)
+
+	n, _ = bus.PublishExcept("test", 3, &self)
+	assert.Equal(t, 1, n, "skipped handler should not be counted")
+}
+
+func TestPublishRoute(t *testing.T) {
+	bus := NewBus()
+	even, odd := &mockHandler{}, &mockHandler{}
+	bus.Subscribe("even", even)
+	bus.Subscribe("odd", odd)
+	bus.SubscribeFunc("odd", func(b *Bus, tp, v interface{}) {})
+
+	calls := 0
+	isEven := func(v interface{}) bool {
+		calls++
+		return v.(int)%2 == 0
+	}
+
+	n, err := bus.PublishRoute(2, isEven, "even", "odd")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, even.v)
+	assert.Nil(t, odd.v)
+
+	n, err = bus.PublishRoute(3, isEven, "even", "odd", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n, "count should be that of the false topic")
+	bus.Drain()
+	assert.Equal(t, 3, odd.v)
+	assert.Equal(t, 2, even.v)
+	assert.Equal(t, 2, calls, "predicate should be called once per publish")
+}
+
+func TestSubscribeNilHandler(t *testing.T) {
+	bus := NewBus()
+	assert.PanicsWithValue(t, "bus: nil handler", func() {
+		bus.Subscribe("test", nil)
+	})
+	assert.PanicsWithValue(t, "bus: nil handler", func() {
+		bus.SubscribeFunc("test", nil)
+	})
+	assert.PanicsWithValue(t, "bus: nil handler", func() {
+		bus.SubscribeAll(nil)
+	})
+	assert.PanicsWithValue(t, "bus: nil handler", func() {
+		bus.SubscribeFilter("test", func(v interface{}) bool { return true }, nil)
+	})
+	assert.PanicsWithValue(t, "bus: nil handler", func() {
+		bus.SubscribeGated("test", func() bool { return true }, nil)
+	})
+	assert.PanicsWithValue(t, "bus: nil handler", func() {
+		bus.OnceFilter("test", func(v interface{}) bool { return true }, nil)
+	})
+	assert.False(t, bus.Has("test"), "nil handlers should not be subscribed")
+
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestSubscribeSafe(t *testing.T) {
+	bus := NewBus()
+
+	unsub, err := bus.SubscribeSafe("test", nil)
+	assert.Equal(t, ErrNilHandler, err)
+	assert.Nil(t, unsub)
+
+	var hf *HandlerFunc
+	_, err = bus.SubscribeSafe("test", hf)
+	assert.Equal(t, ErrNilHandler, err, "nil pointer should be rejected")
+
+	var fn HandlerFunc
+	_, err = bus.SubscribeSafe("test", &fn)
+	assert.Equal(t, ErrNilHandler, err, "nil func should be rejected")
+	assert.False(t, bus.Has("test"))
+
+	calls := 0
+	unsub, err = bus.SubscribeSafe("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		calls++
+	}))
+	assert.NoError(t, err)
+	bus.Publish("test", 1)
+	assert.Equal(t, 1, calls)
+	assert.True(t, unsub())
+
+	bus.Close()
+	_, err = bus.SubscribeSafe("test", HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+	assert.Equal(t, ErrBusClosed, err)
+}
+
+func TestResolve(t *testing.T) {
+	bus := NewBus(WithBubbling())
+	assert.Empty(t, bus.Resolve("a.b"))
+
+	h := func() *HandlerFunc {
+		hf := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+		return &hf
+	}
+	exact, parent, global, fallback := h(), h(), h(), h()
+	bus.SubscribeFallback(fallback)
+	bus.SubscribeAll(global)
+	bus.Subscribe("a", parent)
+	bus.Subscribe("a.b", exact)
+
+	hs := bus.Resolve("a.b")
+	assert.Equal(t, []Handler{exact, parent, global, fallback}, hs)
+
+	hs[0] = nil
+	assert.Equal(t, Handler(exact), bus.Resolve("a.b")[0], "result should be a copy")
+
+	assert.NoError(t, bus.Alias("x", "a"))
+	assert.Equal(t, []Handler{parent, global, fallback}, bus.Resolve("x"), "aliases should be followed")
+
+	ns := bus.Namespace("a")
+	assert.Equal(t, []Handler{exact, parent, global, fallback}, ns.Resolve("b"))
+
+	bus.Reset()
+	only := h()
+	bus.Subscribe("a", only)
+	assert.Equal(t, []Handler{only}, bus.Resolve("a"))
+}
+
+func TestInvalidTopicQueries(t *testing.T) {
+	bus := NewBus(WithHistory("test", 1))
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+	bad := []int{}
+
+	assert.Nil(t, bus.Resolve(bad))
+	assert.False(t, bus.Has(bad))
+	assert.Equal(t, 0, bus.SubscriberCount(bad))
+	assert.False(t, bus.Unsubscribe(bad, h))
+	_, ok := bus.UnsubscribeReturning(bad, h)
+	assert.False(t, ok)
+	assert.Equal(t, 0, bus.RemoveTopic(bad))
+	assert.Nil(t, bus.RemoveTopicReturning(bad))
+	_, ok = bus.GetRetained(bad)
+	assert.False(t, ok)
+	_, ok = bus.PeekLast(bad)
+	assert.False(t, ok)
+
+	// The Bus must still be usable afterwards
+	bus.Subscribe("other", h)
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, bus.Close())
+}
+
+// reentrantObserver subscribes to the "observed" topic whenever it observes
+// a subscription to or publish on "test".
+type reentrantObserver struct {
+	bus *Bus
+}
+
+func (o *reentrantObserver) OnSubscribe(topic interface{}, h Handler) {
+	if topic == "test" {
+		o.bus.Subscribe("observed", &mockHandler{})
+	}
+}
+
+func (o *reentrantObserver) OnUnsubscribe(topic interface{}, h Handler) {
+	if topic == "test" {
+		o.bus.RemoveTopic("observed")
+	}
+}
+
+func (o *reentrantObserver) OnPublish(topic, value interface{}, count int) {
+	if topic == "test" {
+		o.bus.Subscribe("observed", &mockHandler{})
+	}
+}
+
+// TestCallbacksMayUseBus checks that handlers, observers and other callbacks
+// can subscribe and unsubscribe without deadlocking, as none of them are
+// called while the Bus is locked.
+func TestCallbacksMayUseBus(t *testing.T) {
+	reenter := func(b *Bus) {
+		unsub := b.Subscribe("other", &mockHandler{})
+		b.SubscriberCount("other")
+		unsub()
+	}
+	for name, fn := range map[string]func(bus *Bus){
+		"handler": func(bus *Bus) {
+			bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+				reenter(b)
+			})
+			bus.Publish("test", 1)
+		},
+		"observer": func(bus *Bus) {
+			o := &reentrantObserver{}
+			bus = NewBus(WithObserver(o))
+			o.bus = bus
+			unsub := bus.Subscribe("test", &mockHandler{})
+			bus.Publish("test", 1)
+			unsub()
+			assert.Equal(t, 0, bus.SubscriberCount("observed"))
+		},
+		"recover": func(bus *Bus) {
+			bus.SubscribeRecover("test", func(r interface{}) {
+				reenter(bus)
+			}, HandlerFunc(func(b *Bus, tp, v interface{}) {
+				panic(v)
+			}))
+			bus.Publish("test", 1)
+		},
+		"no subscribers": func(bus *Bus) {
+			bus.OnNoSubscribers = func(topic, value interface{}) {
+				reenter(bus)
+			}
+			bus.Publish("test", 1)
+		},
+		"transform": func(bus *Bus) {
+			bus.SetTransform("test", func(v interface{}) interface{} {
+				reenter(bus)
+				return v
+			})
+			bus.Publish("test", 1)
+		},
+		"sticky": func(bus *Bus) {
+			bus.Retain("test")
+			bus.Publish("test", 1)
+			bus.SubscribeSticky("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+				reenter(b)
+			}))
+		},
+		"async": func(bus *Bus) {
+			bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+				reenter(b)
+			})
+			bus.Publish("test", 1, Async)
+			bus.Publish("test", 2, OrderedAsync)
+			bus.Drain()
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				fn(NewBus())
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("deadlocked")
+			}
+		})
+	}
+}
+
+func TestStateInvalidTopic(t *testing.T) {
+	bus := NewBus()
+	assert.PanicsWithValue(t, "bus: invalid topic", func() {
+		bus.state([]int{})
+	})
+	bus.Subscribe("test", &mockHandler{})
+	assert.NoError(t, bus.Close(), "the lock should not be held after the panic")
+}
@@ -0,0 +1,77 @@
+package bus
+
+import (
+	"errors"
+	"sync"
+)
+
+// BusGroup is a set of independent Buses that values can be published to
+// together, so that a publish made through the group reaches the handlers of
+// every member. Its methods may be called concurrently.
+type BusGroup struct {
+	lock  sync.RWMutex
+	buses []*Bus
+}
+
+// NewBusGroup returns a BusGroup containing the given Buses.
+func NewBusGroup(buses ...*Bus) *BusGroup {
+	g := &BusGroup{}
+	for _, b := range buses {
+		g.Add(b)
+	}
+	return g
+}
+
+// Add adds the Bus to the group, returning false if it was already a member.
+func (g *BusGroup) Add(b *Bus) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for _, m := range g.buses {
+		if m == b {
+			return false
+		}
+	}
+	g.buses = append(g.buses, b)
+	return true
+}
+
+// Remove removes the Bus from the group, returning false if it was not a
+// member.
+func (g *BusGroup) Remove(b *Bus) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for i, m := range g.buses {
+		if m == b {
+			g.buses = append(g.buses[:i:i], g.buses[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Buses returns the members of the group, in the order they were added.
+func (g *BusGroup) Buses() []*Bus {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	return append([]*Bus(nil), g.buses...)
+}
+
+// Publish sends the given value to the named topic on every member of the
+// group, as Bus.Publish does, returning the total number of handlers it was
+// delivered to. Members that have been closed are skipped, with ErrBusClosed
+// joined into the returned error along with the errors of the other members.
+func (g *BusGroup) Publish(topic interface{}, value interface{}, flags ...PublishFlag) (int, error) {
+	c := 0
+	var errs []error
+	for _, b := range g.Buses() {
+		n, err := b.Publish(topic, value, flags...)
+		c += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return c, errors.Join(errs...)
+}
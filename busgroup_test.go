@@ -0,0 +1,45 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusGroup(t *testing.T) {
+	a, b, c := NewBus(), NewBus(), NewBus()
+	var got []interface{}
+	for _, m := range []*Bus{a, b, c} {
+		m.SubscribeFunc("test", func(_ *Bus, _, v interface{}) {
+			got = append(got, v)
+		})
+	}
+
+	g := NewBusGroup(a, b)
+	assert.False(t, g.Add(a), "buses should only be added once")
+	assert.True(t, g.Add(c))
+	assert.Equal(t, []*Bus{a, b, c}, g.Buses())
+
+	n, err := g.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	assert.True(t, g.Remove(b))
+	assert.False(t, g.Remove(b))
+	n, err = g.Publish("test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []interface{}{1, 1, 1, 2, 2}, got)
+}
+
+func TestBusGroupClosedMember(t *testing.T) {
+	a, b := NewBus(), NewBus()
+	a.SubscribeFunc("test", func(*Bus, interface{}, interface{}) {})
+	b.SubscribeFunc("test", func(*Bus, interface{}, interface{}) {})
+	g := NewBusGroup(a, b)
+
+	assert.NoError(t, a.Close())
+	n, err := g.Publish("test", 1)
+	assert.ErrorIs(t, err, ErrBusClosed)
+	assert.Equal(t, 1, n, "open members should still be published to")
+}
@@ -0,0 +1,157 @@
+// Package bustest provides test doubles for code that uses a bus.Bus.
+//
+// A SpyHandler records every value it is passed, and can be waited on for
+// values delivered asynchronously:
+//
+//	spy := bustest.NewSpyHandler()
+//	b.Subscribe("orders", spy)
+//	placeOrder(b)
+//	spy.AssertCalledN(t, 1, time.Second)
+//	spy.AssertCalledWith(t, "orders", Order{ID: 1})
+//
+// A CollectingBus records every value published to any of its topics.
+package bustest
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	bus "github.com/johnsto/go-bus"
+)
+
+// Call records a single value received by a SpyHandler.
+type Call struct {
+	// Topic is the topic the value was published to.
+	Topic interface{}
+
+	// Value is the value received.
+	Value interface{}
+}
+
+// SpyHandler is a bus.Handler that records each value it receives. It is
+// safe for concurrent use, so may be called by handlers published
+// asynchronously.
+type SpyHandler struct {
+	lock  sync.Mutex
+	cond  *sync.Cond
+	calls []Call
+}
+
+// NewSpyHandler returns a SpyHandler that has not yet received any values.
+func NewSpyHandler() *SpyHandler {
+	h := &SpyHandler{}
+	h.cond = sync.NewCond(&h.lock)
+	return h
+}
+
+// On records the value.
+func (h *SpyHandler) On(b *bus.Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.calls = append(h.calls, Call{Topic: t, Value: v})
+	h.cond.Broadcast()
+}
+
+// Calls returns the values received so far, in the order they were received.
+func (h *SpyHandler) Calls() []Call {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return append([]Call(nil), h.calls...)
+}
+
+// Len returns the number of values received so far.
+func (h *SpyHandler) Len() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.calls)
+}
+
+// Values returns the values received so far on the given topic, in the order
+// they were received.
+func (h *SpyHandler) Values(topic interface{}) []interface{} {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	var vs []interface{}
+	for _, c := range h.calls {
+		if c.Topic == topic {
+			vs = append(vs, c.Value)
+		}
+	}
+	return vs
+}
+
+// Reset discards the values received so far.
+func (h *SpyHandler) Reset() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.calls = nil
+}
+
+// WaitForN blocks until at least n values have been received, returning the
+// values received, or until the timeout elapses, returning false.
+func (h *SpyHandler) WaitForN(n int, timeout time.Duration) ([]Call, bool) {
+	expired := false
+	timer := time.AfterFunc(timeout, func() {
+		h.lock.Lock()
+		defer h.lock.Unlock()
+		expired = true
+		h.cond.Broadcast()
+	})
+	defer timer.Stop()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for len(h.calls) < n && !expired {
+		h.cond.Wait()
+	}
+	return append([]Call(nil), h.calls...), len(h.calls) >= n
+}
+
+// AssertCalledN fails the test unless at least n values are received within
+// the timeout.
+func (h *SpyHandler) AssertCalledN(t testing.TB, n int, timeout time.Duration) {
+	t.Helper()
+	if calls, ok := h.WaitForN(n, timeout); !ok {
+		t.Errorf("bustest: received %d values within %v, want %d", len(calls), timeout, n)
+	}
+}
+
+// AssertCalledWith fails the test unless the value has been received on the
+// topic. Values are compared with reflect.DeepEqual.
+func (h *SpyHandler) AssertCalledWith(t testing.TB, topic, value interface{}) {
+	t.Helper()
+	for _, c := range h.Calls() {
+		if c.Topic == topic && reflect.DeepEqual(c.Value, value) {
+			return
+		}
+	}
+	t.Errorf("bustest: %v not received on topic %v", value, topic)
+}
+
+// AssertNotCalled fails the test if any value has been received.
+func (h *SpyHandler) AssertNotCalled(t testing.TB) {
+	t.Helper()
+	if calls := h.Calls(); len(calls) > 0 {
+		t.Errorf("bustest: received %d values, want none", len(calls))
+	}
+}
+
+// CollectingBus is a bus.Bus that records every value published to any of
+// its topics with a SpyHandler subscribed to all of them.
+type CollectingBus struct {
+	*bus.Bus
+
+	// Spy records the values published to the Bus.
+	Spy *SpyHandler
+}
+
+// NewCollectingBus returns a new CollectingBus, creating its Bus with the
+// given options.
+func NewCollectingBus(opts ...bus.BusOption) *CollectingBus {
+	b := &CollectingBus{Bus: bus.NewBus(opts...), Spy: NewSpyHandler()}
+	b.SubscribeAll(b.Spy)
+	return b
+}
@@ -0,0 +1,79 @@
+package bustest
+
+import (
+	"testing"
+	"time"
+
+	bus "github.com/johnsto/go-bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpyHandler(t *testing.T) {
+	b := bus.NewBus()
+	spy := NewSpyHandler()
+	b.Subscribe("a", spy)
+	b.Subscribe("b", spy)
+
+	spy.AssertNotCalled(t)
+	b.Publish("a", 1)
+	b.Publish("b", 2)
+	b.Publish("a", 3)
+	assert.Equal(t, []Call{{"a", 1}, {"b", 2}, {"a", 3}}, spy.Calls())
+	assert.Equal(t, []interface{}{1, 3}, spy.Values("a"))
+	spy.AssertCalledWith(t, "b", 2)
+
+	spy.Reset()
+	assert.Equal(t, 0, spy.Len())
+}
+
+func TestSpyHandlerWaitForN(t *testing.T) {
+	b := bus.NewBus()
+	spy := NewSpyHandler()
+	b.Subscribe("test", spy)
+
+	for i := 0; i < 10; i++ {
+		b.Publish("test", i, bus.Async)
+	}
+	calls, ok := spy.WaitForN(10, time.Second)
+	assert.True(t, ok)
+	assert.Len(t, calls, 10)
+	spy.AssertCalledN(t, 10, time.Second)
+
+	calls, ok = spy.WaitForN(11, 10*time.Millisecond)
+	assert.False(t, ok, "wait should time out")
+	assert.Len(t, calls, 10)
+}
+
+// failureRecorder is a testing.TB that counts the failures reported to it.
+type failureRecorder struct {
+	testing.TB
+	failures int
+}
+
+func (t *failureRecorder) Helper() {}
+
+func (t *failureRecorder) Errorf(format string, args ...interface{}) {
+	t.failures++
+}
+
+func TestSpyHandlerAssertionsFail(t *testing.T) {
+	spy := NewSpyHandler()
+	spy.On(nil, "test", 1)
+
+	ft := &failureRecorder{TB: t}
+	spy.AssertNotCalled(ft)
+	spy.AssertCalledWith(ft, "test", 2)
+	spy.AssertCalledN(ft, 2, time.Millisecond)
+	assert.Equal(t, 3, ft.failures)
+
+	spy.AssertCalledWith(ft, "test", 1)
+	spy.AssertCalledN(ft, 1, time.Millisecond)
+	assert.Equal(t, 3, ft.failures)
+}
+
+func TestCollectingBus(t *testing.T) {
+	b := NewCollectingBus()
+	b.Publish("a", 1)
+	b.Namespace("ns").Publish("b", 2)
+	assert.Equal(t, []Call{{"a", 1}, {"ns.b", 2}}, b.Spy.Calls())
+}
@@ -0,0 +1,99 @@
+package bus
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ContextHandler is a Handler that can stop early when the publish it is
+// called by is cancelled.
+type ContextHandler interface {
+	Handler
+
+	// OnContext is called in place of On by publishes made with
+	// PublishCancelable, with a context that is done once the publish is
+	// cancelled.
+	OnContext(ctx context.Context, b *Bus, t, v interface{})
+}
+
+// CancelablePublish is a publish made by PublishCancelable whose handlers
+// can be told to stop.
+type CancelablePublish struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	skipped atomic.Int64
+}
+
+// Cancel cancels the publish, so that handlers that have not yet started are
+// not called, and the context passed to running ContextHandlers is done.
+// Handlers already running are not otherwise stopped.
+func (p *CancelablePublish) Cancel() {
+	p.cancel()
+}
+
+// Context returns the context of the publish, which is done once the publish
+// is cancelled.
+func (p *CancelablePublish) Context() context.Context {
+	return p.ctx
+}
+
+// Skipped returns the number of handlers that were not called because the
+// publish was cancelled before they started.
+func (p *CancelablePublish) Skipped() int {
+	return int(p.skipped.Load())
+}
+
+// cancelHandler skips its handler if the publish has been cancelled by the
+// time it is called.
+type cancelHandler struct {
+	h Handler
+	p *CancelablePublish
+}
+
+func (h *cancelHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *cancelHandler) OnErr(b *Bus, t, v interface{}) error {
+	if h.p.ctx.Err() != nil {
+		h.p.skipped.Add(1)
+		return nil
+	}
+	return call(b, h.h, t, v)
+}
+
+func (h *cancelHandler) unwrap() Handler {
+	return h.h
+}
+
+// PublishCancelable sends the given value to all handlers subscribed to the
+// named topic on this Bus, calling each in a separate goroutine as Publish
+// does with the Async flag, and returns the number of handlers along with a
+// CancelablePublish that can be used to call off the publish. Handlers that
+// have not started by the time it is cancelled, or ctx is done, are skipped.
+// ContextHandlers are called through OnContext with the publish's context,
+// so that they can stop early.
+//
+// As with context.WithCancel, Cancel should be called once the handlers'
+// results are known or no longer needed, to release the publish's context.
+func (b *Bus) PublishCancelable(ctx context.Context, topic, value interface{}) (*CancelablePublish, int, error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return nil, 0, err
+	}
+
+	p := &CancelablePublish{}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	d.cancel = p
+
+	cb := &Bus{core: b.core, prefix: b.prefix, teed: b.teed, ctx: p.ctx}
+	n, err := cb.publish(d, Async)
+	return p, n, err
+}
+
+// PublishCancelable sends the given value to all handlers subscribed to the
+// named topic on the default Bus, each in a separate goroutine, returning a
+// CancelablePublish that can be used to call off the publish.
+func PublishCancelable(ctx context.Context, topic, value interface{}) (*CancelablePublish, int, error) {
+	return getDefaultBus().PublishCancelable(ctx, topic, value)
+}
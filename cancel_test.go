@@ -0,0 +1,104 @@
+package bus
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ctxHandler blocks until the context of the publish it is called by is
+// done, signalling on entered once it has started.
+type ctxHandler struct {
+	entered chan struct{}
+	stopped atomic.Bool
+}
+
+func (h *ctxHandler) On(b *Bus, t, v interface{}) {}
+
+func (h *ctxHandler) OnContext(ctx context.Context, b *Bus, t, v interface{}) {
+	close(h.entered)
+	<-ctx.Done()
+	h.stopped.Store(true)
+}
+
+func TestPublishCancelable(t *testing.T) {
+	bus := NewBus()
+	var calls atomic.Int32
+	for i := 0; i < 3; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+			calls.Add(1)
+		})
+	}
+
+	p, n, err := bus.PublishCancelable(context.Background(), "test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	bus.Drain()
+	p.Cancel()
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 0, p.Skipped())
+
+	// Handlers that have not started once the publish is cancelled are
+	// skipped
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p, n, err = bus.PublishCancelable(ctx, "test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	bus.Drain()
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 3, p.Skipped())
+
+	_, _, err = bus.PublishCancelable(context.Background(), []int{}, 1)
+	assert.Equal(t, ErrInvalidTopic, err)
+}
+
+func TestPublishCancelableContextHandler(t *testing.T) {
+	bus := NewBus()
+	h := &ctxHandler{entered: make(chan struct{})}
+	bus.Namespace("ns").Subscribe("test", h)
+
+	p, n, _ := bus.PublishCancelable(context.Background(), "ns.test", 1)
+	assert.Equal(t, 1, n)
+	<-h.entered
+	assert.False(t, h.stopped.Load())
+	p.Cancel()
+	bus.Drain()
+	assert.True(t, h.stopped.Load(), "running handlers should see the cancellation")
+	assert.Equal(t, 0, p.Skipped())
+
+	// Other publishes call On
+	n, _ = bus.Publish("ns.test", 2)
+	assert.Equal(t, 1, n)
+}
+
+// countingCtxHandler counts how it is called.
+type countingCtxHandler struct {
+	on, onContext atomic.Int32
+}
+
+func (h *countingCtxHandler) On(b *Bus, t, v interface{}) {
+	h.on.Add(1)
+}
+
+func (h *countingCtxHandler) OnContext(ctx context.Context, b *Bus, t, v interface{}) {
+	h.onContext.Add(1)
+}
+
+func TestPublishCancelableNested(t *testing.T) {
+	bus := NewBus()
+	h := &countingCtxHandler{}
+	bus.Subscribe("other", h)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		b.Publish("other", v)
+	})
+
+	p, _, err := bus.PublishCancelable(context.Background(), "test", 1)
+	assert.NoError(t, err)
+	bus.Drain()
+	p.Cancel()
+	assert.Equal(t, int32(1), h.on.Load(), "a plain publish from a handler should call On")
+	assert.Equal(t, int32(0), h.onContext.Load())
+}
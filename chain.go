@@ -0,0 +1,35 @@
+package bus
+
+// chainHandler calls each of its handlers that accepts the value in turn,
+// stopping at the first error.
+type chainHandler []Handler
+
+func (hs chainHandler) On(b *Bus, t, v interface{}) {
+	hs.OnErr(b, t, v)
+}
+
+func (hs chainHandler) OnErr(b *Bus, t, v interface{}) error {
+	for _, h := range hs {
+		if a, ok := h.(acceptor); ok && !a.accept(b, t, v) {
+			continue
+		}
+		if err := call(b, h, t, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Chain returns a Handler that calls each of the given handlers in order, so
+// that a pipeline of handlers can be subscribed and unsubscribed as a unit.
+// If one of the handlers is an ErrHandler and fails, the handlers after it
+// are not called, and the error is reported to the publisher as though
+// returned by the chain. Handlers that decline a value, such as those made
+// by NewRateLimitedHandler, are skipped for it without stopping the chain.
+// Chain panics if any of the handlers is nil.
+func Chain(handlers ...Handler) Handler {
+	for _, h := range handlers {
+		mustHandler(h)
+	}
+	return chainHandler(append([]Handler(nil), handlers...))
+}
@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	step := func(name string) Handler {
+		return HandlerFunc(func(b *Bus, tp, v interface{}) {
+			got = append(got, name)
+		})
+	}
+	bus.Subscribe("test", Chain(step("a"), step("b"), step("c")))
+
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "chain should count as a single handler")
+	assert.Equal(t, []string{"a", "b", "c"}, got)
+}
+
+func TestChainStopsOnError(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	first := &failingHandler{}
+	failing := &failingHandler{err: errFail}
+	last := &failingHandler{}
+	bus.Subscribe("test", Chain(first, failing, last))
+
+	_, err := bus.Publish("test", 1)
+	assert.True(t, errors.Is(err, errFail))
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 1, failing.calls)
+	assert.Equal(t, 0, last.calls, "handlers after a failure should not be called")
+}
+
+func TestChainAccept(t *testing.T) {
+	bus := NewBus()
+	limited := &mockHandler{}
+	last := &mockHandler{}
+	bus.Subscribe("test", Chain(NewRateLimitedHandler(time.Hour, limited), last))
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+	assert.Equal(t, 1, limited.v, "declined values should not be passed to the handler")
+	assert.Equal(t, 2, last.v, "handlers after one that declines should still be called")
+}
@@ -0,0 +1,240 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ChanHandler is a Handler that forwards each value it receives onto a
+// buffered channel, allowing subscribers to select over events rather than
+// handle them in a callback.
+//
+// Sends never block: if the channel buffer is full when a value arrives, the
+// value is dropped and counted, so a slow reader cannot stall a synchronous
+// Publish.
+type ChanHandler struct {
+	lock    sync.Mutex
+	c       chan interface{}
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// NewChanHandler creates a ChanHandler whose channel has the given buffer
+// size.
+func NewChanHandler(buffer int) *ChanHandler {
+	return &ChanHandler{
+		c: make(chan interface{}, buffer),
+	}
+}
+
+// C returns the channel onto which received values are sent.
+func (h *ChanHandler) C() <-chan interface{} {
+	return h.c
+}
+
+// On sends the value onto the channel, dropping it if the channel is full or
+// has been closed.
+func (h *ChanHandler) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	select {
+	case h.c <- v:
+	default:
+		h.dropped.Add(1)
+		if b != nil {
+			b.state(b.qualify(t)).stats.drop(1)
+		}
+	}
+}
+
+// TryOn sends the value onto the channel, returning false if the channel is
+// full or has been closed. Values that are not sent are counted as dropped.
+func (h *ChanHandler) TryOn(b *Bus, t, v interface{}) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return false
+	}
+
+	select {
+	case h.c <- v:
+		return true
+	default:
+		h.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns the number of values dropped because the channel was full.
+func (h *ChanHandler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Close closes the channel. Values received after Close are discarded. It is
+// safe to call Close more than once, and concurrently with On.
+func (h *ChanHandler) Close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.closed {
+		h.closed = true
+		close(h.c)
+	}
+}
+
+// SubscribeChan subscribes a ChanHandler with the given buffer size to the
+// named topic on this Bus, returning its channel and a function that
+// unsubscribes the handler and closes the channel. Values published while the
+// channel is full are dropped; use NewChanHandler and Subscribe directly to
+// monitor the number of dropped values.
+func (b *Bus) SubscribeChan(topic interface{}, buffer int) (<-chan interface{}, UnsubscribeFunc) {
+	h := NewChanHandler(buffer)
+	unsub := b.Subscribe(topic, h)
+	return h.C(), func() bool {
+		ok := unsub()
+		h.Close()
+		return ok
+	}
+}
+
+// ChanQueue is a channel fed with the values published to a topic from a
+// bounded queue, as subscribed by SubscribeChanQueued.
+type ChanQueue struct {
+	lock    sync.Mutex
+	c       chan interface{}
+	done    chan struct{}
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// C returns the channel onto which queued values are sent.
+func (q *ChanQueue) C() <-chan interface{} {
+	return q.c
+}
+
+// Dropped returns the number of values dropped because the queue was full,
+// or discarded from the queue when it was unsubscribed.
+func (q *ChanQueue) Dropped() uint64 {
+	return q.dropped.Load()
+}
+
+// send sends the value onto the channel, waiting for the reader until the
+// queue is closed.
+func (q *ChanQueue) send(b *Bus, t, v interface{}) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return
+	}
+	select {
+	case q.c <- v:
+	case <-q.done:
+	}
+}
+
+// close closes the channel, abandoning any value waiting to be sent.
+func (q *ChanQueue) close() {
+	close(q.done)
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.closed = true
+	close(q.c)
+}
+
+// SubscribeChanQueued subscribes a channel with the given buffer size to the
+// named topic on this Bus like SubscribeChan, but rather than being sent onto
+// the channel during Publish, values are queued and sent from a goroutine
+// dedicated to the subscription, which waits for the reader whenever the
+// channel is full. A slow reader therefore holds up only its own channel,
+// and values are only dropped once queueSize values are waiting to be sent,
+// as counted by the ChanQueue and in the Stats of the topic.
+//
+// As with SubscribeBuffered, Drain and Close wait for queued values to be
+// sent onto the channel. The returned function unsubscribes the channel,
+// discards any values still queued and closes the channel.
+func (b *Bus) SubscribeChanQueued(topic interface{}, buffer, queueSize int) (*ChanQueue, UnsubscribeFunc) {
+	q := &ChanQueue{
+		c:    make(chan interface{}, buffer),
+		done: make(chan struct{}),
+	}
+	unsub := b.subscribeBuffered(topic, queueSize, DropNewest, HandlerFunc(q.send), &q.dropped)
+	var once sync.Once
+	return q, func() bool {
+		ok := unsub()
+		once.Do(q.close)
+		return ok
+	}
+}
+
+// typedChanHandler forwards each value of type T it receives onto a buffered
+// channel of that type.
+type typedChanHandler[T any] struct {
+	lock   sync.Mutex
+	c      chan T
+	closed bool
+}
+
+func (h *typedChanHandler[T]) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return
+	}
+	tv, ok := As[T](v)
+	if ok {
+		select {
+		case h.c <- tv:
+			return
+		default:
+		}
+	}
+	b.state(b.qualify(t)).stats.drop(1)
+}
+
+func (h *typedChanHandler[T]) close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.closed {
+		h.closed = true
+		close(h.c)
+	}
+}
+
+// SubscribeTypedChan subscribes a channel of values of type T with the given
+// buffer size to the named topic on the Bus, as SubscribeChan does, returning
+// the channel and a function that unsubscribes and closes it. Values that
+// are not of type T are dropped, as are values published while the channel
+// is full, and both are counted in the Stats of the topic.
+func SubscribeTypedChan[T any](b *Bus, topic interface{}, buffer int) (<-chan T, UnsubscribeFunc) {
+	h := &typedChanHandler[T]{c: make(chan T, buffer)}
+	unsub := b.Subscribe(topic, h)
+	return h.c, func() bool {
+		ok := unsub()
+		h.close()
+		return ok
+	}
+}
+
+// SubscribeChan subscribes a channel with the given buffer size to the named
+// topic on the default Bus, returning the channel and a function that
+// unsubscribes and closes it.
+func SubscribeChan(topic interface{}, buffer int) (<-chan interface{}, UnsubscribeFunc) {
+	return getDefaultBus().SubscribeChan(topic, buffer)
+}
+
+// SubscribeChanQueued subscribes a channel with the given buffer size to the
+// named topic on the default Bus, fed from a queue of the given size by a
+// goroutine dedicated to the subscription.
+func SubscribeChanQueued(topic interface{}, buffer, queueSize int) (*ChanQueue, UnsubscribeFunc) {
+	return getDefaultBus().SubscribeChanQueued(topic, buffer, queueSize)
+}
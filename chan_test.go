@@ -0,0 +1,138 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeChan(t *testing.T) {
+	bus := NewBus()
+	c, unsub := bus.SubscribeChan("test", 1)
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "hello", <-c)
+
+	assert.True(t, unsub(), "channel handler should unsubscribe")
+	_, ok := <-c
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+	assert.False(t, unsub(), "second unsubscribe should fail")
+}
+
+func TestChanHandlerDrop(t *testing.T) {
+	bus := NewBus()
+	h := NewChanHandler(1)
+	defer bus.Subscribe("test", h)()
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+	bus.Publish("test", 3)
+	assert.Equal(t, uint64(2), h.Dropped(), "full channel should drop values")
+	assert.Equal(t, 1, <-h.C(), "first value should be kept")
+}
+
+func TestChanHandlerDropNamespace(t *testing.T) {
+	bus := NewBus()
+	h := NewChanHandler(1)
+	bus.Namespace("ns").Subscribe("test", h)
+
+	bus.Publish("ns.test", 1)
+	bus.Publish("ns.test", 2)
+	assert.Equal(t, uint64(1), bus.Stats()["ns.test"].DroppedCount)
+	assert.NotContains(t, bus.Stats(), "test", "drops should be counted under the qualified topic")
+}
+
+// TestChanHandlerCloseRace checks that closing a channel handler is safe
+// while values are being sent to it.
+func TestChanHandlerCloseRace(t *testing.T) {
+	bus := NewBus()
+	c, unsub := bus.SubscribeChan("test", 0)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bus.Publish("test", j)
+			}
+		}()
+	}
+	unsub()
+	wg.Wait()
+
+	_, ok := <-c
+	assert.False(t, ok)
+}
+
+func TestSubscribeChanQueued(t *testing.T) {
+	bus := NewBus()
+	q, unsub := bus.SubscribeChanQueued("test", 0, 2)
+	count := 0
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) { count++ })
+
+	// Nobody is reading, so all but the values taken by the queue's
+	// goroutine and those queued are dropped, without holding up publish
+	for i := 0; i < 10; i++ {
+		n, err := bus.Publish("test", i)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	}
+	assert.Equal(t, 10, count, "other handlers should not wait for the reader")
+
+	var got []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for v := range q.C() {
+			got = append(got, v.(int))
+		}
+	}()
+	bus.Drain()
+	assert.True(t, unsub())
+	<-done
+
+	assert.GreaterOrEqual(t, q.Dropped(), uint64(7))
+	assert.Equal(t, 10, len(got)+int(q.Dropped()))
+	assert.Equal(t, q.Dropped(), bus.Stats()["test"].DroppedCount)
+	assert.IsIncreasing(t, got)
+	assert.False(t, unsub())
+}
+
+// TestSubscribeChanQueuedUnsubscribeBlocked checks that unsubscribing closes
+// the channel while a value is waiting to be sent onto it.
+func TestSubscribeChanQueuedUnsubscribeBlocked(t *testing.T) {
+	bus := NewBus()
+	q, unsub := bus.SubscribeChanQueued("test", 0, 4)
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+
+	assert.True(t, unsub())
+	for range q.C() {
+	}
+	bus.Drain()
+}
+
+func TestSubscribeTypedChan(t *testing.T) {
+	bus := NewBus()
+	c, unsub := SubscribeTypedChan[kill](bus, "kills", 1)
+
+	bus.Publish("kills", "not a kill")
+	bus.Publish("kills", kill{Victim: "Breen"})
+	bus.Publish("kills", kill{Victim: "Vortigaunt"})
+	assert.Equal(t, kill{Victim: "Breen"}, <-c)
+	assert.Equal(t, uint64(2), bus.Stats()["kills"].DroppedCount, "mismatched and overflowing values should be dropped")
+
+	assert.True(t, unsub())
+	_, ok := <-c
+	assert.False(t, ok, "channel should be closed after unsubscribe")
+	assert.False(t, unsub())
+	bus.Publish("kills", kill{})
+
+	SubscribeTypedChan[kill](bus.Namespace("ns"), "kills", 1)
+	bus.Publish("ns.kills", "not a kill")
+	assert.Equal(t, uint64(1), bus.Stats()["ns.kills"].DroppedCount, "drops should be counted under the qualified topic")
+}
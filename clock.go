@@ -0,0 +1,160 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by a Bus for its timers, timeouts and
+// timestamps, so that time-based behaviour can be tested without waiting.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc calls f in its own goroutine once d has elapsed, returning
+	// a Timer that can be used to cancel the call.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call made by a Clock's AfterFunc.
+type Timer interface {
+	// Stop prevents the call from being made, returning false if it has
+	// already been made or stopped.
+	Stop() bool
+}
+
+// realClock is the Clock used by default, which tells the time using the
+// time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// WithClock causes the Bus to use the given Clock in place of the system
+// clock for everything it times: scheduled publishes, batching, coalescing,
+// rate limiting, retry backoff, timeouts, topic TTLs, handler timings and
+// publish timestamps. It is intended for tests, with a FakeClock.
+func WithClock(c Clock) BusOption {
+	return func(b *Bus) {
+		b.clock = c
+	}
+}
+
+// after returns a channel that is closed once d has elapsed on the Bus's
+// clock, and a function that stops the timer.
+func (b *Bus) after(d time.Duration) (<-chan struct{}, func() bool) {
+	c := make(chan struct{})
+	t := b.clock.AfterFunc(d, func() { close(c) })
+	return c, t.Stop
+}
+
+// sleep blocks until d has elapsed on the Bus's clock.
+func (b *Bus) sleep(d time.Duration) {
+	c, _ := b.after(d)
+	<-c
+}
+
+// FakeClock is a Clock whose time only moves when it is advanced, for
+// testing time-based behaviour deterministically. It is safe for concurrent
+// use.
+type FakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer is a call waiting for a FakeClock to reach its time.
+type fakeTimer struct {
+	c    *FakeClock
+	at   time.Time
+	f    func()
+	done bool
+}
+
+// NewFakeClock returns a FakeClock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the clock's current time.
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// AfterFunc arranges for f to be called once the clock has been advanced by
+// d. A call that is due is only made by the next call to Advance, even if d
+// is not positive, so f is never called by AfterFunc itself.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := &fakeTimer{c: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d, calling the function of each timer
+// that falls due in the order of their times, with the clock set to each
+// timer's time while its function is called. Functions are called in the
+// goroutine calling Advance, and timers they start are also called if they
+// fall due before the end of the advance.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	end := c.now.Add(d)
+	for {
+		next := -1
+		for i, t := range c.timers {
+			if !t.at.After(end) && (next < 0 || t.at.Before(c.timers[next].at)) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+		t := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		t.done = true
+		if t.at.After(c.now) {
+			c.now = t.at
+		}
+		c.lock.Unlock()
+		t.f()
+		c.lock.Lock()
+	}
+	if end.After(c.now) {
+		c.now = end
+	}
+	c.lock.Unlock()
+}
+
+// Pending returns the number of timers waiting for the clock to reach their
+// time.
+func (c *FakeClock) Pending() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.timers)
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.lock.Lock()
+	defer t.c.lock.Unlock()
+
+	if t.done {
+		return false
+	}
+	t.done = true
+	for i, t2 := range t.c.timers {
+		if t2 == t {
+			t.c.timers = append(t.c.timers[:i], t.c.timers[i+1:]...)
+			break
+		}
+	}
+	return true
+}
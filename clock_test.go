@@ -0,0 +1,88 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	var got []int
+	c.AfterFunc(2*time.Second, func() {
+		got = append(got, 2)
+		assert.Equal(t, start.Add(2*time.Second), c.Now(), "clock should be at the timer's time")
+	})
+	c.AfterFunc(time.Second, func() {
+		got = append(got, 1)
+		// Timers started by timers fire within the same advance
+		c.AfterFunc(time.Second/2, func() { got = append(got, 15) })
+	})
+	stopped := c.AfterFunc(time.Second, func() { got = append(got, -1) })
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+	assert.Equal(t, 2, c.Pending())
+
+	c.Advance(500 * time.Millisecond)
+	assert.Empty(t, got)
+	c.Advance(3 * time.Second)
+	assert.Equal(t, []int{1, 15, 2}, got)
+	assert.Equal(t, start.Add(3500*time.Millisecond), c.Now())
+	assert.Zero(t, c.Pending())
+}
+
+func TestClockPublishAfter(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	bus := NewBus(WithClock(c))
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+
+	bus.PublishAfter(time.Minute, "test", 1)
+	cancel := bus.PublishAfter(2*time.Minute, "test", 2)
+	c.Advance(59 * time.Second)
+	assert.Nil(t, h.v)
+	c.Advance(time.Second)
+	assert.Equal(t, 1, h.v)
+	cancel()
+	c.Advance(time.Hour)
+	assert.Equal(t, 1, h.v, "cancelled publish should not be made")
+	assert.Equal(t, 0, bus.Drain())
+}
+
+func TestClockCoalesceAndRateLimit(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	bus := NewBus(WithClock(c))
+	var got []interface{}
+	bus.SubscribeCoalesced("coalesced", time.Second, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	rl := NewRateLimitedHandler(time.Second, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	bus.Subscribe("limited", rl)
+
+	bus.Publish("coalesced", 1)
+	bus.Publish("coalesced", 2)
+	bus.Publish("limited", "a")
+	bus.Publish("limited", "b")
+	c.Advance(time.Second)
+	bus.Publish("limited", "c")
+	assert.Equal(t, []interface{}{"a", 2, "c"}, got)
+	assert.Equal(t, uint64(1), rl.Dropped())
+}
+
+func TestClockTimeout(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	bus := NewBus(WithClock(c))
+
+	errs := make(chan error)
+	go func() {
+		_, err := bus.WaitFor("test", time.Hour)
+		errs <- err
+	}()
+	assert.Eventually(t, func() bool { return c.Pending() == 1 }, time.Second, time.Millisecond)
+	c.Advance(time.Hour)
+	assert.Equal(t, ErrTimeout, <-errs)
+}
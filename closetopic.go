@@ -0,0 +1,85 @@
+package bus
+
+// CloseTopic closes the given topic on this Bus, causing subsequent publishes
+// to it to fail with ErrTopicClosed. It then waits for the values already
+// queued on the topic with OrderedAsync, and those buffered by handlers
+// subscribed with SubscribeBuffered, to be delivered, before unsubscribing
+// all of the topic's handlers and discarding its settings, history and
+// statistics. It is the per-topic equivalent of Close.
+//
+// Publishes to aliases of the topic fail in the same way.
+//
+// Handlers called with the Async flag or by publishes already in progress
+// are not waited for; use Drain to wait for those. Handlers subscribed to
+// the topic after it is closed are never called by a publish to it. It
+// returns ErrTopicClosed if the topic has already been closed, and
+// ErrBusClosed if the Bus has been.
+func (b *Bus) CloseTopic(topic interface{}) error {
+	if !b.validTopic(topic) {
+		return ErrInvalidTopic
+	}
+	topic = b.qualify(topic)
+
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		return ErrBusClosed
+	}
+	if _, ok := b.closedTopics[topic]; ok {
+		b.lock.Unlock()
+		return ErrTopicClosed
+	}
+	if b.closedTopics == nil {
+		b.closedTopics = make(map[interface{}]struct{})
+	}
+	b.closedTopics[topic] = struct{}{}
+
+	var q *orderedQueue
+	if st := b.states[topic]; st != nil {
+		q = st.ordered
+	}
+	var buffered []*bufferedHandler
+	for _, s := range b.topics[topic] {
+		if bh := bufferedOf(s.handler); bh != nil {
+			buffered = append(buffered, bh)
+		}
+	}
+	b.lock.Unlock()
+
+	// Deliver what has already been queued without holding the lock, as
+	// the handlers may use the Bus
+	if q != nil {
+		q.close()
+	}
+	for _, bh := range buffered {
+		bh.drain()
+		bh.close()
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+	b.removeTopicLocked(topic)
+	delete(b.states, topic)
+	return nil
+}
+
+// bufferedOf returns the buffered handler that h is or wraps, if any.
+func bufferedOf(h Handler) *bufferedHandler {
+	for h != nil {
+		if bh, ok := h.(*bufferedHandler); ok {
+			return bh
+		}
+		w, ok := h.(wrapper)
+		if !ok {
+			return nil
+		}
+		h = w.unwrap()
+	}
+	return nil
+}
+
+// CloseTopic closes the given topic on the default Bus, waiting for the
+// values queued on it to be delivered before removing it.
+func CloseTopic(topic interface{}) error {
+	return getDefaultBus().CloseTopic(topic)
+}
@@ -0,0 +1,81 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloseTopic(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+	bus.Subscribe("other", h)
+
+	assert.NoError(t, bus.CloseTopic("test"))
+	assert.Equal(t, 0, bus.SubscriberCount("test"))
+
+	n, err := bus.Publish("test", 1)
+	assert.Equal(t, ErrTopicClosed, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, ErrTopicClosed, bus.CloseTopic("test"))
+
+	n, err = bus.Publish("other", 1)
+	assert.NoError(t, err, "other topics should be unaffected")
+	assert.Equal(t, 1, n)
+
+	bus.Close()
+	assert.Equal(t, ErrBusClosed, bus.CloseTopic("other"))
+}
+
+func TestCloseTopicWaitsForQueued(t *testing.T) {
+	bus := NewBus()
+	ordered := newBlockingRecorder()
+	buffered := newBlockingRecorder()
+	bus.Subscribe("ordered", ordered)
+	bus.SubscribeBuffered("buffered", 4, buffered)
+
+	for i := 1; i <= 3; i++ {
+		bus.Publish("ordered", i, OrderedAsync)
+		bus.Publish("buffered", i)
+	}
+	<-ordered.entered
+	<-buffered.entered
+
+	done := make(chan struct{})
+	go func() {
+		bus.CloseTopic("ordered")
+		bus.CloseTopic("buffered")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("CloseTopic should wait for queued values")
+	default:
+	}
+
+	close(ordered.release)
+	close(buffered.release)
+	<-done
+	assert.Equal(t, []interface{}{1, 2, 3}, ordered.values())
+	assert.Equal(t, []interface{}{1, 2, 3}, buffered.values())
+
+	_, err := bus.Publish("ordered", 4, OrderedAsync)
+	assert.Equal(t, ErrTopicClosed, err)
+	bus.Drain()
+	assert.Len(t, ordered.values(), 3)
+}
+
+func TestCloseTopicAlias(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+	assert.NoError(t, bus.Alias("alias", "test"))
+
+	assert.NoError(t, bus.CloseTopic("test"))
+	bus.Subscribe("test", h)
+	n, err := bus.Publish("alias", 1)
+	assert.Equal(t, ErrTopicClosed, err, "aliases of a closed topic should be closed too")
+	assert.Equal(t, 0, n)
+	assert.Nil(t, h.v)
+}
@@ -0,0 +1,113 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// coalesceHandler passes on only the latest value it receives in each
+// window of time.
+type coalesceHandler struct {
+	lock    sync.Mutex
+	window  time.Duration
+	timer   Timer
+	epoch   uint64
+	pending bufferedValue
+	closed  bool
+	stats   *topicStats
+	async   *tracker
+	h       Handler
+}
+
+func (h *coalesceHandler) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return
+	}
+	if h.timer != nil {
+		// Replace the value waiting for the window to end
+		h.stats.drop(1)
+	} else {
+		h.epoch = h.async.add()
+		h.timer = b.clock.AfterFunc(h.window, h.fire)
+	}
+	h.pending = bufferedValue{bus: b, topic: t, value: v}
+}
+
+func (h *coalesceHandler) unwrap() Handler {
+	return h.h
+}
+
+// fire delivers the latest value once the window has ended.
+func (h *coalesceHandler) fire() {
+	h.lock.Lock()
+	if h.closed {
+		h.lock.Unlock()
+		return
+	}
+	bv, epoch := h.pending, h.epoch
+	h.pending = bufferedValue{}
+	h.timer = nil
+	h.lock.Unlock()
+
+	defer h.async.done(epoch)
+	bv.bus.reportAsync(bv.bus.Deliver(h.h, bv.topic, bv.value))
+}
+
+// close stops the handler, discarding any value waiting for its window to
+// end.
+func (h *coalesceHandler) close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+		h.pending = bufferedValue{}
+		h.stats.drop(1)
+		h.async.done(h.epoch)
+	}
+}
+
+// SubscribeCoalesced causes the passed Handler to be called with only the
+// latest of the values published to the named topic on this Bus in each
+// window of time. When a value is published, a window begins, and at its end
+// the handler is called with the last value published during it; the values
+// before it are dropped, and counted in the Stats of the topic. A window with
+// only a single value still delivers it. The handler is called from a
+// goroutine of its own.
+//
+// Values waiting for their window to end are waited for by Drain and Close.
+// Unsubscribing discards any value still waiting.
+func (b *Bus) SubscribeCoalesced(topic interface{}, window time.Duration, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	if !b.validTopic(topic) {
+		panic(ErrInvalidTopic.Error())
+	}
+	ch := &coalesceHandler{
+		window: window,
+		stats:  &b.state(b.qualify(topic)).stats,
+		async:  &b.async,
+		h:      h,
+	}
+
+	unsub := b.Subscribe(topic, ch)
+	return func() bool {
+		ok := unsub()
+		ch.close()
+		return ok
+	}
+}
+
+// SubscribeCoalesced causes the passed Handler to be called with only the
+// latest of the values published to the named topic on the default Bus in
+// each window of time.
+func SubscribeCoalesced(topic interface{}, window time.Duration, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeCoalesced(topic, window, h)
+}
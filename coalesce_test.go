@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeCoalesced(t *testing.T) {
+	bus := NewBus()
+	var lock sync.Mutex
+	var got []interface{}
+	bus.SubscribeCoalesced("test", 20*time.Millisecond, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		lock.Lock()
+		got = append(got, v)
+		lock.Unlock()
+	}))
+
+	for i := 1; i <= 5; i++ {
+		bus.Publish("test", i)
+	}
+	assert.Equal(t, 1, bus.Drain(), "drain should wait for the window to end")
+	bus.Publish("test", 6)
+	bus.Drain()
+
+	lock.Lock()
+	assert.Equal(t, []interface{}{5, 6}, got, "a lone value should still be delivered")
+	lock.Unlock()
+	assert.Equal(t, uint64(4), bus.Stats()["test"].DroppedCount)
+}
+
+func TestSubscribeCoalescedUnsubscribe(t *testing.T) {
+	bus := NewBus()
+	unsub := bus.SubscribeCoalesced("test", 10*time.Millisecond, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		t.Error("pending value should be discarded")
+	}))
+
+	bus.Publish("test", 1)
+	assert.True(t, unsub())
+	assert.Equal(t, 0, bus.Drain())
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestSubscribeCoalescedInvalidTopic(t *testing.T) {
+	bus := NewBus()
+	assert.PanicsWithValue(t, "bus: invalid topic", func() {
+		bus.SubscribeCoalesced([]int{}, time.Millisecond, &mockHandler{})
+	})
+	bus.Subscribe("test", &mockHandler{})
+	assert.NoError(t, bus.Close(), "the lock should not be held after the panic")
+}
@@ -0,0 +1,93 @@
+package bus
+
+// Completer is implemented by handlers that want to be told when a topic
+// they are subscribed to has been completed with CompleteTopic, for example
+// so that they can close a channel.
+type Completer interface {
+	// OnComplete is called once the topic t will receive no more values.
+	OnComplete(b *Bus, t interface{})
+}
+
+// complete tells the handler, or the first handler it wraps that is a
+// Completer, that the topic has been completed.
+func complete(b *Bus, h Handler, t interface{}) {
+	for h != nil {
+		switch w := h.(type) {
+		case Completer:
+			w.OnComplete(b, t)
+			return
+		case *nsHandler:
+			b, t, h = w.b, w.b.unqualify(t), w.h
+		case wrapper:
+			h = w.unwrap()
+		default:
+			return
+		}
+	}
+}
+
+// CompleteTopic tells each handler subscribed to the named topic on this Bus
+// that implements Completer, or wraps one, that the topic is complete, then
+// removes the topic as RemoveTopic does, returning the number of handlers
+// removed. Handlers that do not implement Completer are simply unsubscribed.
+// Completing a topic without handlers, or an invalid topic, does nothing.
+func (b *Bus) CompleteTopic(topic interface{}) int {
+	if !b.validTopic(topic) {
+		return 0
+	}
+	qt := b.qualify(topic)
+
+	ss := b.subscriptions(qt)
+	if len(ss) == 0 {
+		return 0
+	}
+
+	root := &Bus{core: b.core}
+	for _, s := range ss {
+		complete(root, s.handler, qt)
+	}
+	return b.RemoveTopic(topic)
+}
+
+// subscriptions returns a copy of the subscriptions of the given topic.
+func (b *Bus) subscriptions(topic interface{}) []*subscription {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return append([]*subscription(nil), b.topics[topic]...)
+}
+
+// CompleteTopic tells each handler subscribed to the named topic on the
+// default Bus that implements Completer that the topic is complete, then
+// removes the topic.
+func CompleteTopic(topic interface{}) int {
+	return getDefaultBus().CompleteTopic(topic)
+}
+
+// OnComplete closes the channel.
+func (h *ChanHandler) OnComplete(b *Bus, t interface{}) {
+	h.Close()
+}
+
+func (h *fanOutHandler) OnComplete(b *Bus, t interface{}) {
+	h.close()
+}
+
+// OnComplete completes the handler once the values already buffered have
+// been passed to it.
+func (h *bufferedHandler) OnComplete(b *Bus, t interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed || h.completed != nil {
+		return
+	}
+	h.completed = &bufferedValue{bus: b, topic: t}
+	h.cond.Signal()
+}
+
+func (hs chainHandler) OnComplete(b *Bus, t interface{}) {
+	for _, h := range hs {
+		complete(b, h, t)
+	}
+}
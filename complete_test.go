@@ -0,0 +1,67 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// completingHandler records the topics it is told are complete.
+type completingHandler struct {
+	mockHandler
+	completed []interface{}
+}
+
+func (h *completingHandler) OnComplete(b *Bus, t interface{}) {
+	h.completed = append(h.completed, t)
+}
+
+func TestCompleteTopic(t *testing.T) {
+	bus := NewBus()
+	c, _ := bus.SubscribeChan("test", 1)
+	h := &completingHandler{}
+	bus.Namespace("ns").Subscribe("test", h)
+	bus.Subscribe("ns.test", &mockHandler{})
+	bus.SubscribeFilter("ns.test", func(v interface{}) bool { return true }, h)
+
+	bus.Publish("test", 1)
+	assert.Equal(t, 1, bus.CompleteTopic("test"))
+	assert.Equal(t, 1, <-c)
+	_, ok := <-c
+	assert.False(t, ok, "channel should be closed")
+	assert.False(t, bus.Has("test"))
+
+	assert.Equal(t, 3, bus.CompleteTopic("ns.test"))
+	assert.Equal(t, []interface{}{"test", "ns.test"}, h.completed, "wrapped completers should be told")
+	assert.False(t, bus.Has("ns.test"))
+
+	assert.Equal(t, 0, bus.CompleteTopic("unknown"))
+}
+
+func TestCompleteTopicBuffered(t *testing.T) {
+	bus := NewBus()
+	h := newBlockingRecorder()
+	ch := NewChanHandler(4)
+	bus.SubscribeBuffered("test", 4, Chain(h, ch))
+
+	bus.Publish("test", 1)
+	<-h.entered
+	bus.Publish("test", 2)
+	bus.CompleteTopic("test")
+	bus.Publish("test", 3)
+	close(h.release)
+
+	var got []interface{}
+	for v := range ch.C() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []interface{}{1, 2}, got, "buffered values should be delivered before completing")
+	assert.Equal(t, []interface{}{1, 2}, h.values())
+}
+
+func TestCompleteTopicInvalid(t *testing.T) {
+	bus := NewBus()
+	assert.Equal(t, 0, bus.CompleteTopic([]int{}))
+	bus.Subscribe("test", &mockHandler{})
+	assert.NoError(t, bus.Close())
+}
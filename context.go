@@ -0,0 +1,117 @@
+package bus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SubscribeCtx causes the passed Handler to be called when data is published
+// to the named topic on this Bus, until the given context is done, at which
+// point the handler is unsubscribed automatically. The returned function may
+// be called to unsubscribe the handler early.
+func (b *Bus) SubscribeCtx(ctx context.Context, topic interface{}, h Handler) UnsubscribeFunc {
+	unsub := b.Subscribe(topic, h)
+	return watchCtx(ctx, unsub)
+}
+
+// SubscribeChanCtx is like SubscribeChan, but the channel is also
+// unsubscribed and closed once the given context is done, so that a reader
+// ranging over it stops.
+func (b *Bus) SubscribeChanCtx(ctx context.Context, topic interface{}, buffer int) (<-chan interface{}, UnsubscribeFunc) {
+	c, unsub := b.SubscribeChan(topic, buffer)
+	return c, watchCtx(ctx, unsub)
+}
+
+// SubscribeChanQueuedCtx is like SubscribeChanQueued, but the channel is
+// also unsubscribed, its goroutine stopped and the channel closed once the
+// given context is done.
+func (b *Bus) SubscribeChanQueuedCtx(ctx context.Context, topic interface{}, buffer, queueSize int) (*ChanQueue, UnsubscribeFunc) {
+	q, unsub := b.SubscribeChanQueued(topic, buffer, queueSize)
+	return q, watchCtx(ctx, unsub)
+}
+
+// SubscribeBufferedCtx is like SubscribeBuffered, but the handler is also
+// unsubscribed, and its goroutine stopped, once the given context is done.
+func (b *Bus) SubscribeBufferedCtx(ctx context.Context, topic interface{}, bufSize int, h Handler) UnsubscribeFunc {
+	return watchCtx(ctx, b.SubscribeBuffered(topic, bufSize, h))
+}
+
+// SubscribeLatestCtx is like SubscribeLatest, but the handler is also
+// unsubscribed, and its goroutine stopped, once the given context is done.
+func (b *Bus) SubscribeLatestCtx(ctx context.Context, topic interface{}, h Handler) UnsubscribeFunc {
+	return watchCtx(ctx, b.SubscribeLatest(topic, h))
+}
+
+// SubscribeCoalescedCtx is like SubscribeCoalesced, but the handler is also
+// unsubscribed, discarding any value waiting for its window to end, once the
+// given context is done.
+func (b *Bus) SubscribeCoalescedCtx(ctx context.Context, topic interface{}, window time.Duration, h Handler) UnsubscribeFunc {
+	return watchCtx(ctx, b.SubscribeCoalesced(topic, window, h))
+}
+
+// watchCtx calls unsub once the context is done, returning a function that
+// calls unsub immediately and stops watching the context. Either way, the
+// goroutine watching the context exits. If the subscription is instead
+// removed by other means, such as UnsubscribeID, RemoveTopic, Reset or
+// Close, its worker goroutine is stopped, but the goroutine watching the
+// context remains until the context is done or the returned function is
+// called.
+func watchCtx(ctx context.Context, unsub UnsubscribeFunc) UnsubscribeFunc {
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			unsub()
+		case <-stop:
+		}
+	}()
+
+	once := sync.Once{}
+	return func() bool {
+		once.Do(func() {
+			close(stop)
+		})
+		return unsub()
+	}
+}
+
+// SubscribeCtx causes the passed Handler to be called when data is published
+// to the named topic on the default Bus, until the given context is done.
+func SubscribeCtx(ctx context.Context, topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeCtx(ctx, topic, h)
+}
+
+// SubscribeChanCtx subscribes a channel with the given buffer size to the
+// named topic on the default Bus until the given context is done.
+func SubscribeChanCtx(ctx context.Context, topic interface{}, buffer int) (<-chan interface{}, UnsubscribeFunc) {
+	return getDefaultBus().SubscribeChanCtx(ctx, topic, buffer)
+}
+
+// SubscribeChanQueuedCtx subscribes a channel with the given buffer size,
+// fed from a queue of queueSize values, to the named topic on the default Bus
+// until the given context is done.
+func SubscribeChanQueuedCtx(ctx context.Context, topic interface{}, buffer, queueSize int) (*ChanQueue, UnsubscribeFunc) {
+	return getDefaultBus().SubscribeChanQueuedCtx(ctx, topic, buffer, queueSize)
+}
+
+// SubscribeBufferedCtx causes the passed Handler to be called from a
+// goroutine dedicated to the subscription with each value published to the
+// named topic on the default Bus, until the given context is done.
+func SubscribeBufferedCtx(ctx context.Context, topic interface{}, bufSize int, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeBufferedCtx(ctx, topic, bufSize, h)
+}
+
+// SubscribeLatestCtx causes the passed Handler to be called with the latest
+// value published to the named topic on the default Bus, until the given
+// context is done.
+func SubscribeLatestCtx(ctx context.Context, topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeLatestCtx(ctx, topic, h)
+}
+
+// SubscribeCoalescedCtx causes the passed Handler to be called with only the
+// latest of the values published to the named topic on the default Bus in
+// each window of time, until the given context is done.
+func SubscribeCoalescedCtx(ctx context.Context, topic interface{}, window time.Duration, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeCoalescedCtx(ctx, topic, window, h)
+}
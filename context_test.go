@@ -0,0 +1,127 @@
+package bus
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeCtx(t *testing.T) {
+	bus := NewBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	unsub := bus.SubscribeCtx(ctx, "test", &mockHandler{})
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		return bus.SubscriberCount("test") == 0
+	}, time.Second, time.Millisecond, "handler should be removed on cancel")
+	assert.False(t, unsub(), "handler should already be unsubscribed")
+}
+
+func TestSubscribeCtxManual(t *testing.T) {
+	bus := NewBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	before := runtime.NumGoroutine()
+	unsub := bus.SubscribeCtx(ctx, "test", &mockHandler{})
+	assert.True(t, unsub(), "manual unsubscribe should succeed")
+	assert.False(t, unsub(), "manual unsubscribe should be idempotent")
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, runtime.NumGoroutine() <= before, "watcher goroutine should stop")
+
+	n, _ := bus.Publish("test", "hello")
+	assert.Equal(t, 0, n)
+}
+
+// waitGoroutines waits up to a second for the number of goroutines to drop
+// to n.
+func waitGoroutines(n int) {
+	for i := 0; i < 1000 && runtime.NumGoroutine() > n; i++ {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubscribeWorkersCtx(t *testing.T) {
+	h := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	for _, tc := range []struct {
+		name      string
+		subscribe func(ctx context.Context, b *Bus) UnsubscribeFunc
+	}{{
+		name: "chan",
+		subscribe: func(ctx context.Context, b *Bus) UnsubscribeFunc {
+			c, unsub := b.SubscribeChanCtx(ctx, "test", 1)
+			go func() {
+				for range c {
+				}
+			}()
+			return unsub
+		},
+	}, {
+		name: "chan queued",
+		subscribe: func(ctx context.Context, b *Bus) UnsubscribeFunc {
+			_, unsub := b.SubscribeChanQueuedCtx(ctx, "test", 0, 4)
+			return unsub
+		},
+	}, {
+		name: "buffered",
+		subscribe: func(ctx context.Context, b *Bus) UnsubscribeFunc {
+			return b.SubscribeBufferedCtx(ctx, "test", 4, h)
+		},
+	}, {
+		name: "latest",
+		subscribe: func(ctx context.Context, b *Bus) UnsubscribeFunc {
+			return b.SubscribeLatestCtx(ctx, "test", h)
+		},
+	}, {
+		name: "coalesced",
+		subscribe: func(ctx context.Context, b *Bus) UnsubscribeFunc {
+			return b.SubscribeCoalescedCtx(ctx, "test", time.Hour, h)
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			bus := NewBus()
+			before := runtime.NumGoroutine()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			unsub := tc.subscribe(ctx, bus)
+			bus.Publish("test", 1)
+			bus.Publish("test", 2)
+			assert.Equal(t, 1, bus.SubscriberCount("test"))
+
+			cancel()
+			waitGoroutines(before)
+			assert.True(t, runtime.NumGoroutine() <= before, "cancelling should stop every goroutine")
+			assert.Equal(t, 0, bus.SubscriberCount("test"), "cancelling should unsubscribe")
+			assert.False(t, unsub(), "handler should already be unsubscribed")
+			assert.Equal(t, 0, bus.Drain(), "nothing should be left to drain")
+		})
+	}
+}
+
+func TestSubscribeWorkersCtxManual(t *testing.T) {
+	bus := NewBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	before := runtime.NumGoroutine()
+	c, unsubChan := bus.SubscribeChanCtx(ctx, "test", 1)
+	unsubBuffered := bus.SubscribeBufferedCtx(ctx, "test", 1, &mockHandler{})
+	assert.True(t, unsubChan())
+	assert.True(t, unsubBuffered())
+	_, ok := <-c
+	assert.False(t, ok, "channel should be closed on unsubscribe")
+
+	waitGoroutines(before)
+	assert.True(t, runtime.NumGoroutine() <= before, "unsubscribing should stop every goroutine")
+}
@@ -0,0 +1,84 @@
+package bus
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WithDeclaredTopics causes the Bus to reject topics that have not been
+// declared, either by passing them to this option or with DeclareTopic, so
+// that a mistyped topic is reported instead of silently having no effect.
+// Publishing to an undeclared topic fails with an error wrapping
+// ErrUnknownTopic, SubscribeSafe and SubscribeID fail with it, and Subscribe
+// panics with it. The topics Request uses for replies need not be declared.
+func WithDeclaredTopics(topics ...interface{}) BusOption {
+	return func(b *Bus) {
+		b.strict = true
+		for _, t := range topics {
+			b.declareLocked(t)
+		}
+	}
+}
+
+// DeclareTopic declares the named topic on this Bus, allowing it to be
+// published and subscribed to if the Bus was created with
+// WithDeclaredTopics. Declaring a topic on a Bus without that option has no
+// effect beyond being reported by DeclaredTopics.
+func (b *Bus) DeclareTopic(topic interface{}) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.declareLocked(topic)
+}
+
+// declareLocked declares the topic. It must be called with the write lock
+// held.
+func (b *Bus) declareLocked(topic interface{}) {
+	if b.declared == nil {
+		b.declared = make(map[interface{}]struct{})
+	}
+	b.declared[b.qualify(topic)] = struct{}{}
+}
+
+// DeclaredTopics returns the topics declared on this Bus, ordered by their
+// string representations.
+func (b *Bus) DeclaredTopics() []interface{} {
+	b.lock.RLock()
+	ts := make([]interface{}, 0, len(b.declared))
+	for t := range b.declared {
+		ts = append(ts, t)
+	}
+	b.lock.RUnlock()
+
+	sort.Slice(ts, func(i, j int) bool {
+		return fmt.Sprint(ts[i]) < fmt.Sprint(ts[j])
+	})
+	return ts
+}
+
+// checkDeclaredLocked returns an error wrapping ErrUnknownTopic if the Bus
+// only accepts declared topics and the qualified topic has not been declared.
+// It must be called with the lock held.
+func (b *Bus) checkDeclaredLocked(topic interface{}) error {
+	if !b.strict {
+		return nil
+	}
+	if _, ok := topic.(replyTopic); ok {
+		// Reply topics are private to Request, so cannot be declared
+		return nil
+	}
+	if _, ok := b.declared[topic]; !ok {
+		return fmt.Errorf("%w: %v", ErrUnknownTopic, topic)
+	}
+	return nil
+}
+
+// DeclareTopic declares the named topic on the default Bus.
+func DeclareTopic(topic interface{}) {
+	getDefaultBus().DeclareTopic(topic)
+}
+
+// DeclaredTopics returns the topics declared on the default Bus.
+func DeclaredTopics() []interface{} {
+	return getDefaultBus().DeclaredTopics()
+}
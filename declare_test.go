@@ -0,0 +1,74 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeclaredTopics(t *testing.T) {
+	bus := NewBus(WithDeclaredTopics("user.signup"))
+	bus.DeclareTopic("user.login")
+	assert.Equal(t, []interface{}{"user.login", "user.signup"}, bus.DeclaredTopics())
+
+	h := &mockHandler{}
+	bus.Subscribe("user.signup", h)
+	n, err := bus.Publish("user.signup", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = bus.Publish("user.signpu", 2)
+	assert.True(t, errors.Is(err, ErrUnknownTopic))
+	assert.Equal(t, 0, n)
+
+	_, err = bus.SubscribeSafe("user.signpu", h)
+	assert.True(t, errors.Is(err, ErrUnknownTopic))
+	_, err = bus.SubscribeID("user.signpu", h)
+	assert.True(t, errors.Is(err, ErrUnknownTopic))
+	assert.Panics(t, func() {
+		bus.Subscribe("user.signpu", h)
+	})
+	assert.Empty(t, bus.Resolve("user.signpu"), "failed subscriptions should not be added")
+}
+
+func TestDeclaredTopicsNamespace(t *testing.T) {
+	bus := NewBus(WithDeclaredTopics())
+	ns := bus.Namespace("app")
+	ns.DeclareTopic("start")
+	assert.Equal(t, []interface{}{"app.start"}, bus.DeclaredTopics())
+
+	ns.Subscribe("start", &mockHandler{})
+	n, err := bus.Publish("app.start", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestDeclaredTopicsNotStrict(t *testing.T) {
+	bus := NewBus()
+	bus.DeclareTopic("test")
+	assert.Equal(t, []interface{}{"test"}, bus.DeclaredTopics())
+
+	bus.Subscribe("other", &mockHandler{})
+	n, err := bus.Publish("other", 1)
+	assert.NoError(t, err, "undeclared topics should be allowed by default")
+	assert.Equal(t, 1, n)
+}
+
+func TestDeclaredTopicsPanicUnlocks(t *testing.T) {
+	bus := NewBus(WithDeclaredTopics())
+	h := &mockHandler{}
+	assert.Panics(t, func() {
+		bus.SubscribeSticky("test", h)
+	})
+	assert.Panics(t, func() {
+		bus.SubscribeReplay("test", h)
+	})
+
+	// The Bus is still usable after the panics
+	bus.DeclareTopic("test")
+	bus.Subscribe("test", h)
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
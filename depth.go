@@ -0,0 +1,51 @@
+package bus
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// depths counts the synchronous deliveries in progress on each goroutine,
+// so that runaway recursive publishing can be stopped.
+type depths struct {
+	lock   sync.Mutex
+	counts map[uint64]int
+}
+
+// enter records the start of a delivery on the given goroutine, returning
+// the number of deliveries now in progress on it.
+func (d *depths) enter(g uint64) int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.counts == nil {
+		d.counts = make(map[uint64]int)
+	}
+	d.counts[g]++
+	return d.counts[g]
+}
+
+// leave records the end of a delivery on the given goroutine.
+func (d *depths) leave(g uint64) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.counts[g]--; d.counts[g] == 0 {
+		delete(d.counts, g)
+	}
+}
+
+// goroutineID returns the id of the calling goroutine, as reported in stack
+// traces.
+func goroutineID() uint64 {
+	var buf [64]byte
+	s := buf[:runtime.Stack(buf[:], false)]
+	s = bytes.TrimPrefix(s, []byte("goroutine "))
+	if i := bytes.IndexByte(s, ' '); i >= 0 {
+		s = s[:i]
+	}
+	id, _ := strconv.ParseUint(string(s), 10, 64)
+	return id
+}
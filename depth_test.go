@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxDepth(t *testing.T) {
+	bus := NewBus(WithMaxDepth(3))
+	calls := 0
+	var err error
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		calls++
+		if _, perr := bus.Publish("test", v); perr != nil {
+			err = perr
+		}
+	})
+
+	n, perr := bus.Publish("test", 1)
+	assert.NoError(t, perr)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 3, calls, "recursion should stop at the configured depth")
+	assert.Equal(t, ErrMaxDepth, err)
+
+	calls = 0
+	bus.Publish("test", 2)
+	assert.Equal(t, 3, calls, "depth should be reset after publishing")
+}
+
+func TestMaxDepthUnlimited(t *testing.T) {
+	bus := NewBus()
+	calls := 0
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		if calls++; calls < 100 {
+			bus.Publish("test", v)
+		}
+	})
+
+	bus.Publish("test", 1)
+	assert.Equal(t, 100, calls)
+}
+
+func TestGoroutineID(t *testing.T) {
+	id := goroutineID()
+	assert.NotZero(t, id)
+	assert.Equal(t, id, goroutineID())
+
+	ch := make(chan uint64)
+	go func() { ch <- goroutineID() }()
+	assert.NotEqual(t, id, <-ch)
+}
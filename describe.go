@@ -0,0 +1,225 @@
+package bus
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TopicDescription summarizes the handlers subscribed to a topic.
+type TopicDescription struct {
+	// Topic is the topic described.
+	Topic interface{}
+
+	// HandlerCount is the number of handlers subscribed to the topic.
+	HandlerCount int
+
+	// HandlerTypes holds the type of each handler, in the order they are
+	// called.
+	HandlerTypes []string
+}
+
+// Describe returns a description of each topic with handlers subscribed to
+// it on this Bus, ordered by the topics' string representations. It is
+// intended for debugging, and takes a consistent snapshot of the Bus, but its
+// output should not be relied upon otherwise. Handlers subscribed with
+// SubscribeAll or SubscribeFallback are not included.
+func (b *Bus) Describe() []TopicDescription {
+	b.lock.RLock()
+	ds := make([]TopicDescription, 0, len(b.topics))
+	for t, ss := range b.topics {
+		d := TopicDescription{
+			Topic:        t,
+			HandlerCount: len(ss),
+			HandlerTypes: make([]string, len(ss)),
+		}
+		for i, s := range ss {
+			h := s.handler
+			if nh, ok := h.(*nsHandler); ok {
+				h = nh.h
+			}
+			d.HandlerTypes[i] = reflect.TypeOf(h).String()
+		}
+		ds = append(ds, d)
+	}
+	b.lock.RUnlock()
+
+	// Topics are sorted without the lock held, as formatting them may call
+	// their String methods
+	sort.Slice(ds, func(i, j int) bool {
+		return fmt.Sprint(ds[i].Topic) < fmt.Sprint(ds[j].Topic)
+	})
+	return ds
+}
+
+// SubscriptionInfo describes a single subscription.
+type SubscriptionInfo struct {
+	// ID identifies the subscription, as accepted by UnsubscribeID.
+	ID SubscriptionID
+
+	// Topic is the topic the handler is subscribed to, including any
+	// namespace prefix, or the prefix for handlers subscribed with
+	// SubscribePrefix. It is nil for handlers subscribed with SubscribeAll
+	// or SubscribeFallback.
+	Topic interface{}
+
+	// Handler is the handler as it was subscribed.
+	Handler Handler
+
+	// Tag is the tag the handler was subscribed with by SubscribeTagged, if
+	// any.
+	Tag string
+
+	// Paused is set if the subscription has been paused through its
+	// Subscription handle or its tag.
+	Paused bool
+
+	// Async is set if the handler was subscribed with SubscribeAsync, and
+	// so is always called in a goroutine of its own.
+	Async bool
+
+	// CreatedAt is the time the subscription was made.
+	CreatedAt time.Time
+}
+
+// ListSubscriptions returns a description of every subscription on this Bus,
+// including those made with SubscribeAll, SubscribeFallback and
+// SubscribePrefix, in the order they were made. It describes a consistent
+// snapshot of the Bus, but subscriptions may change as soon as it returns.
+func (b *Bus) ListSubscriptions() []SubscriptionInfo {
+	b.lock.RLock()
+	ss := make([]*subscription, 0, len(b.ids))
+	for _, s := range b.ids {
+		ss = append(ss, s)
+	}
+	b.lock.RUnlock()
+
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].id < ss[j].id
+	})
+	infos := make([]SubscriptionInfo, len(ss))
+	for i, s := range ss {
+		infos[i] = SubscriptionInfo{
+			ID:        s.id,
+			Topic:     s.topic,
+			Handler:   subscribed(s.handler),
+			Tag:       s.tag,
+			Paused:    paused(s.handler),
+			Async:     alwaysAsync(s.handler),
+			CreatedAt: s.created,
+		}
+	}
+	return infos
+}
+
+// paused reports whether the handler, or any handler it wraps, has been
+// paused through its Subscription handle or its tag.
+func paused(h Handler) bool {
+	for h != nil {
+		switch ph := h.(type) {
+		case *Subscription:
+			if ph.Paused() {
+				return true
+			}
+		case *tagHandler:
+			if ph.paused.Load() {
+				return true
+			}
+		}
+		w, ok := h.(wrapper)
+		if !ok {
+			return false
+		}
+		h = w.unwrap()
+	}
+	return false
+}
+
+// DebugString returns a human-readable summary of the handlers subscribed on
+// this Bus, for logging during development and in test failure messages. It
+// lists each topic, ordered as by Describe, on a line of its own with the
+// number of handlers it has and their types, followed by the handlers
+// subscribed with SubscribePrefix, SubscribeAll and SubscribeFallback. Like
+// Describe, its output is meant for people and may change.
+func (b *Bus) DebugString() string {
+	b.lock.RLock()
+	var prefixes [][2]string
+	var globals, fallbacks []string
+	for _, s := range b.prefixed {
+		prefixes = append(prefixes, [2]string{fmt.Sprintf("%v*", s.topic), handlerType(s)})
+	}
+	for _, s := range b.globals {
+		globals = append(globals, handlerType(s))
+	}
+	for _, s := range b.fallbacks {
+		fallbacks = append(fallbacks, handlerType(s))
+	}
+	b.lock.RUnlock()
+
+	var sb strings.Builder
+	for _, d := range b.Describe() {
+		writeDebugLine(&sb, fmt.Sprint(d.Topic), d.HandlerTypes)
+	}
+	for _, p := range prefixes {
+		writeDebugLine(&sb, p[0], p[1:])
+	}
+	if len(globals) > 0 {
+		writeDebugLine(&sb, "(all topics)", globals)
+	}
+	if len(fallbacks) > 0 {
+		writeDebugLine(&sb, "(fallback)", fallbacks)
+	}
+	if sb.Len() == 0 {
+		return "no handlers\n"
+	}
+	return sb.String()
+}
+
+// handlerType returns the type of the handler of the subscription, as
+// passed to Subscribe.
+func handlerType(s *subscription) string {
+	return reflect.TypeOf(subscribed(s.handler)).String()
+}
+
+// writeDebugLine writes a line of DebugString, describing the handlers of the
+// given types.
+func writeDebugLine(sb *strings.Builder, name string, types []string) {
+	noun := "handlers"
+	if len(types) == 1 {
+		noun = "handler"
+	}
+	fmt.Fprintf(sb, "%s: %d %s (%s)\n", name, len(types), noun, strings.Join(types, ", "))
+}
+
+// ForEach calls fn with each topic on this Bus and each handler subscribed to
+// it, in the order the handlers were subscribed, until fn returns false. It
+// iterates over a snapshot taken before fn is first called, so fn may use the
+// Bus, for example to unsubscribe handlers. Handlers subscribed with
+// SubscribeAll or SubscribeFallback are not included.
+func (b *Bus) ForEach(fn func(topic interface{}, h Handler) bool) {
+	b.ForEachSubscription(func(id SubscriptionID, topic interface{}, h Handler) bool {
+		return fn(topic, h)
+	})
+}
+
+// ForEachSubscription is like ForEach, but also passes fn the identifier of
+// each subscription, which can be passed to UnsubscribeID to remove it.
+func (b *Bus) ForEachSubscription(fn func(id SubscriptionID, topic interface{}, h Handler) bool) {
+	b.lock.RLock()
+	var ss []*subscription
+	for _, ts := range b.topics {
+		ss = append(ss, ts...)
+	}
+	b.lock.RUnlock()
+
+	sort.Slice(ss, func(i, j int) bool {
+		return ss[i].id < ss[j].id
+	})
+	for _, s := range ss {
+		if !fn(s.id, s.topic, subscribed(s.handler)) {
+			return
+		}
+	}
+}
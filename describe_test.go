@@ -0,0 +1,114 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	bus := NewBus()
+	assert.Empty(t, bus.Describe())
+
+	bus.SubscribeFunc("b", func(b *Bus, tp, v interface{}) {})
+	bus.Subscribe("b", &mockHandler{})
+	bus.Namespace("ns").Subscribe("a", &mockHandler{})
+	bus.SubscribeAll(HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+
+	assert.Equal(t, []TopicDescription{
+		{Topic: "b", HandlerCount: 2, HandlerTypes: []string{"*bus.HandlerFunc", "*bus.mockHandler"}},
+		{Topic: "ns.a", HandlerCount: 1, HandlerTypes: []string{"*bus.mockHandler"}},
+	}, bus.Describe())
+}
+
+func TestForEach(t *testing.T) {
+	bus := NewBus()
+	a := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	c := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	bus.Subscribe("b", &a)
+	bus.Namespace("ns").Subscribe("a", &c)
+	bus.SubscribeAll(&a)
+
+	var topics []interface{}
+	var hs []Handler
+	bus.ForEach(func(topic interface{}, h Handler) bool {
+		topics = append(topics, topic)
+		hs = append(hs, h)
+		return true
+	})
+	assert.Equal(t, []interface{}{"b", "ns.a"}, topics)
+	assert.Equal(t, []Handler{&a, &c}, hs)
+
+	calls := 0
+	bus.ForEach(func(topic interface{}, h Handler) bool {
+		calls++
+		return false
+	})
+	assert.Equal(t, 1, calls, "iteration should stop when fn returns false")
+}
+
+func TestForEachSubscriptionUnsubscribe(t *testing.T) {
+	bus := NewBus()
+	for i := 0; i < 3; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	}
+
+	bus.ForEachSubscription(func(id SubscriptionID, topic interface{}, h Handler) bool {
+		assert.True(t, bus.UnsubscribeID(id), "fn should be able to use the bus")
+		return true
+	})
+	assert.False(t, bus.Has("test"))
+}
+
+func TestDebugString(t *testing.T) {
+	bus := NewBus()
+	assert.Equal(t, "no handlers\n", bus.DebugString())
+
+	bus.SubscribeFunc("b", func(b *Bus, tp, v interface{}) {})
+	bus.Subscribe("b", &mockHandler{})
+	bus.Namespace("ns").Subscribe("a", &mockHandler{})
+	bus.SubscribePrefix("ns.", &mockHandler{})
+	bus.SubscribeAll(HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+	bus.SubscribeFallback(&mockHandler{})
+
+	assert.Equal(t, "b: 2 handlers (*bus.HandlerFunc, *bus.mockHandler)\n"+
+		"ns.a: 1 handler (*bus.mockHandler)\n"+
+		"ns.*: 1 handler (*bus.mockHandler)\n"+
+		"(all topics): 1 handler (bus.HandlerFunc)\n"+
+		"(fallback): 1 handler (*bus.mockHandler)\n", bus.DebugString())
+}
+
+func TestListSubscriptions(t *testing.T) {
+	clock := NewFakeClock(time.Unix(100, 0))
+	bus := NewBus(WithClock(clock))
+	assert.Empty(t, bus.ListSubscriptions())
+
+	h := &mockHandler{}
+	bus.Subscribe("a", h)
+	clock.Advance(time.Second)
+	sub := bus.SubscribeHandle("b", h)
+	sub.Pause()
+	bus.SubscribeTagged("plugin", "c", h)
+	bus.PauseTagged("plugin")
+	bus.SubscribeAsync("d", h)
+	bus.SubscribeAll(h)
+
+	infos := bus.ListSubscriptions()
+	if assert.Len(t, infos, 5) {
+		assert.Equal(t, SubscriptionInfo{ID: infos[0].ID, Topic: "a", Handler: h, CreatedAt: time.Unix(100, 0)}, infos[0])
+		assert.Equal(t, "b", infos[1].Topic)
+		assert.Same(t, sub, infos[1].Handler)
+		assert.True(t, infos[1].Paused)
+		assert.Equal(t, time.Unix(101, 0), infos[1].CreatedAt)
+		assert.Equal(t, "plugin", infos[2].Tag)
+		assert.True(t, infos[2].Paused)
+		assert.True(t, infos[3].Async)
+		assert.False(t, infos[3].Paused)
+		assert.Nil(t, infos[4].Topic)
+		assert.True(t, infos[0].ID < infos[4].ID, "subscriptions should be in the order they were made")
+	}
+
+	sub.Resume()
+	assert.False(t, bus.ListSubscriptions()[1].Paused)
+}
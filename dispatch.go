@@ -0,0 +1,67 @@
+package bus
+
+import (
+	"errors"
+)
+
+// Dispatcher determines how a published value is delivered to the handlers
+// that are to receive it, allowing the scheduling of handlers to be
+// customized, for example to use a worker pool.
+type Dispatcher interface {
+	// Dispatch delivers the value published to topic to each of handlers,
+	// returning the number of handlers called. If async is true, the value
+	// was published with the Async flag, and Dispatch should not block
+	// waiting for handlers to return.
+	Dispatch(b *Bus, handlers []Handler, topic, value interface{}, async bool) (int, error)
+}
+
+// DefaultDispatcher is the Dispatcher used by a Bus unless configured
+// otherwise. It calls each handler in turn in the publishing goroutine,
+// joining the errors reported by any ErrHandlers, or in a new goroutine per
+// handler when async is true or the handler was subscribed with
+// SubscribeAsync. Goroutines started by DefaultDispatcher respect
+// WithMaxConcurrency, and are waited for by Drain and Close; custom
+// Dispatchers that start their own goroutines are
+// responsible for managing them.
+var DefaultDispatcher Dispatcher = defaultDispatcher{}
+
+type defaultDispatcher struct{}
+
+func (defaultDispatcher) Dispatch(b *Bus, handlers []Handler, topic, value interface{}, async bool) (int, error) {
+	var errs []error
+	for _, h := range handlers {
+		if async {
+			// Call handler in a separate Goroutine
+			b.goAsync(h, topic, value)
+		} else if err := b.deliverSync(h, topic, value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(handlers), errors.Join(errs...)
+}
+
+// DispatcherFunc is an adaptor that allows a function to act as a Dispatcher.
+type DispatcherFunc func(b *Bus, handlers []Handler, topic, value interface{}, async bool) (int, error)
+
+func (f DispatcherFunc) Dispatch(b *Bus, handlers []Handler, topic, value interface{}, async bool) (int, error) {
+	return f(b, handlers, topic, value, async)
+}
+
+// notifyHandler calls a function each time its handler returns.
+type notifyHandler struct {
+	h  Handler
+	fn func()
+}
+
+func (h *notifyHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *notifyHandler) OnErr(b *Bus, t, v interface{}) error {
+	defer h.fn()
+	return call(b, h.h, t, v)
+}
+
+func (h *notifyHandler) unwrap() Handler {
+	return h.h
+}
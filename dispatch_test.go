@@ -0,0 +1,46 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDispatcher(t *testing.T) {
+	var calls []bool
+	d := DispatcherFunc(func(b *Bus, hs []Handler, tp, v interface{}, async bool) (int, error) {
+		calls = append(calls, async)
+		// Deliver in reverse order, synchronously
+		for i := len(hs) - 1; i >= 0; i-- {
+			hs[i].On(b, tp, v)
+		}
+		return len(hs), nil
+	})
+	bus := NewBus(WithDispatcher(d))
+
+	var order []int
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		order = append(order, 1)
+	})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		order = append(order, 2)
+	})
+	bus.SubscribeFilter("test", func(v interface{}) bool {
+		return false
+	}, &mockHandler{})
+
+	n, err := bus.Publish("test", "hello", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n, "dispatcher should only receive accepted handlers")
+	assert.Equal(t, []int{2, 1}, order)
+	assert.Equal(t, []bool{true}, calls)
+}
+
+func TestDefaultDispatcher(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	n, err := DefaultDispatcher.Dispatch(bus, []Handler{h}, "test", "hello", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "hello", h.v)
+}
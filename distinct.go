@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"reflect"
+	"sync"
+)
+
+// distinctHandler passes on only those values that differ from the last value
+// it passed on.
+type distinctHandler struct {
+	lock  sync.Mutex
+	equal func(a, b interface{}) bool
+	last  interface{}
+	seen  bool
+	h     Handler
+}
+
+func (h *distinctHandler) accept(b *Bus, t, v interface{}) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.seen && h.equal(h.last, v) {
+		return false
+	}
+	h.last = v
+	h.seen = true
+	return true
+}
+
+func (h *distinctHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *distinctHandler) OnErr(b *Bus, t, v interface{}) error {
+	return call(b, h.h, t, v)
+}
+
+func (h *distinctHandler) unwrap() Handler {
+	return h.h
+}
+
+// SubscribeDistinct causes the passed Handler to be called when data is
+// published to the named topic on this Bus, skipping values that are equal to
+// the last value delivered to it. The first value is always delivered. If
+// equal is nil, values are compared using reflect.DeepEqual. Skipped values
+// are not counted as deliveries by Publish.
+func (b *Bus) SubscribeDistinct(topic interface{}, equal func(a, b interface{}) bool, h Handler) UnsubscribeFunc {
+	if equal == nil {
+		equal = reflect.DeepEqual
+	}
+	return b.Subscribe(topic, &distinctHandler{equal: equal, h: h})
+}
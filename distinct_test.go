@@ -0,0 +1,39 @@
+package bus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeDistinct(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	defer bus.SubscribeDistinct("state", nil, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))()
+
+	counts := []int{}
+	for _, v := range []interface{}{"on", "on", "off", []int{1}, []int{1}, "on"} {
+		n, err := bus.Publish("state", v)
+		assert.NoError(t, err)
+		counts = append(counts, n)
+	}
+	assert.Equal(t, []int{1, 0, 1, 1, 0, 1}, counts)
+	assert.Equal(t, []interface{}{"on", "off", []int{1}, "on"}, got)
+}
+
+func TestSubscribeDistinctEqual(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	defer bus.SubscribeDistinct("state", func(a, b interface{}) bool {
+		return strings.EqualFold(a.(string), b.(string))
+	}, h)()
+
+	n, _ := bus.Publish("state", "on")
+	assert.Equal(t, 1, n, "first value should always be delivered")
+	n, _ = bus.Publish("state", "ON")
+	assert.Equal(t, 0, n, "custom equality should be used")
+	assert.Equal(t, "on", h.v)
+}
@@ -0,0 +1,199 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrBusClosed is returned when publishing to a Bus that has been
+	// closed.
+	ErrBusClosed = errors.New("bus: closed")
+
+	// ErrTimeout is returned when an operation does not complete within its
+	// allotted time.
+	ErrTimeout = errors.New("bus: timed out")
+
+	// ErrNoSubscribers is returned when publishing to a topic that has no
+	// handlers, if the Bus was created with WithNoSubscribersError.
+	ErrNoSubscribers = errors.New("bus: no subscribers")
+
+	// ErrMaxDepth is returned when publishing from within a handler would
+	// exceed the depth limit set by WithMaxDepth.
+	ErrMaxDepth = errors.New("bus: maximum publish depth exceeded")
+
+	// ErrPayloadType is returned when publishing a value to a topic
+	// registered with RegisterTopicType that is not of the topic's type.
+	ErrPayloadType = errors.New("bus: payload type does not match topic")
+
+	// ErrNilHandler is returned by SubscribeSafe when passed a nil Handler.
+	// Subscribe panics with the same message. It is also returned by
+	// Publish if a nil handler is found among a topic's handlers, unless
+	// the Bus was created with WithSkipNilHandlers.
+	ErrNilHandler = errors.New("bus: nil handler")
+
+	// ErrInvalidTopic is returned when subscribing or publishing to a topic
+	// that cannot be used as a map key, such as a slice or a map, or to a
+	// nil topic on a Bus created with WithRejectNilTopic. Subscribe panics
+	// with the same message.
+	ErrInvalidTopic = errors.New("bus: invalid topic")
+
+	// ErrUnknownTopic is returned when publishing or subscribing to a topic
+	// that has not been declared on a Bus created with WithDeclaredTopics.
+	// Subscribe panics with the same message.
+	ErrUnknownTopic = errors.New("bus: unknown topic")
+
+	// ErrAliasCycle is returned by Alias when the alias would cause a
+	// topic to resolve to itself.
+	ErrAliasCycle = errors.New("bus: alias cycle")
+
+	// ErrTopicClosed is returned when publishing to a topic that has been
+	// closed with CloseTopic.
+	ErrTopicClosed = errors.New("bus: topic closed")
+
+	// ErrOutOfOrder is returned by PublishSeq on a Bus created with
+	// WithSeqPolicy(SeqReject) when the sequence number is not greater than
+	// that of the topic's previous value.
+	ErrOutOfOrder = errors.New("bus: sequence number out of order")
+
+	// ErrOrderedQueueFull is returned when a handler called from a topic's
+	// OrderedAsync queue publishes to the same topic with OrderedAsync while
+	// the queue is full, which would otherwise wait forever for the handler
+	// to return.
+	ErrOrderedQueueFull = errors.New("bus: ordered queue full")
+
+	// ErrRateLimited is returned when publishing to a topic that has used
+	// up the budget set by SetTopicRateLimit.
+	ErrRateLimited = errors.New("bus: topic rate limit exceeded")
+
+	// ErrBatchDone is returned when publishing to or committing a batch
+	// begun with BeginBatch that has already been committed or rolled back.
+	ErrBatchDone = errors.New("bus: batch already committed or rolled back")
+)
+
+// HandlerError is returned by Publish when an ErrHandler fails to handle a
+// value. When several handlers fail, their errors are joined, and each can
+// be found using errors.As.
+type HandlerError struct {
+	// Topic is the topic the value was published to.
+	Topic interface{}
+
+	// Value is the value the handler failed to handle.
+	Value interface{}
+
+	// Err is the error returned by the handler.
+	Err error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("bus: handler for topic %v: %v", e.Topic, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
+// Deliver calls the handler with the value published to the given topic,
+// returning any error reported by the handler wrapped in a HandlerError. If
+// the Bus has a clone function, the handler is passed a clone of the value.
+// Custom Dispatchers should deliver values using Deliver so that handler
+// errors are reported by Publish. If the Bus has a handler timer or metrics
+// sink, it is passed the time taken by the handler, as is its slow handler
+// callback if the handler took longer than the threshold. A panic in the
+// handler is dealt with according to the Bus's PanicPolicy.
+func (b *Bus) Deliver(h Handler, t, v interface{}) error {
+	hv := v
+	if b.clone != nil {
+		hv = b.clone(v)
+	}
+	var err error
+	if b.timer != nil || b.metrics != nil || b.slowWarn != nil {
+		start := b.clock.Now()
+		err = b.callHandler(h, t, hv)
+		d := b.clock.Now().Sub(start)
+		if b.timer != nil {
+			b.timer(t, subscribed(h), d)
+		}
+		if b.metrics != nil {
+			b.metrics.ObserveHandlerDuration(fmt.Sprint(t), d)
+		}
+		if b.slowWarn != nil && d > b.slow {
+			b.slowWarn(t, subscribed(h), d)
+		}
+	} else {
+		err = b.callHandler(h, t, hv)
+	}
+	if err != nil {
+		return &HandlerError{Topic: t, Value: v, Err: err}
+	}
+	return nil
+}
+
+// reportAsync records the errors reported by handlers called asynchronously,
+// for LastAsyncError and the Bus's async error handler, if any.
+func (b *Bus) reportAsync(err error) {
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(interface{ Unwrap() []error }); ok {
+		// Several handlers failed
+		for _, err := range errs.Unwrap() {
+			b.reportAsync(err)
+		}
+		return
+	}
+
+	herr, ok := err.(*HandlerError)
+	if !ok {
+		herr = &HandlerError{Err: err}
+	}
+	b.lastErr.Store(herr)
+	if b.asyncErr != nil {
+		b.asyncErr(herr.Topic, herr.Value, herr.Err)
+	}
+}
+
+// LastAsyncError returns the most recent error reported by an ErrHandler
+// called asynchronously on this Bus, wrapped in a HandlerError, or nil if
+// there has been none. It is intended for debugging; use
+// WithAsyncErrorHandler to be told about every error.
+func (b *Bus) LastAsyncError() error {
+	if herr := b.lastErr.Load(); herr != nil {
+		return herr
+	}
+	return nil
+}
+
+// LastAsyncError returns the most recent error reported by an ErrHandler
+// called asynchronously on the default Bus.
+func LastAsyncError() error {
+	return getDefaultBus().LastAsyncError()
+}
+
+// subscribed returns the handler as it was subscribed, removing the wrappers
+// the Bus adds internally for namespaces, delivery notification, tags,
+// matched topics and SubscribeAsync.
+func subscribed(h Handler) Handler {
+	for {
+		switch w := h.(type) {
+		case *nsHandler:
+			h = w.h
+		case *notifyHandler:
+			h = w.h
+		case *asyncHandler:
+			h = w.h
+		case *cancelHandler:
+			h = w.h
+		case *tryHandler:
+			h = w.h
+		case *tagHandler:
+			h = w.h
+		case *matchedHandler:
+			h = w.h
+		case *reduceStep:
+			h = w.h
+		default:
+			return h
+		}
+	}
+}
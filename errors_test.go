@@ -0,0 +1,240 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingHandler is an ErrHandler that always fails with its error.
+type failingHandler struct {
+	err   error
+	calls int
+}
+
+func (h *failingHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *failingHandler) OnErr(b *Bus, t, v interface{}) error {
+	h.calls++
+	return h.err
+}
+
+func TestHandlerError(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	bus.Subscribe("test", &failingHandler{err: errFail})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+
+	n, err := bus.Publish("test", "value")
+	assert.Equal(t, 2, n, "all handlers should be called")
+	assert.True(t, errors.Is(err, errFail))
+
+	var herr *HandlerError
+	if assert.True(t, errors.As(err, &herr)) {
+		assert.Equal(t, "test", herr.Topic)
+		assert.Equal(t, "value", herr.Value)
+		assert.Equal(t, errFail, herr.Err)
+	}
+}
+
+func TestSubscribeFuncErr(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	unsub := bus.SubscribeFuncErr("test", func(b *Bus, tp, v interface{}) error {
+		if v == "bad" {
+			return errFail
+		}
+		return nil
+	})
+
+	n, err := bus.Publish("test", "good")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = bus.Publish("test", "bad")
+	assert.True(t, errors.Is(err, errFail))
+	assert.Equal(t, 1, n)
+
+	assert.True(t, unsub())
+	n, err = bus.Publish("test", "bad")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestHandlerErrorJoined(t *testing.T) {
+	bus := NewBus()
+	errA, errB := errors.New("a"), errors.New("b")
+	bus.Subscribe("test", &failingHandler{err: errA})
+	bus.Subscribe("test", &failingHandler{err: errB})
+
+	_, err := bus.Publish("test", 1)
+	assert.True(t, errors.Is(err, errA))
+	assert.True(t, errors.Is(err, errB))
+}
+
+func TestHandlerErrorWrapped(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	bus.SubscribeFilter("test", func(v interface{}) bool { return true }, &failingHandler{err: errFail})
+	bus.Namespace("ns").Subscribe("test", &failingHandler{err: errFail})
+
+	_, err := bus.Publish("test", 1)
+	assert.True(t, errors.Is(err, errFail), "filtered handler should report errors")
+	_, err = bus.Publish("ns.test", 1)
+	assert.True(t, errors.Is(err, errFail), "namespaced handler should report errors")
+}
+
+func TestHandlerErrorAsync(t *testing.T) {
+	bus := NewBus()
+	h := &failingHandler{err: errors.New("fail")}
+	bus.Subscribe("test", h)
+
+	n, err := bus.Publish("test", 1, Async)
+	assert.NoError(t, err, "async errors are not returned")
+	assert.Equal(t, 1, n)
+	bus.Drain()
+	assert.Equal(t, 1, h.calls)
+}
+
+func TestNoSubscribersError(t *testing.T) {
+	n, err := NewBus().Publish("test", 1)
+	assert.NoError(t, err, "error should be opt-in")
+	assert.Equal(t, 0, n)
+
+	bus := NewBus(WithNoSubscribersError())
+	_, err = bus.Publish("test", 1)
+	assert.Equal(t, ErrNoSubscribers, err)
+
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	_, err = bus.Publish("test", 1)
+	assert.NoError(t, err)
+}
+
+func TestInvalidTopic(t *testing.T) {
+	bus := NewBus()
+	h := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+
+	for _, topic := range []interface{}{[]string{"a"}, map[string]int{}, [1]interface{}{[]int{}}} {
+		n, err := bus.Publish(topic, 1)
+		assert.Equal(t, ErrInvalidTopic, err)
+		assert.Equal(t, 0, n)
+
+		_, err = bus.SubscribeSafe(topic, h)
+		assert.Equal(t, ErrInvalidTopic, err)
+		_, err = bus.SubscribeID(topic, h)
+		assert.Equal(t, ErrInvalidTopic, err)
+		assert.PanicsWithValue(t, "bus: invalid topic", func() {
+			bus.Subscribe(topic, h)
+		})
+	}
+
+	// Comparable topics of any type remain valid
+	type key struct{ a, b string }
+	_, err := bus.SubscribeSafe(key{"a", "b"}, h)
+	assert.NoError(t, err)
+	n, err := bus.Publish(key{"a", "b"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestRejectNilTopic(t *testing.T) {
+	h := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+
+	bus := NewBus()
+	_, err := bus.SubscribeSafe(nil, h)
+	assert.NoError(t, err, "nil should be a valid topic by default")
+	n, err := bus.Publish(nil, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	bus = NewBus(WithRejectNilTopic())
+	n, err = bus.Publish(nil, 1)
+	assert.Equal(t, ErrInvalidTopic, err)
+	assert.Equal(t, 0, n)
+	_, err = bus.SubscribeSafe(nil, h)
+	assert.Equal(t, ErrInvalidTopic, err)
+	_, err = bus.SubscribeID(nil, h)
+	assert.Equal(t, ErrInvalidTopic, err)
+	assert.PanicsWithValue(t, "bus: invalid topic", func() {
+		bus.Subscribe(nil, h)
+	})
+
+	_, err = bus.Publish("test", 1)
+	assert.NoError(t, err, "other topics should be unaffected")
+}
+
+// TestNilHandlerInTopic checks that a nil handler found among a topic's
+// handlers is skipped rather than causing a panic.
+func TestNilHandlerInTopic(t *testing.T) {
+	for _, opts := range [][]BusOption{nil, {WithSkipNilHandlers()}} {
+		bus := NewBus(opts...)
+		calls := 0
+		h := HandlerFunc(func(b *Bus, tp, v interface{}) { calls++ })
+		bus.Subscribe("test", h)
+		bus.Subscribe("lone", h)
+
+		// Corrupt the topics' handlers
+		bus.lock.Lock()
+		bus.topics["test"] = append(bus.topics["test"], nil, &subscription{topic: "test"})
+		bus.topics["lone"][0] = &subscription{topic: "lone"}
+		bus.lock.Unlock()
+
+		for _, topic := range []string{"test", "lone"} {
+			n, err := bus.Publish(topic, 1)
+			if opts == nil {
+				assert.ErrorIs(t, err, ErrNilHandler)
+				assert.ErrorContains(t, err, topic)
+			} else {
+				assert.NoError(t, err)
+			}
+			if topic == "test" {
+				assert.Equal(t, 1, n, "valid handlers should still be called")
+			}
+		}
+		assert.Equal(t, 1, calls)
+	}
+}
+
+func TestAsyncErrorHandler(t *testing.T) {
+	type report struct {
+		topic, value interface{}
+		err          error
+	}
+	reports := make(chan report, 10)
+	bus := NewBus(WithAsyncErrorHandler(func(topic, value interface{}, err error) {
+		reports <- report{topic, value, err}
+	}))
+	assert.NoError(t, bus.LastAsyncError())
+
+	errFail := errors.New("fail")
+	bus.Subscribe("test", &failingHandler{err: errFail})
+	bus.Subscribe("test", &failingHandler{})
+
+	_, err := bus.Publish("test", 1, Async)
+	assert.NoError(t, err)
+	bus.Drain()
+	assert.Equal(t, report{"test", 1, errFail}, <-reports)
+	assert.Len(t, reports, 0, "successful handlers should not be reported")
+
+	bus.Publish("test", 2, OrderedAsync)
+	bus.Drain()
+	assert.Equal(t, report{"test", 2, errFail}, <-reports)
+
+	var herr *HandlerError
+	assert.True(t, errors.As(bus.LastAsyncError(), &herr))
+	assert.Equal(t, 2, herr.Value)
+	assert.Equal(t, errFail, herr.Err)
+}
+
+func TestLastAsyncError(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	bus.SubscribeAsync("test", &failingHandler{err: errFail})
+
+	bus.Publish("test", 1)
+	bus.Drain()
+	assert.True(t, errors.Is(bus.LastAsyncError(), errFail), "errors should be recorded without a handler")
+}
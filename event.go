@@ -0,0 +1,47 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event is the wire format used to carry a published value between buses
+// over an external transport.
+type Event struct {
+	Topic     string          `json:"topic"`
+	Payload   json.RawMessage `json:"payload"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// MarshalEvent encodes a value published to the given topic as a JSON Event,
+// timestamped with the current time. The topic must be a string.
+func MarshalEvent(topic interface{}, v interface{}) ([]byte, error) {
+	t, ok := topic.(string)
+	if !ok {
+		return nil, fmt.Errorf("bus: cannot marshal event with %T topic", topic)
+	}
+
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(Event{
+		Topic:     t,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	})
+}
+
+// BridgePublish decodes a JSON Event, as produced by MarshalEvent, and
+// publishes its payload to the event's topic on the given Bus. Handlers
+// receive the payload as a json.RawMessage, which they may unmarshal into
+// the type they expect.
+func BridgePublish(b *Bus, data []byte) (int, error) {
+	var ev Event
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return 0, err
+	}
+	return b.Publish(ev.Topic, ev.Payload)
+}
@@ -0,0 +1,48 @@
+package bus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type kill struct {
+	Victim string
+}
+
+func TestMarshalEvent(t *testing.T) {
+	data, err := MarshalEvent("kills", kill{Victim: "Breen"})
+	assert.NoError(t, err)
+
+	var ev Event
+	assert.NoError(t, json.Unmarshal(data, &ev))
+	assert.Equal(t, "kills", ev.Topic)
+	assert.JSONEq(t, `{"Victim":"Breen"}`, string(ev.Payload))
+	assert.WithinDuration(t, time.Now(), ev.Timestamp, time.Minute)
+}
+
+func TestMarshalEventNonString(t *testing.T) {
+	_, err := MarshalEvent(42, "hello")
+	assert.Error(t, err, "non-string topics cannot be marshaled")
+}
+
+func TestBridgePublish(t *testing.T) {
+	bus := NewBus()
+	var got kill
+	defer bus.SubscribeFunc("kills", func(b *Bus, tp, v interface{}) {
+		assert.NoError(t, json.Unmarshal(v.(json.RawMessage), &got))
+	})()
+
+	data, err := MarshalEvent("kills", kill{Victim: "Breen"})
+	assert.NoError(t, err)
+
+	n, err := BridgePublish(bus, data)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, kill{Victim: "Breen"}, got)
+
+	_, err = BridgePublish(bus, []byte("not json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// LoggedEvent is a publish recorded by the event log of a Bus created with
+// WithEventLog.
+type LoggedEvent struct {
+	// Topic is the topic the value was published to.
+	Topic interface{}
+
+	// Value is the value published.
+	Value interface{}
+
+	// Time is the time at which the value was published.
+	Time time.Time
+
+	// Seq is the number of the publish, as passed to MetaHandlers.
+	Seq uint64
+}
+
+// eventLog is a ring buffer of the last publishes made on a Bus. It has its
+// own lock, so recording a publish never waits for the Bus lock.
+type eventLog struct {
+	lock   sync.Mutex
+	events []LoggedEvent
+	next   int
+	full   bool
+}
+
+// add records the publish, discarding the oldest if the log is full.
+func (l *eventLog) add(ev LoggedEvent) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.events[l.next] = ev
+	if l.next++; l.next == len(l.events) {
+		l.next = 0
+		l.full = true
+	}
+}
+
+// snapshot returns a copy of the recorded publishes, oldest first.
+func (l *eventLog) snapshot() []LoggedEvent {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if !l.full {
+		return append([]LoggedEvent(nil), l.events[:l.next]...)
+	}
+	evs := make([]LoggedEvent, 0, len(l.events))
+	evs = append(evs, l.events[l.next:]...)
+	return append(evs, l.events[:l.next]...)
+}
+
+// clear discards the recorded publishes.
+func (l *eventLog) clear() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	clear(l.events)
+	l.next = 0
+	l.full = false
+}
+
+// WithEventLog causes the Bus to record the last size values published to
+// any of its topics, which are returned by EventLog. Unlike WithHistory, the
+// log covers every topic, and is intended for auditing and debugging. A size
+// of 0 disables the log.
+func WithEventLog(size int) BusOption {
+	return func(b *Bus) {
+		if size > 0 {
+			b.eventLog = &eventLog{events: make([]LoggedEvent, size)}
+		} else {
+			b.eventLog = nil
+		}
+	}
+}
+
+// logPublish records the publish in the Bus's event log, if it has one.
+func (b *Bus) logPublish(d *delivery) {
+	if b.eventLog != nil {
+		b.eventLog.add(LoggedEvent{Topic: d.topic, Value: d.value, Time: b.clock.Now(), Seq: d.seq})
+	}
+}
+
+// EventLog returns the publishes recorded by the event log of this Bus,
+// oldest first, or nil if the Bus was not created with WithEventLog. Topics
+// are those of the underlying Bus, including any namespace prefixes.
+func (b *Bus) EventLog() []LoggedEvent {
+	if b.eventLog == nil {
+		return nil
+	}
+	return b.eventLog.snapshot()
+}
+
+// ClearEventLog discards the publishes recorded by the event log of this
+// Bus.
+func (b *Bus) ClearEventLog() {
+	if b.eventLog != nil {
+		b.eventLog.clear()
+	}
+}
+
+// EventLog returns the publishes recorded by the event log of the default
+// Bus, oldest first.
+func EventLog() []LoggedEvent {
+	return getDefaultBus().EventLog()
+}
+
+// ClearEventLog discards the publishes recorded by the event log of the
+// default Bus.
+func ClearEventLog() {
+	getDefaultBus().ClearEventLog()
+}
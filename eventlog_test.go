@@ -0,0 +1,45 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLog(t *testing.T) {
+	bus := NewBus(WithEventLog(3))
+	assert.Empty(t, bus.EventLog())
+
+	before := time.Now()
+	bus.Publish("a", 1)
+	bus.Publish("b", 2)
+	bus.TryPublish("c", 3)
+	bus.Namespace("ns").Publish("d", 4)
+
+	evs := bus.EventLog()
+	if assert.Len(t, evs, 3, "oldest publishes should be discarded") {
+		assert.Equal(t, "b", evs[0].Topic)
+		assert.Equal(t, 2, evs[0].Value)
+		assert.Equal(t, "c", evs[1].Topic)
+		assert.Equal(t, "ns.d", evs[2].Topic)
+		assert.Equal(t, 4, evs[2].Value)
+		assert.True(t, evs[0].Seq < evs[1].Seq && evs[1].Seq < evs[2].Seq, "sequence should increase")
+		assert.False(t, evs[0].Time.Before(before))
+	}
+
+	evs[0].Value = "changed"
+	assert.Equal(t, 2, bus.EventLog()[0].Value, "log should be a copy")
+
+	bus.ClearEventLog()
+	assert.Empty(t, bus.EventLog())
+	bus.Publish("e", 5)
+	assert.Len(t, bus.EventLog(), 1)
+}
+
+func TestEventLogDisabled(t *testing.T) {
+	bus := NewBus()
+	bus.Publish("a", 1)
+	assert.Nil(t, bus.EventLog())
+	bus.ClearEventLog()
+}
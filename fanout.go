@@ -0,0 +1,81 @@
+package bus
+
+import (
+	"sync"
+)
+
+// fanOutHandler passes each value it receives to one of several channels in
+// turn.
+type fanOutHandler struct {
+	lock   sync.Mutex
+	cs     []chan interface{}
+	next   int
+	closed bool
+}
+
+func (h *fanOutHandler) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	for i := range h.cs {
+		c := h.cs[(h.next+i)%len(h.cs)]
+		select {
+		case c <- v:
+			h.next = (h.next + i + 1) % len(h.cs)
+			return
+		default:
+		}
+	}
+	b.state(b.qualify(t)).stats.drop(1)
+}
+
+func (h *fanOutHandler) close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.closed {
+		h.closed = true
+		for _, c := range h.cs {
+			close(c)
+		}
+	}
+}
+
+// FanOut subscribes to the named topic on this Bus, returning n channels
+// between which the values published to it are shared, and a function that
+// unsubscribes and closes the channels. Unlike subscribing n handlers, each
+// value is received from only one of the channels, so that several consumers
+// can share the load. An n of less than 1 is treated as 1.
+//
+// Values are sent to each channel in turn. Sends never block: if the next
+// channel's buffer of the given size is full, the channels after it are
+// tried in turn, and a value that none of them has room for is dropped and
+// counted in the Stats of the topic.
+func (b *Bus) FanOut(topic interface{}, n int, buffer int) ([]<-chan interface{}, UnsubscribeFunc) {
+	if n < 1 {
+		n = 1
+	}
+	h := &fanOutHandler{cs: make([]chan interface{}, n)}
+	cs := make([]<-chan interface{}, n)
+	for i := range h.cs {
+		h.cs[i] = make(chan interface{}, buffer)
+		cs[i] = h.cs[i]
+	}
+
+	unsub := b.Subscribe(topic, h)
+	return cs, func() bool {
+		ok := unsub()
+		h.close()
+		return ok
+	}
+}
+
+// FanOut subscribes to the named topic on the default Bus, returning n
+// channels between which the values published to it are shared.
+func FanOut(topic interface{}, n int, buffer int) ([]<-chan interface{}, UnsubscribeFunc) {
+	return getDefaultBus().FanOut(topic, n, buffer)
+}
@@ -0,0 +1,48 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFanOut(t *testing.T) {
+	bus := NewBus()
+	cs, unsub := bus.FanOut("test", 3, 2)
+	assert.Len(t, cs, 3)
+
+	for i := 0; i < 6; i++ {
+		n, err := bus.Publish("test", i)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+	for i, c := range cs {
+		assert.Equal(t, i, <-c)
+		assert.Equal(t, i+3, <-c)
+	}
+
+	assert.True(t, unsub())
+	for _, c := range cs {
+		_, ok := <-c
+		assert.False(t, ok, "channels should be closed")
+	}
+	assert.False(t, bus.Has("test"))
+}
+
+func TestFanOutFull(t *testing.T) {
+	bus := NewBus()
+	cs, unsub := bus.FanOut("test", 2, 1)
+	defer unsub()
+
+	bus.Publish("test", 1)
+	<-cs[0]
+	bus.Publish("test", 2)
+	bus.Publish("test", 3)
+	assert.Equal(t, 3, <-cs[0], "full channel should be skipped")
+	assert.Equal(t, 2, <-cs[1])
+
+	bus.Publish("test", 4)
+	bus.Publish("test", 5)
+	bus.Publish("test", 6)
+	assert.Equal(t, uint64(1), bus.Stats()["test"].DroppedCount)
+}
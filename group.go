@@ -0,0 +1,66 @@
+package bus
+
+import (
+	"sync"
+)
+
+// Group tracks the subscriptions made through it, so that a component that
+// subscribes many handlers can unsubscribe them all at once. Its methods may
+// be called concurrently, including while values are being published.
+type Group struct {
+	bus    *Bus
+	lock   sync.Mutex
+	unsubs []UnsubscribeFunc
+	closed bool
+}
+
+// NewGroup returns a Group that subscribes handlers to this Bus.
+func (b *Bus) NewGroup() *Group {
+	return &Group{bus: b}
+}
+
+// NewGroup returns a Group that subscribes handlers to the default Bus.
+func NewGroup() *Group {
+	return getDefaultBus().NewGroup()
+}
+
+// Subscribe causes the passed Handler to be called when data is published to
+// the named topic on the group's Bus, until the group is closed. It returns a
+// function that can be called to unsubscribe the handler sooner. Once the
+// group has been closed, Subscribe does nothing.
+func (g *Group) Subscribe(topic interface{}, h Handler) UnsubscribeFunc {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.closed {
+		return func() bool { return false }
+	}
+	unsub := g.bus.Subscribe(topic, h)
+	g.unsubs = append(g.unsubs, unsub)
+	return unsub
+}
+
+// SubscribeFunc registers the handler function on the given topic of the
+// group's Bus, until the group is closed.
+func (g *Group) SubscribeFunc(topic interface{}, h func(b *Bus, t, v interface{})) UnsubscribeFunc {
+	hf := HandlerFunc(h)
+	return g.Subscribe(topic, &hf)
+}
+
+// Close unsubscribes every handler subscribed through the group, returning
+// the number of subscriptions it removed. Calling Close again does nothing.
+func (g *Group) Close() int {
+	g.lock.Lock()
+	unsubs := g.unsubs
+	g.unsubs = nil
+	g.closed = true
+	g.lock.Unlock()
+
+	n := 0
+	for _, unsub := range unsubs {
+		if unsub() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,61 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	bus := NewBus()
+	other := bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) {})
+	defer other()
+
+	g := bus.NewGroup()
+	g.SubscribeFunc("a", func(b *Bus, tp, v interface{}) {})
+	g.Subscribe("b", &mockHandler{})
+	unsub := g.SubscribeFunc("c", func(b *Bus, tp, v interface{}) {})
+	assert.True(t, unsub(), "subscriptions should be removable individually")
+
+	n, _ := bus.Publish("a", 1)
+	assert.Equal(t, 2, n)
+
+	assert.Equal(t, 2, g.Close())
+	assert.Equal(t, 0, g.Close(), "second close should do nothing")
+	n, _ = bus.Publish("a", 1)
+	assert.Equal(t, 1, n, "handlers outside the group should remain")
+	assert.False(t, bus.Has("b"))
+
+	g.SubscribeFunc("b", func(b *Bus, tp, v interface{}) {})
+	assert.False(t, bus.Has("b"), "closed group should not subscribe")
+}
+
+func TestGroupConcurrentClose(t *testing.T) {
+	bus := NewBus()
+	g := bus.NewGroup()
+	for i := 0; i < 10; i++ {
+		g.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	total := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			bus.Publish("test", 1)
+		}()
+		go func() {
+			defer wg.Done()
+			n := g.Close()
+			lock.Lock()
+			total += n
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, total)
+	assert.False(t, bus.Has("test"))
+}
@@ -0,0 +1,110 @@
+package bus
+
+import (
+	"sync"
+)
+
+// Subscription is a handle to a single subscription, allowing it to be
+// paused and resumed without losing its place among the topic's handlers.
+type Subscription struct {
+	lock   sync.Mutex
+	paused bool
+	bus    *Bus
+	id     SubscriptionID
+	unsub  UnsubscribeFunc
+	h      Handler
+}
+
+func (s *Subscription) accept(b *Bus, t, v interface{}) bool {
+	s.lock.Lock()
+	paused := s.paused
+	s.lock.Unlock()
+
+	if paused {
+		return false
+	}
+	if a, ok := s.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (s *Subscription) On(b *Bus, t, v interface{}) {
+	s.OnErr(b, t, v)
+}
+
+func (s *Subscription) OnErr(b *Bus, t, v interface{}) error {
+	return call(b, s.h, t, v)
+}
+
+func (s *Subscription) unwrap() Handler {
+	return s.h
+}
+
+// ID returns the identifier of the subscription, as accepted by
+// UnsubscribeID.
+func (s *Subscription) ID() SubscriptionID {
+	return s.id
+}
+
+// Pause stops the handler being called until Resume is called. Values
+// published while the subscription is paused are not delivered to the
+// handler, nor counted as deliveries by Publish.
+func (s *Subscription) Pause() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.paused = true
+}
+
+// Resume causes the handler to be called again after Pause.
+func (s *Subscription) Resume() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.paused = false
+}
+
+// Paused reports whether the subscription is paused.
+func (s *Subscription) Paused() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.paused
+}
+
+// Unsubscribe removes the subscription, returning true if it was still
+// subscribed.
+func (s *Subscription) Unsubscribe() bool {
+	return s.unsub()
+}
+
+// IsActive reports whether the subscription is still in place, without
+// changing it. It returns false once the subscription has been removed by
+// Unsubscribe or by any other means, such as UnsubscribeID or RemoveTopic.
+// A paused subscription is still active.
+func (s *Subscription) IsActive() bool {
+	s.bus.lock.RLock()
+	defer s.bus.lock.RUnlock()
+
+	_, ok := s.bus.ids[s.id]
+	return ok
+}
+
+// SubscribeHandle causes the passed Handler to be called when data is
+// published to the named topic on this Bus, returning a handle through which
+// the subscription can be paused, resumed and removed.
+func (b *Bus) SubscribeHandle(topic interface{}, h Handler) *Subscription {
+	sh := &Subscription{bus: b, h: h}
+
+	b.lock.Lock()
+	defer b.unlock()
+	s := b.subscribeLocked(topic, sh)
+	sh.id = s.id
+	sh.unsub = b.unsubscribeFunc(s)
+	return sh
+}
+
+// SubscribeHandle causes the passed Handler to be called when data is
+// published to the named topic on the default Bus, returning a handle
+// through which the subscription can be paused, resumed and removed.
+func SubscribeHandle(topic interface{}, h Handler) *Subscription {
+	return getDefaultBus().SubscribeHandle(topic, h)
+}
@@ -0,0 +1,80 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeHandle(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	s := bus.SubscribeHandle("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+
+	n, _ := bus.Publish("test", 1)
+	assert.Equal(t, 1, n)
+
+	s.Pause()
+	assert.True(t, s.Paused())
+	n, _ = bus.Publish("test", 2)
+	assert.Equal(t, 0, n, "paused handler should not be counted")
+	assert.Equal(t, 1, bus.SubscriberCount("test"), "paused handler should stay subscribed")
+
+	s.Resume()
+	n, _ = bus.Publish("test", 3)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{1, 3}, got)
+
+	assert.True(t, s.Unsubscribe())
+	assert.False(t, s.Unsubscribe())
+	assert.Equal(t, 0, bus.SubscriberCount("test"))
+}
+
+func TestSubscribeHandleID(t *testing.T) {
+	bus := NewBus()
+	s := bus.SubscribeHandle("test", HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+	assert.True(t, bus.UnsubscribeID(s.ID()))
+	assert.False(t, s.Unsubscribe())
+}
+
+func TestSubscribeHandleIsActive(t *testing.T) {
+	bus := NewBus()
+	s := bus.SubscribeHandle("test", HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+	assert.True(t, s.IsActive())
+	assert.True(t, s.IsActive(), "checking should not unsubscribe")
+
+	s.Pause()
+	assert.True(t, s.IsActive(), "paused subscription should be active")
+	assert.True(t, s.Unsubscribe())
+	assert.False(t, s.IsActive())
+
+	s = bus.SubscribeHandle("test", HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+	bus.RemoveTopic("test")
+	assert.False(t, s.IsActive(), "removal by other means should be seen")
+}
+
+func TestSubscribeHandleConcurrent(t *testing.T) {
+	bus := NewBus()
+	s := bus.SubscribeHandle("test", HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			s.Pause()
+			s.Resume()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			bus.Publish("test", i, Async)
+		}
+	}()
+	wg.Wait()
+	bus.Drain()
+}
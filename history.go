@@ -0,0 +1,208 @@
+package bus
+
+import (
+	"sync"
+)
+
+// history is a ring buffer of the last values published to a topic.
+type history struct {
+	values []interface{}
+	next   int
+	full   bool
+}
+
+// add records the value, discarding the oldest if the history is full.
+func (h *history) add(v interface{}) {
+	h.values[h.next] = v
+	if h.next++; h.next == len(h.values) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// resize returns a history recording the last n values, keeping as many of
+// the values already recorded as fit, or nil if n is not positive. The
+// history itself is returned if it is already of that size.
+func (h *history) resize(n int) *history {
+	if n <= 0 {
+		return nil
+	}
+	if h != nil && len(h.values) == n {
+		return h
+	}
+	nh := &history{values: make([]interface{}, n)}
+	if h != nil {
+		vs := h.snapshot()
+		if len(vs) > n {
+			vs = vs[len(vs)-n:]
+		}
+		for _, v := range vs {
+			nh.add(v)
+		}
+	}
+	return nh
+}
+
+// snapshot returns a copy of the recorded values, oldest first.
+func (h *history) snapshot() []interface{} {
+	if !h.full {
+		return append([]interface{}(nil), h.values[:h.next]...)
+	}
+	vs := make([]interface{}, 0, len(h.values))
+	vs = append(vs, h.values[h.next:]...)
+	return append(vs, h.values[:h.next]...)
+}
+
+// last returns the most recently recorded value, or false if there is none.
+func (h *history) last() (interface{}, bool) {
+	if !h.full && h.next == 0 {
+		return nil, false
+	}
+	i := h.next - 1
+	if i < 0 {
+		i = len(h.values) - 1
+	}
+	return h.values[i], true
+}
+
+// replayHandler holds back the values it receives while older values are
+// being replayed to its handler, so that the handler receives all of them in
+// order.
+type replayHandler struct {
+	lock      sync.Mutex
+	replaying bool
+	pending   []interface{}
+	h         Handler
+}
+
+func (h *replayHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *replayHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *replayHandler) OnErr(b *Bus, t, v interface{}) error {
+	h.lock.Lock()
+	if h.replaying {
+		h.pending = append(h.pending, v)
+		h.lock.Unlock()
+		return nil
+	}
+	h.lock.Unlock()
+	return call(b, h.h, t, v)
+}
+
+func (h *replayHandler) unwrap() Handler {
+	return h.h
+}
+
+// replay delivers each of the values it accepts to the handler, followed by
+// any values held back meanwhile, which were accepted when they were
+// published. Values are delivered as by Publish, so that the Bus's clone
+// function and PanicPolicy apply, and values published after a panic are no
+// longer held back.
+func (h *replayHandler) replay(b *Bus, t interface{}, vs []interface{}) {
+	defer func() {
+		h.lock.Lock()
+		h.replaying = false
+		h.lock.Unlock()
+	}()
+
+	for _, v := range vs {
+		if h.accept(b, t, v) {
+			b.Deliver(h.h, t, v)
+		}
+	}
+	for {
+		h.lock.Lock()
+		vs, h.pending = h.pending, nil
+		if len(vs) == 0 {
+			h.replaying = false
+			h.lock.Unlock()
+			return
+		}
+		h.lock.Unlock()
+
+		for _, v := range vs {
+			b.Deliver(h.h, t, v)
+		}
+	}
+}
+
+// SubscribeReplay causes the passed Handler to be called when data is
+// published to the named topic on this Bus, as Subscribe does, after first
+// calling it with each value recorded by the topic's history, oldest first.
+// The replay is atomic with the subscription, so no value is missed or
+// received twice at the boundary. Values published during the replay are
+// held back and passed to the handler once it completes, in order. Topics
+// without a history, configured by WithHistory, replay nothing.
+func (b *Bus) SubscribeReplay(topic interface{}, h Handler) UnsubscribeFunc {
+	rh := &replayHandler{replaying: true, h: h}
+
+	s, vs := b.subscribeHistory(topic, rh)
+
+	// Handlers subscribed through a namespace are passed it and the topic
+	// relative to it, both of which are those passed in
+	rh.replay(b, topic, vs)
+	return b.unsubscribeFunc(s)
+}
+
+// subscribeHistory subscribes h to the topic as Subscribe does, returning the
+// subscription along with the values recorded by the topic's history, if it
+// has one, as of the moment h was subscribed.
+func (b *Bus) subscribeHistory(topic interface{}, h Handler) (*subscription, []interface{}) {
+	b.lock.Lock()
+	defer b.unlock()
+
+	s := b.subscribeLocked(topic, h)
+	if st := b.states[s.topic]; st.history != nil {
+		return s, st.history.snapshot()
+	}
+	return s, nil
+}
+
+// PeekLast returns the newest value recorded by the history of the named
+// topic on this Bus, without subscribing to it. It returns false if the topic
+// has no history, configured by WithHistory, or nothing has been recorded by
+// it. If the Bus was created with WithCloneFunc, a clone of the value is
+// returned.
+func (b *Bus) PeekLast(topic interface{}) (interface{}, bool) {
+	if !b.validTopic(topic) {
+		return nil, false
+	}
+	v, ok := b.lastRecorded(b.qualify(topic))
+	if ok && b.clone != nil {
+		v = b.clone(v)
+	}
+	return v, ok
+}
+
+// lastRecorded returns the newest value recorded by the topic's history, if
+// any.
+func (b *Bus) lastRecorded(topic interface{}) (interface{}, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if st := b.states[topic]; st != nil && st.history != nil {
+		return st.history.last()
+	}
+	return nil, false
+}
+
+// PeekLast returns the newest value recorded by the history of the named
+// topic on the default Bus, without subscribing to it.
+func PeekLast(topic interface{}) (interface{}, bool) {
+	return getDefaultBus().PeekLast(topic)
+}
+
+// SubscribeReplay causes the passed Handler to be called with each value
+// recorded by the history of the named topic on the default Bus, and then
+// whenever data is published to it.
+func SubscribeReplay(topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeReplay(topic, h)
+}
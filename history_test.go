@@ -0,0 +1,187 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	h := &history{values: make([]interface{}, 3)}
+	assert.Empty(t, h.snapshot())
+	h.add(1)
+	h.add(2)
+	assert.Equal(t, []interface{}{1, 2}, h.snapshot())
+	h.add(3)
+	h.add(4)
+	assert.Equal(t, []interface{}{2, 3, 4}, h.snapshot())
+}
+
+func TestHistoryLast(t *testing.T) {
+	h := &history{values: make([]interface{}, 2)}
+	_, ok := h.last()
+	assert.False(t, ok)
+	for i := 1; i <= 3; i++ {
+		h.add(i)
+		v, ok := h.last()
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+}
+
+func TestHistoryResize(t *testing.T) {
+	var h *history
+	assert.Nil(t, h.resize(0))
+	h = h.resize(2)
+	h.add(1)
+	h.add(2)
+	assert.Same(t, h, h.resize(2))
+	assert.Equal(t, []interface{}{1, 2}, h.resize(3).snapshot())
+	assert.Equal(t, []interface{}{2}, h.resize(1).snapshot())
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	bus := NewBus(WithHistory("test", 2))
+	for i := 1; i <= 3; i++ {
+		bus.Publish("test", i)
+	}
+
+	var got []interface{}
+	bus.SubscribeReplay("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	assert.Equal(t, []interface{}{2, 3}, got, "last values should be replayed oldest first")
+
+	bus.Publish("test", 4)
+	assert.Equal(t, []interface{}{2, 3, 4}, got)
+}
+
+// acceptCounter records how many times it is asked to accept a value, and
+// republishes the first value it is passed.
+type acceptCounter struct {
+	accepts int
+	got     []interface{}
+}
+
+func (h *acceptCounter) accept(b *Bus, t, v interface{}) bool {
+	h.accepts++
+	return true
+}
+
+func (h *acceptCounter) On(b *Bus, t, v interface{}) {
+	h.got = append(h.got, v)
+	if len(h.got) == 1 {
+		b.Publish(t, 2)
+	}
+}
+
+func TestSubscribeReplayHeldBack(t *testing.T) {
+	bus := NewBus(WithHistory("test", 1))
+	bus.Publish("test", 1)
+
+	h := &acceptCounter{}
+	bus.SubscribeReplay("test", h)
+	assert.Equal(t, []interface{}{1, 2}, h.got)
+	assert.Equal(t, 2, h.accepts, "held back values should only be accepted once")
+}
+
+func TestSubscribeReplayNoHistory(t *testing.T) {
+	bus := NewBus()
+	bus.Publish("test", 1)
+
+	var got []interface{}
+	bus.SubscribeReplay("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	assert.Empty(t, got)
+}
+
+func TestSubscribeReplayNamespace(t *testing.T) {
+	bus := NewBus(WithHistory("ns.test", 2))
+	bus.Publish("ns.test", 1)
+
+	ns := bus.Namespace("ns")
+	var topics []interface{}
+	var buses []*Bus
+	ns.SubscribeReplay("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		topics = append(topics, tp)
+		buses = append(buses, b)
+	}))
+	bus.Publish("ns.test", 2)
+
+	assert.Equal(t, []interface{}{"test", "test"}, topics)
+	assert.Equal(t, []*Bus{ns, ns}, buses)
+}
+
+func TestSubscribeReplayConcurrent(t *testing.T) {
+	bus := NewBus(WithHistory("test", 1000))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			bus.Publish("test", i)
+		}
+	}()
+
+	var lock sync.Mutex
+	var got []interface{}
+	bus.SubscribeReplay("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		lock.Lock()
+		got = append(got, v)
+		lock.Unlock()
+	}))
+	wg.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if assert.Len(t, got, 1000, "no value should be missed or duplicated") {
+		for i, v := range got {
+			assert.Equal(t, i, v, "values should arrive in order")
+		}
+	}
+}
+
+func TestPeekLast(t *testing.T) {
+	bus := NewBus(WithHistory("test", 2))
+	_, ok := bus.PeekLast("test")
+	assert.False(t, ok, "nothing has been recorded yet")
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+	bus.Publish("test", 3)
+	v, ok := bus.PeekLast("test")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+
+	bus.Publish("other", 4)
+	_, ok = bus.PeekLast("other")
+	assert.False(t, ok, "topics without a history should have no value")
+}
+
+func TestSubscribeReplayDeliver(t *testing.T) {
+	type item struct{ N int }
+	bus := NewBus(WithHistory("test", 2), WithCloneFunc(func(v interface{}) interface{} {
+		c := *v.(*item)
+		return &c
+	}))
+	published := &item{N: 1}
+	bus.Publish("test", published)
+
+	var got []*item
+	assert.Panics(t, func() {
+		bus.SubscribeReplay("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+			got = append(got, v.(*item))
+			if len(got) == 1 {
+				panic("boom")
+			}
+		}))
+	})
+	assert.NotSame(t, published, got[0], "replayed values should be cloned")
+
+	bus.Publish("test", &item{N: 2})
+	assert.Len(t, got, 2, "values published after a panic should not be held back")
+	assert.Equal(t, 2, got[1].N)
+}
@@ -0,0 +1,72 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// topicReport is the JSON description of a topic served by StatsHandler.
+type topicReport struct {
+	Topic       string `json:"topic"`
+	Subscribers int    `json:"subscribers"`
+	Published   uint64 `json:"published"`
+	Delivered   uint64 `json:"delivered"`
+	Dropped     uint64 `json:"dropped"`
+}
+
+// statsReport is the JSON document served by StatsHandler.
+type statsReport struct {
+	Topics           []topicReport `json:"topics"`
+	TotalSubscribers int           `json:"total_subscribers"`
+}
+
+// StatsHandler returns an http.Handler that serves a JSON snapshot of the
+// given Bus on GET: each topic that has handlers or has been published to,
+// ordered by name, with its number of subscribers and its Stats, along with
+// the total number of subscribers. Topics are named by their string
+// representations. Methods other than GET and HEAD are refused.
+func StatsHandler(b *Bus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(b.statsReport()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// statsReport takes a snapshot of the Bus for StatsHandler.
+func (b *Bus) statsReport() statsReport {
+	reports := map[interface{}]*topicReport{}
+	report := func(t interface{}) *topicReport {
+		r := reports[t]
+		if r == nil {
+			r = &topicReport{Topic: fmt.Sprint(t)}
+			reports[t] = r
+		}
+		return r
+	}
+	for t, st := range b.Stats() {
+		r := report(t)
+		r.Published, r.Delivered, r.Dropped = st.PublishCount, st.DeliverCount, st.DroppedCount
+	}
+	for _, d := range b.Describe() {
+		report(d.Topic).Subscribers = d.HandlerCount
+	}
+
+	sr := statsReport{Topics: make([]topicReport, 0, len(reports)), TotalSubscribers: b.TotalSubscribers()}
+	for _, r := range reports {
+		sr.Topics = append(sr.Topics, *r)
+	}
+	sort.Slice(sr.Topics, func(i, j int) bool {
+		return sr.Topics[i].Topic < sr.Topics[j].Topic
+	})
+	return sr
+}
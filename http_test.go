@@ -0,0 +1,38 @@
+package bus
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsHandler(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("b", &mockHandler{})
+	bus.Subscribe("b", &mockHandler{})
+	bus.Publish("b", 1)
+	bus.Publish("a", 1)
+
+	rec := httptest.NewRecorder()
+	StatsHandler(bus).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var got statsReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, statsReport{
+		Topics: []topicReport{
+			{Topic: "a", Published: 1},
+			{Topic: "b", Subscribers: 2, Published: 1, Delivered: 2},
+		},
+		TotalSubscribers: 2,
+	}, got)
+
+	rec = httptest.NewRecorder()
+	StatsHandler(bus).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/stats", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+}
@@ -0,0 +1,97 @@
+package bus
+
+import (
+	"sync/atomic"
+)
+
+// limitHandler passes on at most a fixed number of values, removing its own
+// subscription once the last has been accepted.
+type limitHandler struct {
+	remaining atomic.Int64
+	id        SubscriptionID
+	h         Handler
+}
+
+func (h *limitHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok && !a.accept(b, t, v) {
+		return false
+	}
+	// Claim a delivery before the handler is dispatched, so that racing
+	// publishes can never exceed the budget
+	n := h.remaining.Add(-1)
+	if n == 0 {
+		b.UnsubscribeID(h.id)
+	}
+	return n >= 0
+}
+
+func (h *limitHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *limitHandler) OnErr(b *Bus, t, v interface{}) error {
+	return call(b, h.h, t, v)
+}
+
+func (h *limitHandler) unwrap() Handler {
+	return h.h
+}
+
+// SubscribeN causes the passed Handler to be called for at most the next n
+// values published to the named topic on this Bus, after which it is
+// unsubscribed. The limit holds even when values are published concurrently
+// or with the Async flag. The returned function unsubscribes the handler
+// before its budget is used up. If n is not positive, the handler is never
+// called and no subscription is made.
+func (b *Bus) SubscribeN(topic interface{}, n int, h Handler) UnsubscribeFunc {
+	if n <= 0 {
+		return func() bool { return false }
+	}
+	lh := &limitHandler{h: h}
+	lh.remaining.Store(int64(n))
+
+	b.lock.Lock()
+	defer b.unlock()
+	s := b.subscribeLocked(topic, lh)
+	lh.id = s.id
+	return b.unsubscribeFunc(s)
+}
+
+// OnceN is SubscribeN under the name of the other one-shot subscriptions: the
+// passed Handler is called for at most the next n values published to the
+// named topic on this Bus, after which it is unsubscribed.
+func (b *Bus) OnceN(topic interface{}, n int, h Handler) UnsubscribeFunc {
+	return b.SubscribeN(topic, n, h)
+}
+
+// OnceFilter causes the passed Handler to be called with the first value
+// published to the named topic on this Bus that satisfies the filter, after
+// which it is unsubscribed. Values that do not satisfy the filter are
+// ignored. As with SubscribeN, the handler is called exactly once even when
+// values are published concurrently or with the Async flag. The returned
+// function unsubscribes the handler before a value is matched.
+func (b *Bus) OnceFilter(topic interface{}, filter func(v interface{}) bool, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	return b.SubscribeN(topic, 1, &filterHandler{filter: filter, h: h})
+}
+
+// SubscribeN causes the passed Handler to be called for at most the next n
+// values published to the named topic on the default Bus, after which it is
+// unsubscribed.
+func SubscribeN(topic interface{}, n int, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeN(topic, n, h)
+}
+
+// OnceN causes the passed Handler to be called for at most the next n values
+// published to the named topic on the default Bus, after which it is
+// unsubscribed.
+func OnceN(topic interface{}, n int, h Handler) UnsubscribeFunc {
+	return getDefaultBus().OnceN(topic, n, h)
+}
+
+// OnceFilter causes the passed Handler to be called with the first value
+// published to the named topic on the default Bus that satisfies the filter,
+// after which it is unsubscribed.
+func OnceFilter(topic interface{}, filter func(v interface{}) bool, h Handler) UnsubscribeFunc {
+	return getDefaultBus().OnceFilter(topic, filter, h)
+}
@@ -0,0 +1,161 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeN(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.SubscribeN("test", 3, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+
+	for i := 0; i < 5; i++ {
+		bus.Publish("test", i)
+	}
+	assert.Equal(t, []interface{}{0, 1, 2}, got)
+	assert.Equal(t, 0, bus.SubscriberCount("test"), "handler should unsubscribe itself")
+}
+
+func TestSubscribeNOnce(t *testing.T) {
+	bus := NewBus()
+	count := 0
+	bus.SubscribeN("test", 1, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		count++
+	}))
+
+	n, _ := bus.Publish("test", 1)
+	assert.Equal(t, 1, n)
+	n, _ = bus.Publish("test", 2)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 1, count)
+}
+
+func TestSubscribeNCancel(t *testing.T) {
+	bus := NewBus()
+	count := 0
+	unsub := bus.SubscribeN("test", 3, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		count++
+	}))
+
+	bus.Publish("test", 1)
+	assert.True(t, unsub())
+	bus.Publish("test", 2)
+	assert.Equal(t, 1, count)
+	assert.False(t, unsub(), "budget should only be cancelled once")
+}
+
+func TestSubscribeNConcurrent(t *testing.T) {
+	bus := NewBus()
+	var count atomic.Int64
+	bus.SubscribeN("test", 10, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		count.Add(1)
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				bus.Publish("test", j, Async)
+			}
+		}()
+	}
+	wg.Wait()
+	bus.Drain()
+
+	assert.Equal(t, int64(10), count.Load(), "handler should never exceed its budget")
+}
+
+func TestOnceNDefault(t *testing.T) {
+	cnt := 0
+	defer OnceN("test", 2, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		cnt++
+	}))()
+
+	for i := 0; i < 3; i++ {
+		Publish("test", i)
+	}
+	assert.Equal(t, 2, cnt)
+
+	unsub := OnceN("test", 2, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		cnt++
+	}))
+	assert.True(t, unsub())
+	n, _ := Publish("test", 3)
+	assert.Equal(t, 0, n)
+}
+
+func TestOnceFilterDefault(t *testing.T) {
+	var got []interface{}
+	defer OnceFilter("status", func(v interface{}) bool { return v == "ready" }, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))()
+
+	for _, v := range []string{"starting", "ready", "ready"} {
+		Publish("status", v)
+	}
+	assert.Equal(t, []interface{}{"ready"}, got)
+
+	unsub := OnceFilter("status", func(v interface{}) bool { return true }, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	assert.True(t, unsub())
+	Publish("status", "ready")
+	assert.Len(t, got, 1)
+}
+
+func TestOnceFilter(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.OnceFilter("status", func(v interface{}) bool { return v == "ready" }, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+
+	bus.Publish("status", "starting")
+	assert.Equal(t, 1, bus.SubscriberCount("status"), "non-matching values should not unsubscribe")
+	bus.Publish("status", "ready")
+	bus.Publish("status", "ready")
+	assert.Equal(t, []interface{}{"ready"}, got)
+	assert.Equal(t, 0, bus.SubscriberCount("status"))
+}
+
+func TestOnceFilterCancel(t *testing.T) {
+	bus := NewBus()
+	called := false
+	unsub := bus.OnceFilter("test", func(v interface{}) bool { return true }, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		called = true
+	}))
+	assert.True(t, unsub())
+	bus.Publish("test", 1)
+	assert.False(t, called)
+}
+
+func TestOnceFilterConcurrent(t *testing.T) {
+	bus := NewBus()
+	var count atomic.Int64
+	bus.OnceFilter("test", func(v interface{}) bool { return v.(int)%2 == 0 }, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		count.Add(1)
+	}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				bus.Publish("test", j, Async)
+			}
+		}()
+	}
+	wg.Wait()
+	bus.Drain()
+
+	assert.Equal(t, int64(1), count.Load())
+}
@@ -0,0 +1,49 @@
+package bus
+
+import (
+	"sort"
+	"strings"
+)
+
+// TopicMatcher decides which subscribed topics a published topic is
+// delivered to, for Buses created with WithMatcher. Match is called while
+// the Bus is locked, so it must not use the Bus.
+type TopicMatcher interface {
+	// Match reports whether values published to the topic published should
+	// be delivered to handlers subscribed to the topic subscribed.
+	Match(subscribed, published interface{}) bool
+}
+
+// CaseInsensitiveMatcher is a TopicMatcher that matches string topics
+// regardless of case, and other topics only if they are equal.
+type CaseInsensitiveMatcher struct{}
+
+// Match reports whether the topics are equal, ignoring the case of strings.
+func (CaseInsensitiveMatcher) Match(subscribed, published interface{}) bool {
+	s, ok1 := subscribed.(string)
+	p, ok2 := published.(string)
+	if ok1 && ok2 {
+		return strings.EqualFold(s, p)
+	}
+	return subscribed == published
+}
+
+// matchLocked returns the subscriptions of every topic the Bus's matcher
+// matches to the published topic, in the order they were made. It must be
+// called with the lock held.
+func (b *Bus) matchLocked(topic interface{}) []*subscription {
+	var ss []*subscription
+	matched := 0
+	for t, ts := range b.topics {
+		if b.matcher.Match(t, topic) {
+			ss = append(ss, ts...)
+			matched++
+		}
+	}
+	if matched > 1 {
+		sort.Slice(ss, func(i, j int) bool {
+			return ss[i].id < ss[j].id
+		})
+	}
+	return ss
+}
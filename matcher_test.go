@@ -0,0 +1,37 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseInsensitiveMatcher(t *testing.T) {
+	m := CaseInsensitiveMatcher{}
+	assert.True(t, m.Match("User.Signup", "user.signup"))
+	assert.False(t, m.Match("user.signup", "user.signout"))
+	assert.True(t, m.Match(42, 42))
+	assert.False(t, m.Match(42, "42"))
+}
+
+func TestWithMatcher(t *testing.T) {
+	bus := NewBus(WithMatcher(CaseInsensitiveMatcher{}))
+	var got []interface{}
+	bus.SubscribeFunc("user.signup", func(b *Bus, tp, v interface{}) {
+		got = append(got, "lower")
+	})
+	bus.SubscribeFunc("User.Signup", func(b *Bus, tp, v interface{}) {
+		got = append(got, "title")
+	})
+	bus.SubscribeFunc("user.signout", func(b *Bus, tp, v interface{}) {
+		got = append(got, "other")
+	})
+
+	n, err := bus.Publish("USER.SIGNUP", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []interface{}{"lower", "title"}, got, "handlers should be called in subscription order")
+
+	n, _ = NewBus().Publish("USER.SIGNUP", 1)
+	assert.Equal(t, 0, n, "topics should match exactly by default")
+}
@@ -0,0 +1,98 @@
+package bus
+
+import (
+	"sync"
+)
+
+// mergeBuffer is the size of the channel returned by Merge.
+const mergeBuffer = 64
+
+// TopicValue is a value received from a topic.
+type TopicValue struct {
+	Topic interface{}
+	Value interface{}
+}
+
+// mergeHandler forwards the values it receives from several topics onto a
+// single channel, tagged with their topics.
+type mergeHandler struct {
+	lock   sync.Mutex
+	c      chan TopicValue
+	closed bool
+}
+
+func (h *mergeHandler) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	select {
+	case h.c <- TopicValue{Topic: t, Value: v}:
+	default:
+		b.state(b.qualify(t)).stats.drop(1)
+	}
+}
+
+func (h *mergeHandler) TryOn(b *Bus, t, v interface{}) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.closed {
+		return false
+	}
+
+	select {
+	case h.c <- TopicValue{Topic: t, Value: v}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *mergeHandler) close() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.closed {
+		h.closed = true
+		close(h.c)
+	}
+}
+
+// Merge subscribes to each of the named topics on this Bus, returning a
+// channel that receives every value published to them, tagged with the topic
+// it was published to, and a function that unsubscribes from all of the
+// topics and closes the channel.
+//
+// The channel has a buffer of 64 values. As with SubscribeChan, sends never
+// block: values published while the buffer is full are dropped, and counted
+// in the Stats of their topic.
+func (b *Bus) Merge(topics ...interface{}) (<-chan TopicValue, UnsubscribeFunc) {
+	h := &mergeHandler{c: make(chan TopicValue, mergeBuffer)}
+
+	unsubs := make([]UnsubscribeFunc, len(topics))
+	for i, t := range topics {
+		unsubs[i] = b.Subscribe(t, h)
+	}
+
+	return h.c, func() bool {
+		ok := false
+		for _, unsub := range unsubs {
+			if unsub() {
+				ok = true
+			}
+		}
+		h.close()
+		return ok
+	}
+}
+
+// Merge subscribes to each of the named topics on the default Bus, returning
+// a channel that receives every value published to them, tagged with their
+// topic, and a function that unsubscribes and closes it.
+func Merge(topics ...interface{}) (<-chan TopicValue, UnsubscribeFunc) {
+	return getDefaultBus().Merge(topics...)
+}
@@ -0,0 +1,48 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	bus := NewBus()
+	c, unsub := bus.Merge("a", "b")
+
+	bus.Publish("a", 1)
+	bus.Publish("b", 2)
+	bus.Publish("c", 3)
+
+	assert.Equal(t, TopicValue{Topic: "a", Value: 1}, <-c)
+	assert.Equal(t, TopicValue{Topic: "b", Value: 2}, <-c)
+	assert.Len(t, c, 0, "unmerged topics should not be received")
+
+	assert.True(t, unsub())
+	assert.Equal(t, 0, bus.SubscriberCount("a"))
+	assert.Equal(t, 0, bus.SubscriberCount("b"))
+	_, ok := <-c
+	assert.False(t, ok, "channel should be closed")
+	assert.False(t, unsub())
+}
+
+func TestMergeFull(t *testing.T) {
+	bus := NewBus()
+	c, unsub := bus.Merge("a")
+	defer unsub()
+
+	for i := 0; i < mergeBuffer+3; i++ {
+		bus.Publish("a", i)
+	}
+	assert.Len(t, c, mergeBuffer)
+	assert.Equal(t, uint64(3), bus.Stats()["a"].DroppedCount)
+}
+
+func TestMergeNamespace(t *testing.T) {
+	bus := NewBus()
+	c, unsub := bus.Namespace("ns").Merge("a")
+	defer unsub()
+
+	bus.Publish("ns.a", 1)
+	assert.Equal(t, TopicValue{Topic: "a", Value: 1}, <-c)
+}
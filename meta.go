@@ -0,0 +1,113 @@
+package bus
+
+import (
+	"time"
+)
+
+// PublishMeta describes the publish of a value.
+type PublishMeta struct {
+	// Seq numbers each publish on the Bus, increasing with every publish.
+	// Concurrent publishes are given distinct numbers, though not
+	// necessarily in the order their handlers are called.
+	Seq uint64
+
+	// Time is the time at which the value was published.
+	Time time.Time
+
+	// Values is scratch space shared by the MetaHandlers called with the
+	// value, such as for passing a correlation ID from one handler to those
+	// after it. It is created empty for each publish to a topic and
+	// discarded afterwards. Handlers called concurrently, such as with the
+	// Async flag, must synchronize their own access to it.
+	Values map[interface{}]interface{}
+
+	// SourceSeq is the sequence number given by the publisher to PublishSeq,
+	// or zero for values published otherwise.
+	SourceSeq uint64
+
+	// OutOfOrder is set if SourceSeq was not greater than that of the
+	// previous value published to the topic with PublishSeq.
+	OutOfOrder bool
+
+	// OriginalTopic is the topic the value was published to, before any
+	// alias was followed. For values forwarded by Tee, SubscribeTransform or
+	// PipeTo, it is the topic the value was first published to. That is the
+	// topic the first of them was subscribed to, unless the value reached a
+	// MetaHandler there, in which case any alias it was published to is
+	// reported as well.
+	OriginalTopic interface{}
+
+	// MatchedTopic is the topic the handler was subscribed to that the
+	// publish matched: the pattern, parent topic or prefix for handlers
+	// subscribed to one, and otherwise the topic the value was delivered to.
+	MatchedTopic interface{}
+}
+
+// MetaHandler is a Handler that is also told about the publish of each value
+// it receives. The Bus calls OnMeta in place of On.
+//
+// Like every handler, a MetaHandler is passed the topic the value was
+// delivered to, after following aliases, as t, even if it was subscribed to a
+// pattern, parent topic or prefix matching it. The OriginalTopic and
+// MatchedTopic of the meta tell it how the value got there.
+type MetaHandler interface {
+	Handler
+
+	// OnMeta is called each time a value is received on a particular topic,
+	// along with details of its publish.
+	OnMeta(b *Bus, meta PublishMeta, t, v interface{})
+}
+
+// matchedHandler wraps a MetaHandler subscribed to a pattern, parent topic
+// or prefix, telling it which of them the publish matched.
+type matchedHandler struct {
+	topic interface{}
+	h     Handler
+}
+
+func (h *matchedHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *matchedHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *matchedHandler) OnErr(b *Bus, t, v interface{}) error {
+	if b.meta != nil {
+		meta := *b.meta
+		meta.MatchedTopic = h.topic
+		mb := *b
+		mb.meta = &meta
+		b = &mb
+	}
+	return call(b, h.h, t, v)
+}
+
+func (h *matchedHandler) unwrap() Handler {
+	return h.h
+}
+
+// wrapper is implemented by handlers that wrap another handler.
+type wrapper interface {
+	unwrap() Handler
+}
+
+// wantsMeta reports whether the handler, or any handler it wraps, is a
+// MetaHandler.
+func wantsMeta(h Handler) bool {
+	for h != nil {
+		if _, ok := h.(MetaHandler); ok {
+			return true
+		}
+		w, ok := h.(wrapper)
+		if !ok {
+			return false
+		}
+		h = w.unwrap()
+	}
+	return false
+}
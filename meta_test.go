@@ -0,0 +1,222 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// metaRecorder is a MetaHandler that records the metadata it is passed.
+type metaRecorder struct {
+	lock  sync.Mutex
+	metas []PublishMeta
+	plain int
+}
+
+func (h *metaRecorder) On(b *Bus, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.plain++
+}
+
+func (h *metaRecorder) OnMeta(b *Bus, meta PublishMeta, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.metas = append(h.metas, meta)
+}
+
+func TestMetaHandler(t *testing.T) {
+	bus := NewBus()
+	h := &metaRecorder{}
+	bus.Subscribe("test", h)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+
+	before := time.Now()
+	bus.Publish("test", 1)
+	bus.Publish("other", 2)
+	bus.Publish("test", 3)
+
+	assert.Equal(t, 0, h.plain, "On should not be called")
+	if assert.Len(t, h.metas, 2) {
+		assert.True(t, h.metas[0].Seq < h.metas[1].Seq, "sequence should increase")
+		assert.False(t, h.metas[0].Time.Before(before))
+		assert.False(t, h.metas[1].Time.Before(h.metas[0].Time))
+	}
+}
+
+func TestMetaHandlerWrapped(t *testing.T) {
+	bus := NewBus()
+	h := &metaRecorder{}
+	bus.Namespace("ns").SubscribeFilter("test", func(v interface{}) bool { return true }, h)
+	bus.Publish("ns.test", 1, Async)
+	bus.Drain()
+
+	assert.Len(t, h.metas, 1, "wrapped handlers should receive metadata")
+	assert.Equal(t, 0, h.plain)
+}
+
+func TestMetaHandlerConcurrent(t *testing.T) {
+	bus := NewBus()
+	h := &metaRecorder{}
+	bus.Subscribe("test", h)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				bus.Publish("test", j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := map[uint64]bool{}
+	for _, m := range h.metas {
+		assert.False(t, seen[m.Seq], "sequence numbers should be distinct")
+		seen[m.Seq] = true
+	}
+	assert.Len(t, seen, 100)
+}
+
+// correlator is a MetaHandler that stores a correlation ID in the publish's
+// Values, for handlers after it to read.
+type correlator struct {
+	next int
+}
+
+func (h *correlator) On(b *Bus, t, v interface{}) {}
+
+func (h *correlator) OnMeta(b *Bus, meta PublishMeta, t, v interface{}) {
+	h.next++
+	meta.Values["correlation"] = h.next
+}
+
+func TestMetaValues(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("test", &correlator{})
+	h := &metaRecorder{}
+	bus.Subscribe("test", h)
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+
+	if assert.Len(t, h.metas, 2) {
+		assert.Equal(t, 1, h.metas[0].Values["correlation"], "values should be shared by a publish's handlers")
+		assert.Equal(t, 2, h.metas[1].Values["correlation"])
+		h.metas[0].Values["extra"] = true
+		assert.NotContains(t, h.metas[1].Values, "extra", "each publish should have its own values")
+	}
+}
+
+// topicRecorder is a MetaHandler that records the topics it is passed, as
+// t, OriginalTopic and MatchedTopic.
+type topicRecorder struct {
+	lock   sync.Mutex
+	topics [][3]interface{}
+}
+
+func (h *topicRecorder) On(b *Bus, t, v interface{}) {}
+
+func (h *topicRecorder) OnMeta(b *Bus, meta PublishMeta, t, v interface{}) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.topics = append(h.topics, [3]interface{}{t, meta.OriginalTopic, meta.MatchedTopic})
+}
+
+func TestMetaTopics(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      []BusOption
+		subscribe func(b *Bus, h Handler)
+		publish   func(b *Bus)
+		want      [3]interface{}
+	}{{
+		name:      "exact",
+		subscribe: func(b *Bus, h Handler) { b.Subscribe("a.b", h) },
+		publish:   func(b *Bus) { b.Publish("a.b", 1) },
+		want:      [3]interface{}{"a.b", "a.b", "a.b"},
+	}, {
+		name:      "all",
+		subscribe: func(b *Bus, h Handler) { b.SubscribeAll(h) },
+		publish:   func(b *Bus) { b.Publish("a.b", 1) },
+		want:      [3]interface{}{"a.b", "a.b", "a.b"},
+	}, {
+		name:      "pattern",
+		opts:      []BusOption{WithMatcher(CaseInsensitiveMatcher{})},
+		subscribe: func(b *Bus, h Handler) { b.Subscribe("A.B", h) },
+		publish:   func(b *Bus) { b.Publish("a.b", 1) },
+		want:      [3]interface{}{"a.b", "a.b", "A.B"},
+	}, {
+		name:      "prefix",
+		subscribe: func(b *Bus, h Handler) { b.SubscribePrefix("a.", h) },
+		publish:   func(b *Bus) { b.Publish("a.b", 1) },
+		want:      [3]interface{}{"a.b", "a.b", "a."},
+	}, {
+		name:      "bubble",
+		opts:      []BusOption{WithBubbling()},
+		subscribe: func(b *Bus, h Handler) { b.Subscribe("a", h) },
+		publish:   func(b *Bus) { b.Publish("a.b", 1) },
+		want:      [3]interface{}{"a.b", "a.b", "a"},
+	}, {
+		name:      "alias",
+		subscribe: func(b *Bus, h Handler) { b.Subscribe("a.b", h) },
+		publish: func(b *Bus) {
+			b.Alias("old", "a.b")
+			b.Publish("old", 1)
+		},
+		want: [3]interface{}{"a.b", "old", "a.b"},
+	}, {
+		name:      "tee",
+		subscribe: func(b *Bus, h Handler) { b.Subscribe("a.b", h) },
+		publish: func(b *Bus) {
+			b.Tee("src", "dst")
+			b.Tee("dst", "a.b")
+			b.Publish("src", 1)
+		},
+		want: [3]interface{}{"a.b", "src", "a.b"},
+	}, {
+		name:      "namespace",
+		opts:      []BusOption{WithBubbling()},
+		subscribe: func(b *Bus, h Handler) { b.Namespace("ns").Subscribe("a", h) },
+		publish:   func(b *Bus) { b.Publish("ns.a.b", 1) },
+		want:      [3]interface{}{"a.b", "a.b", "a"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			bus := NewBus(tc.opts...)
+			h := &topicRecorder{}
+			tc.subscribe(bus, h)
+			bus.SubscribeFunc("a.b", func(*Bus, interface{}, interface{}) {})
+			tc.publish(bus)
+			assert.Equal(t, [][3]interface{}{tc.want}, h.topics)
+		})
+	}
+}
+
+func TestMetaTopicsPipe(t *testing.T) {
+	a, b := NewBus(), NewBus()
+	h := &topicRecorder{}
+	b.Subscribe("test", h)
+	a.Pipe(b, "test")
+	a.Alias("old", "test")
+	a.Publish("old", 1)
+	assert.Equal(t, [][3]interface{}{{"test", "test", "test"}}, h.topics, "piped values should report the topic they were published to")
+}
+
+func TestMetaTopicsResolve(t *testing.T) {
+	bus := NewBus(WithBubbling())
+	h := &topicRecorder{}
+	bus.Subscribe("a", h)
+	assert.Equal(t, []Handler{h}, bus.Resolve("a.b"), "the handler should be returned as subscribed")
+}
+
+func TestMetaTopicsLoneHandler(t *testing.T) {
+	bus := NewBus(WithBubbling())
+	h := &topicRecorder{}
+	bus.Subscribe("a", h)
+	bus.Publish("a.b", 1)
+	assert.Equal(t, [][3]interface{}{{"a.b", "a.b", "a"}}, h.topics)
+}
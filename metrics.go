@@ -0,0 +1,51 @@
+package bus
+
+import (
+	"fmt"
+	"time"
+)
+
+// MetricsSink receives metrics about the values published on a Bus, for
+// export to a monitoring system. Topics are passed formatted with fmt.Sprint.
+// Its methods may be called concurrently.
+type MetricsSink interface {
+	// IncPublish is called each time a value is published to the topic.
+	IncPublish(topic string)
+
+	// ObserveHandlerDuration is called each time a handler of the topic
+	// returns, with the time it took.
+	ObserveHandlerDuration(topic string, d time.Duration)
+
+	// IncDropped is called each time a value published to the topic is
+	// declined or discarded by one of its handlers, as counted in the
+	// DroppedCount of its Stats.
+	IncDropped(topic string)
+}
+
+// bind causes the metrics of the given topic to be reported to sink as well
+// as being counted.
+func (s *topicStats) bind(sink MetricsSink, topic interface{}) {
+	s.sink = sink
+	s.name = fmt.Sprint(topic)
+}
+
+// publish counts a value published to the topic.
+func (s *topicStats) publish() {
+	s.published.Add(1)
+	if s.sink != nil {
+		s.sink.IncPublish(s.name)
+	}
+}
+
+// drop counts n values dropped by the topic's handlers.
+func (s *topicStats) drop(n uint64) {
+	if n == 0 {
+		return
+	}
+	s.dropped.Add(n)
+	if s.sink != nil {
+		for i := uint64(0); i < n; i++ {
+			s.sink.IncDropped(s.name)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingSink is a MetricsSink that counts the metrics it is passed.
+type recordingSink struct {
+	lock      sync.Mutex
+	published map[string]int
+	dropped   map[string]int
+	durations map[string]int
+}
+
+func newRecordingSink() *recordingSink {
+	return &recordingSink{
+		published: map[string]int{},
+		dropped:   map[string]int{},
+		durations: map[string]int{},
+	}
+}
+
+func (s *recordingSink) IncPublish(topic string) {
+	s.lock.Lock()
+	s.published[topic]++
+	s.lock.Unlock()
+}
+
+func (s *recordingSink) ObserveHandlerDuration(topic string, d time.Duration) {
+	s.lock.Lock()
+	s.durations[topic]++
+	s.lock.Unlock()
+}
+
+func (s *recordingSink) IncDropped(topic string) {
+	s.lock.Lock()
+	s.dropped[topic]++
+	s.lock.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	sink := newRecordingSink()
+	bus := NewBus(WithHistory("history", 1), WithMetrics(sink))
+
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	bus.SubscribeFilter("test", func(v interface{}) bool {
+		return v != 2
+	}, HandlerFunc(func(b *Bus, tp, v interface{}) {}))
+	bus.SubscribeFunc(42, func(b *Bus, tp, v interface{}) {})
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+	bus.Publish(42, 1, Async)
+	bus.Publish("history", 1)
+	bus.Drain()
+
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+	assert.Equal(t, map[string]int{"test": 2, "42": 1, "history": 1}, sink.published)
+	assert.Equal(t, map[string]int{"test": 1}, sink.dropped)
+	assert.Equal(t, map[string]int{"test": 3, "42": 1}, sink.durations)
+}
@@ -0,0 +1,69 @@
+package bus
+
+// namedHandler is a handler function identified by name, so that it can be
+// unsubscribed without the function returned by SubscribeNamedFunc.
+type namedHandler struct {
+	name string
+	fn   HandlerFunc
+}
+
+func (h *namedHandler) On(b *Bus, t, v interface{}) {
+	h.fn(b, t, v)
+}
+
+// SubscribeNamedFunc registers the handler function on the given topic under
+// the given name, returning a function that can be called to deregister it.
+// The function can also be deregistered from anywhere with UnsubscribeNamed,
+// given the topic and name. Subscribing a function under a name already in
+// use on the topic replaces the earlier function, as if it had been
+// unsubscribed first, so a name is subscribed at most once per topic.
+func (b *Bus) SubscribeNamedFunc(topic interface{}, name string, fn func(b *Bus, t, v interface{})) UnsubscribeFunc {
+	if fn == nil {
+		panic(ErrNilHandler.Error())
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	if s := b.namedLocked(topic, name); s != nil {
+		b.removeLocked(s)
+	}
+	return b.unsubscribeFunc(b.subscribeLocked(topic, &namedHandler{name: name, fn: fn}))
+}
+
+// UnsubscribeNamed removes the handler function subscribed to the given topic
+// on this Bus under the given name by SubscribeNamedFunc, returning true if
+// it was found and removed.
+func (b *Bus) UnsubscribeNamed(topic interface{}, name string) bool {
+	b.lock.Lock()
+	defer b.unlock()
+
+	if s := b.namedLocked(topic, name); s != nil {
+		return b.removeLocked(s)
+	}
+	return false
+}
+
+// namedLocked returns the subscription of the named handler function to the
+// topic, or nil if there is none. It must be called with the lock held.
+func (b *Bus) namedLocked(topic interface{}, name string) *subscription {
+	for _, s := range b.topics[b.qualify(topic)] {
+		if nh, ok := s.subscribed().(*namedHandler); ok && nh.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// SubscribeNamedFunc registers the handler function on the given topic of the
+// default Bus under the given name, replacing any function already
+// registered under it.
+func SubscribeNamedFunc(topic interface{}, name string, fn func(b *Bus, t, v interface{})) UnsubscribeFunc {
+	return getDefaultBus().SubscribeNamedFunc(topic, name, fn)
+}
+
+// UnsubscribeNamed removes the handler function registered on the given topic
+// of the default Bus under the given name.
+func UnsubscribeNamed(topic interface{}, name string) bool {
+	return getDefaultBus().UnsubscribeNamed(topic, name)
+}
@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeNamedFunc(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	record := func(name string) func(b *Bus, tp, v interface{}) {
+		return func(b *Bus, tp, v interface{}) {
+			got = append(got, name)
+		}
+	}
+	bus.SubscribeNamedFunc("test", "logger", record("logger"))
+	bus.SubscribeNamedFunc("test", "metrics", record("metrics"))
+	bus.SubscribeNamedFunc("other", "logger", record("other"))
+
+	n, _ := bus.Publish("test", 1)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"logger", "metrics"}, got)
+
+	// Removed elsewhere by name alone
+	assert.True(t, bus.UnsubscribeNamed("test", "logger"))
+	assert.False(t, bus.UnsubscribeNamed("test", "logger"))
+	got = nil
+	bus.Publish("test", 1)
+	assert.Equal(t, []string{"metrics"}, got)
+	assert.Equal(t, 1, bus.SubscriberCount("other"), "names should be scoped to their topic")
+}
+
+func TestSubscribeNamedFuncReplace(t *testing.T) {
+	bus := NewBus()
+	var got []string
+	unsub := bus.SubscribeNamedFunc("test", "h", func(b *Bus, tp, v interface{}) {
+		got = append(got, "old")
+	})
+	bus.SubscribeNamedFunc("test", "h", func(b *Bus, tp, v interface{}) {
+		got = append(got, "new")
+	})
+
+	n, _ := bus.Publish("test", 1)
+	assert.Equal(t, 1, n, "name should be subscribed once")
+	assert.Equal(t, []string{"new"}, got)
+	assert.False(t, unsub(), "replaced function should already be unsubscribed")
+
+	ns := bus.Namespace("ns")
+	ns.SubscribeNamedFunc("test", "h", func(b *Bus, tp, v interface{}) {})
+	assert.True(t, bus.UnsubscribeNamed("ns.test", "h"), "named functions should be found through namespaces")
+}
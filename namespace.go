@@ -0,0 +1,75 @@
+package bus
+
+import (
+	"strings"
+)
+
+// Namespace returns a view of this Bus in which string topics are prefixed
+// with the given prefix followed by a dot, so that subscribers and
+// publishers using different namespaces never see each other's values. For
+// example, publishing "started" to the "jobs" namespace publishes
+// "jobs.started" on the underlying Bus. Handlers subscribed through the
+// namespace are passed topics with the prefix removed, and the namespace
+// itself as their Bus. Namespaces may be nested.
+//
+// Topics that are not strings are left unprefixed, and so are shared by all
+// namespaces. The namespace shares all state and configuration with the
+// underlying Bus; in particular, SubscribeAll, PublishAll, Stats and Close
+// act on the underlying Bus as a whole.
+func (b *Bus) Namespace(prefix string) *Bus {
+	return &Bus{core: b.core, prefix: b.prefix + prefix + "."}
+}
+
+// qualify returns the topic as stored by the underlying Bus, prefixing
+// string topics with the namespace of this Bus.
+func (b *Bus) qualify(topic interface{}) interface{} {
+	if s, ok := topic.(string); ok && b.prefix != "" {
+		return b.prefix + s
+	}
+	return topic
+}
+
+// unqualify reverses qualify, removing the namespace of this Bus from string
+// topics.
+func (b *Bus) unqualify(topic interface{}) interface{} {
+	if s, ok := topic.(string); ok {
+		return strings.TrimPrefix(s, b.prefix)
+	}
+	return topic
+}
+
+// nsHandler wraps a handler subscribed through a namespace, passing it topics
+// relative to that namespace.
+type nsHandler struct {
+	b *Bus
+	h Handler
+}
+
+func (h *nsHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(h.b, h.b.unqualify(t), v)
+	}
+	return true
+}
+
+func (h *nsHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *nsHandler) OnErr(b *Bus, t, v interface{}) error {
+	// Keep track of the topics the value was forwarded through, and the
+	// details of its publish
+	nb := *b
+	nb.prefix = h.b.prefix
+	if b.meta != nil {
+		meta := *b.meta
+		meta.OriginalTopic = h.b.unqualify(meta.OriginalTopic)
+		meta.MatchedTopic = h.b.unqualify(meta.MatchedTopic)
+		nb.meta = &meta
+	}
+	return call(&nb, h.h, h.b.unqualify(t), v)
+}
+
+func (h *nsHandler) unwrap() Handler {
+	return h.h
+}
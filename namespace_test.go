@@ -0,0 +1,86 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	bus := NewBus()
+	jobs := bus.Namespace("jobs")
+	users := bus.Namespace("users")
+
+	h := &mockHandler{}
+	unsub := jobs.Subscribe("started", h)
+
+	n, err := users.Publish("started", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "other namespaces should not see publishes")
+
+	n, err = jobs.Publish("started", "build")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "started", h.t, "handler should receive unprefixed topic")
+	assert.Equal(t, "build", h.v)
+
+	n, err = bus.Publish("jobs.started", "test")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "underlying bus should see prefixed topic")
+	assert.Equal(t, "started", h.t)
+
+	assert.True(t, jobs.Unsubscribe("started", h), "unsubscribe by handler")
+	assert.False(t, unsub())
+}
+
+func TestNamespaceNested(t *testing.T) {
+	bus := NewBus()
+	ns := bus.Namespace("a").Namespace("b")
+	h := &mockHandler{}
+	defer bus.Subscribe("a.b.c", h)()
+
+	n, err := ns.Publish("c", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "a.b.c", h.t)
+}
+
+func TestNamespaceNonString(t *testing.T) {
+	bus := NewBus()
+	ns := bus.Namespace("ns")
+	h := &mockHandler{}
+	defer ns.Subscribe(42, h)()
+
+	n, err := bus.Publish(42, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "non-string topics should not be prefixed")
+	assert.Equal(t, 42, h.t)
+}
+
+func TestNamespaceFilter(t *testing.T) {
+	bus := NewBus()
+	ns := bus.Namespace("ns")
+	h := &mockHandler{}
+	defer ns.SubscribeFilter("test", func(v interface{}) bool {
+		return v == "pass"
+	}, h)()
+
+	n, _ := ns.Publish("test", "fail")
+	assert.Equal(t, 0, n, "filters should apply within namespaces")
+	n, _ = ns.Publish("test", "pass")
+	assert.Equal(t, 1, n)
+}
+
+func TestNamespacePublishSync(t *testing.T) {
+	bus := NewBus()
+	id := goroutineID()
+	calls := 0
+	bus.Namespace("ns").SubscribeAsync("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		assert.Equal(t, id, goroutineID(), "handler should be called by the publisher")
+		calls++
+	}))
+
+	_, err := bus.PublishSync("ns.test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
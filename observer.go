@@ -0,0 +1,65 @@
+package bus
+
+// Observer is told about the subscriptions and publishes made on a Bus, for
+// debugging or monitoring. Observers are added with WithObserver, and are
+// called synchronously, without any lock held, so may use the Bus.
+//
+// Topics are those of the underlying Bus, including any namespace prefixes.
+// Handlers subscribed with SubscribeAll or SubscribeFallback are reported
+// with a nil topic.
+type Observer interface {
+	// OnSubscribe is called after a handler is subscribed to a topic.
+	OnSubscribe(topic interface{}, h Handler)
+
+	// OnUnsubscribe is called after a handler is unsubscribed from a topic,
+	// including by RemoveTopic and Reset.
+	OnUnsubscribe(topic interface{}, h Handler)
+
+	// OnPublish is called after a value is published to a topic, with the
+	// number of handlers it was delivered to.
+	OnPublish(topic, value interface{}, count int)
+}
+
+// observerEvent is a subscription or unsubscription waiting to be reported
+// to the observers once the lock is released.
+type observerEvent struct {
+	subscribed bool
+	topic      interface{}
+	handler    Handler
+}
+
+// observeLocked records that the subscription was added or removed, to be
+// reported to the observers by unlock. It must be called with the write lock
+// held.
+func (b *Bus) observeLocked(subscribed bool, s *subscription) {
+	if len(b.observers) == 0 {
+		return
+	}
+	h := s.handler
+	if nh, ok := h.(*nsHandler); ok {
+		h = nh.h
+	}
+	b.events = append(b.events, observerEvent{subscribed: subscribed, topic: s.topic, handler: h})
+}
+
+// unlock releases the write lock, then reports the subscriptions made and
+// removed while it was held to the observers, and stops the handlers of those
+// removed.
+func (b *Bus) unlock() {
+	evs, stops := b.events, b.stopping
+	b.events, b.stopping = nil, nil
+	b.lock.Unlock()
+
+	for _, ev := range evs {
+		for _, o := range b.observers {
+			if ev.subscribed {
+				o.OnSubscribe(ev.topic, ev.handler)
+			} else {
+				o.OnUnsubscribe(ev.topic, ev.handler)
+			}
+		}
+	}
+	for _, stop := range stops {
+		stop()
+	}
+}
@@ -0,0 +1,79 @@
+package bus
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingObserver records the events it observes.
+type recordingObserver struct {
+	bus    *Bus
+	events []string
+}
+
+func (o *recordingObserver) OnSubscribe(topic interface{}, h Handler) {
+	o.events = append(o.events, fmt.Sprintf("subscribe %v", topic))
+}
+
+func (o *recordingObserver) OnUnsubscribe(topic interface{}, h Handler) {
+	o.events = append(o.events, fmt.Sprintf("unsubscribe %v", topic))
+}
+
+func (o *recordingObserver) OnPublish(topic, value interface{}, count int) {
+	// Observers are called without the lock held, so may use the Bus
+	o.bus.SubscriberCount(topic)
+	o.events = append(o.events, fmt.Sprintf("publish %v %v %d", topic, value, count))
+}
+
+func TestObserver(t *testing.T) {
+	a, b := &recordingObserver{}, &recordingObserver{}
+	bus := NewBus(WithObserver(a), WithObserver(b))
+	a.bus, b.bus = bus, bus
+
+	h := &mockHandler{}
+	unsub := bus.Subscribe("x", h)
+	bus.Namespace("ns").Subscribe("y", h)
+	bus.Publish("x", 1)
+	unsub()
+	unsub()
+	bus.Unsubscribe("x", h)
+	bus.RemoveTopic("ns.y")
+
+	want := []string{
+		"subscribe x",
+		"subscribe ns.y",
+		"publish x 1 1",
+		"unsubscribe x",
+		"unsubscribe ns.y",
+	}
+	assert.Equal(t, want, a.events)
+	assert.Equal(t, want, b.events, "all observers should be called")
+}
+
+func TestObserverReset(t *testing.T) {
+	o := &recordingObserver{}
+	bus := NewBus(WithObserver(o))
+	o.bus = bus
+
+	bus.SubscribeAll(&mockHandler{})
+	bus.Reset()
+	assert.Equal(t, []string{"subscribe <nil>", "unsubscribe <nil>"}, o.events)
+}
+
+// TestObserverCountMatchesPublish checks that observers are told the same
+// count as Publish returns, even when handlers subscribe during delivery.
+func TestObserverCountMatchesPublish(t *testing.T) {
+	o := &recordingObserver{}
+	bus := NewBus(WithObserver(o))
+	o.bus = bus
+
+	bus.SubscribeFunc("x", func(b *Bus, tp, v interface{}) {
+		b.SubscribeFunc("x", func(b *Bus, tp, v interface{}) {})
+	})
+	n, err := bus.Publish("x", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "handlers subscribed during delivery should not be called")
+	assert.Contains(t, o.events, "publish x 1 1")
+}
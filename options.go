@@ -0,0 +1,324 @@
+package bus
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// BusOption configures a Bus created by NewBus.
+type BusOption func(b *Bus)
+
+// WithMaxConcurrency limits the number of handlers that may run concurrently
+// as a result of publishing with the Async flag. Once the limit is reached,
+// Publish blocks until a running handler returns. A limit of 0 means
+// handlers are not limited.
+func WithMaxConcurrency(n int) BusOption {
+	return func(b *Bus) {
+		if n > 0 {
+			b.sem = make(chan struct{}, n)
+		} else {
+			b.sem = nil
+		}
+	}
+}
+
+// WithPooledAsync causes handlers called asynchronously, with the Async flag
+// or subscribed with SubscribeAsync, to be run by a pool of the given number
+// of goroutines, which live until the Bus is closed, rather than each in a
+// new goroutine, reducing the cost of publishing at high rates. Up to
+// workers handlers wait in a queue for a free worker, or as many as set by
+// WithPooledAsyncQueue; when the queue is full, handlers are run in new
+// goroutines as usual, so that a handler publishing asynchronously never
+// deadlocks waiting for the pool. WithMaxConcurrency and topics'
+// MaxConcurrency still apply. Close waits for the queued handlers to be run
+// before stopping the workers. A workers of 0 or less disables the pool.
+func WithPooledAsync(workers int) BusOption {
+	return func(b *Bus) {
+		b.workers = workers
+	}
+}
+
+// WithPooledAsyncQueue sets the number of handlers that can wait for a free
+// worker of the pool set up by WithPooledAsync before handlers are run in
+// new goroutines instead. A larger queue absorbs bursts of publishes without
+// starting goroutines, at the cost of handlers waiting longer to be run. A
+// size of 0 or less uses the default of one per worker. It has no effect
+// without WithPooledAsync.
+func WithPooledAsyncQueue(size int) BusOption {
+	return func(b *Bus) {
+		b.poolQueue = size
+	}
+}
+
+// WithSerialTopics serializes synchronous publishes to each topic, so that
+// concurrent calls to Publish on the same topic deliver their values one at a
+// time and every handler observes the same order of values. This reduces
+// throughput in exchange for ordering. Publishing to a topic from within one
+// of its own synchronous handlers will deadlock in this mode.
+func WithSerialTopics() BusOption {
+	return func(b *Bus) {
+		b.serial = true
+	}
+}
+
+// WithDispatcher causes the Bus to deliver published values using the given
+// Dispatcher instead of DefaultDispatcher.
+func WithDispatcher(d Dispatcher) BusOption {
+	return func(b *Bus) {
+		b.dispatcher = d
+	}
+}
+
+// WithNoSubscribersError causes Publish to fail with ErrNoSubscribers when a
+// value is published to a topic without any handlers.
+func WithNoSubscribersError() BusOption {
+	return func(b *Bus) {
+		b.noSubsErr = true
+	}
+}
+
+// WithMaxDepth limits how deeply synchronous publishes may nest, such as when
+// a handler publishes to its own topic. A publish that would begin more than
+// n synchronous deliveries in progress on the same goroutine fails with
+// ErrMaxDepth instead. Publishes with the Async or OrderedAsync flags are
+// not limited. A limit of 0 means depth is not limited.
+func WithMaxDepth(n int) BusOption {
+	return func(b *Bus) {
+		b.maxDepth = n
+	}
+}
+
+// WithBubbling causes values published to a string topic containing dots to
+// also be delivered to the handlers of each of its parent topics, nearest
+// first. For example, publishing to "a.b.c" calls the handlers of "a.b.c",
+// then "a.b", then "a", passing each of them "a.b.c" as the topic. Other
+// topics are delivered to their own handlers only.
+func WithBubbling() BusOption {
+	return func(b *Bus) {
+		b.bubbling = true
+	}
+}
+
+// Order is the order in which the handlers of a topic are called.
+type Order int
+
+const (
+	// FIFO calls handlers in the order they were subscribed.
+	FIFO Order = iota
+
+	// LIFO calls the most recently subscribed handlers first.
+	LIFO
+)
+
+// WithDeliveryOrder sets the order in which the handlers of each topic are
+// called. Handlers subscribed with SubscribeAll are still called after the
+// handlers of the topic itself, in the same order. With the Async flag, the
+// order determines the order in which the handlers are started. The default
+// is FIFO.
+func WithDeliveryOrder(order Order) BusOption {
+	return func(b *Bus) {
+		b.order = order
+	}
+}
+
+// WithRejectNilTopic causes the Bus to treat nil as an invalid topic, so that
+// subscribing or publishing to it fails with ErrInvalidTopic as for any other
+// topic that cannot be used, rather than a topic variable that was never set
+// going unnoticed. By default, nil is a valid topic.
+func WithRejectNilTopic() BusOption {
+	return func(b *Bus) {
+		b.rejectNil = true
+	}
+}
+
+// WithSkipNilHandlers causes publishing to silently skip any nil handler
+// found among the handlers of a topic. By default, such handlers are also
+// skipped, so that the others are still called, but the publish returns
+// ErrNilHandler naming the topic. Nil handlers cannot normally be
+// subscribed, so finding one indicates a bug.
+func WithSkipNilHandlers() BusOption {
+	return func(b *Bus) {
+		b.skipNil = true
+	}
+}
+
+// WithDedupSubscriptions causes subscribing a handler to a topic it is
+// already subscribed to on the Bus to have no effect, so that it continues
+// to be called once per value. The function returned for the repeated
+// subscription removes the existing one. By default, each subscription of
+// the same handler is kept, and the handler is called once for each.
+// Handlers of uncomparable types, such as bare HandlerFunc values, are never
+// considered to be already subscribed.
+func WithDedupSubscriptions() BusOption {
+	return func(b *Bus) {
+		b.dedup = true
+	}
+}
+
+// WithGlobalOrder determines whether handlers subscribed with SubscribeAll
+// are called before or after the handlers of the topic a value is published
+// to. By default they are called after. Only the order in which the handlers
+// are called changes; the number of deliveries is the same either way.
+func WithGlobalOrder(before bool) BusOption {
+	return func(b *Bus) {
+		b.globalsFirst = before
+	}
+}
+
+// WithHistory causes the named topic to record the last n values published
+// to it, which are replayed to handlers subscribed with SubscribeReplay.
+func WithHistory(topic interface{}, n int) BusOption {
+	return func(b *Bus) {
+		b.stateLocked(topic).history = (*history)(nil).resize(n)
+	}
+}
+
+// WithObserver adds an Observer to the Bus, which is told about each
+// subscription, unsubscription and publish. It may be passed more than once
+// to add several observers, which are called in the order they were added.
+func WithObserver(o Observer) BusOption {
+	return func(b *Bus) {
+		b.observers = append(b.observers, o)
+	}
+}
+
+// WithHandlerTimer causes fn to be called after each handler is called,
+// with the topic, the handler and the time the handler took. Asynchronous
+// handlers are timed in their own goroutine, so fn must be safe to call
+// concurrently.
+func WithHandlerTimer(fn func(topic interface{}, h Handler, d time.Duration)) BusOption {
+	return func(b *Bus) {
+		b.timer = fn
+	}
+}
+
+// WithSlowHandlerThreshold causes warn to be called after any handler takes
+// longer than d, with the topic, the handler and the time the handler took.
+// Handlers that return within d cost only a comparison. Asynchronous handlers
+// are timed in their own goroutine, so warn must be safe to call
+// concurrently.
+func WithSlowHandlerThreshold(d time.Duration, warn func(topic interface{}, h Handler, d time.Duration)) BusOption {
+	return func(b *Bus) {
+		b.slow = d
+		b.slowWarn = warn
+	}
+}
+
+// WithPanicPolicy sets what the Bus does when a handler panics, whether it
+// is called synchronously or with the Async flag. By default, PanicPropagate
+// is used. With PanicLog, logf is called with the topic, the handler and the
+// recovered value, or the panic and its stack are logged with the log
+// package if logf is nil. Asynchronous handlers panic in their own goroutine,
+// so logf must be safe to call concurrently.
+func WithPanicPolicy(p PanicPolicy, logf func(topic interface{}, h Handler, recovered interface{})) BusOption {
+	return func(b *Bus) {
+		b.panics = p
+		b.panicLog = logf
+	}
+}
+
+// WithTopicTTL causes the statistics of a topic that has had no handlers for
+// longer than d, and has not been configured, to be discarded by Sweep,
+// which is called every d/2 in the background until the Bus is closed. A
+// topic's idle time starts when its last handler is unsubscribed, or when a
+// sweep first finds it without handlers, and is reset when a handler is
+// subscribed to it.
+func WithTopicTTL(d time.Duration) BusOption {
+	return func(b *Bus) {
+		b.ttl = d
+	}
+}
+
+// WithSeqPolicy sets what PublishSeq does with a value whose sequence number
+// is not greater than that of the previous value published to its topic. By
+// default, SeqFlag is used.
+func WithSeqPolicy(p SeqPolicy) BusOption {
+	return func(b *Bus) {
+		b.seqPolicy = p
+	}
+}
+
+// WithMetrics causes the number of values published to and dropped by each
+// topic, and the time taken by each handler, to be reported to sink. Without
+// it, no metrics are reported.
+func WithMetrics(sink MetricsSink) BusOption {
+	return func(b *Bus) {
+		b.metrics = sink
+		for t, st := range b.states {
+			st.stats.bind(sink, t)
+		}
+	}
+}
+
+// WithMatcher causes values published to a topic to be delivered to the
+// handlers of every subscribed topic that m matches to it, rather than only
+// to those of the identical topic. This allows, for example, string topics to
+// be matched regardless of case using CaseInsensitiveMatcher. Each publish
+// must then ask m about every topic on the Bus instead of looking the topic
+// up directly, so publishing becomes slower as the number of topics grows.
+// Retained values, histories and other per-topic settings still belong to
+// the exact topic published to.
+func WithMatcher(m TopicMatcher) BusOption {
+	return func(b *Bus) {
+		b.matcher = m
+	}
+}
+
+// WithAsyncErrorHandler causes fn to be called with the topic, value and
+// error each time an ErrHandler called asynchronously fails, as such errors
+// cannot be returned by Publish. This includes handlers called because of the
+// Async or OrderedAsync flags, and those subscribed with SubscribeAsync,
+// SubscribeBuffered and similar. fn is called from the goroutine that called
+// the handler, so must be safe to call concurrently.
+func WithAsyncErrorHandler(fn func(topic, value interface{}, err error)) BusOption {
+	return func(b *Bus) {
+		b.asyncErr = fn
+	}
+}
+
+// WithCloneFunc causes each handler to be passed its own copy of each value
+// published, made by calling fn, so that handlers mutating the values they
+// receive do not affect each other. Synchronous handlers are each passed a
+// clone made just before they are called, and asynchronous handlers a clone
+// made in their own goroutine. Handlers' filters are passed the original
+// value. Without a clone function, all handlers share the published value.
+func WithCloneFunc(fn func(v interface{}) interface{}) BusOption {
+	return func(b *Bus) {
+		b.clone = fn
+	}
+}
+
+// WithJSONClone causes each handler to be passed its own copy of each value
+// published, as WithCloneFunc does, made by encoding the value as JSON and
+// decoding it into a new value of the same type. Handlers therefore share no
+// mutable state for values that survive the round trip, such as maps,
+// slices and structs of exported fields. Unexported fields are not copied,
+// and are left as zero values in the clone. Values that cannot be encoded,
+// such as channels and functions, are passed to every handler as published.
+//
+// The clone only has the same types as the value where they are given by
+// the value's type: fields, slice elements and map values of type
+// interface{} are decoded as JSON would decode them, so numbers held in them
+// become float64 and structs become map[string]interface{}.
+func WithJSONClone() BusOption {
+	return WithCloneFunc(jsonClone)
+}
+
+// jsonClone returns a copy of v made by encoding it as JSON and decoding it
+// into a new value of the same type, or v itself if that fails. Values held
+// in interface{} are decoded with the types given by encoding/json.
+func jsonClone(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	c := reflect.New(reflect.TypeOf(v))
+	if err := json.Unmarshal(data, c.Interface()); err != nil {
+		return v
+	}
+	return c.Elem().Interface()
+}
@@ -0,0 +1,220 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	bus := NewBus(WithMaxConcurrency(2))
+
+	var running, peak int32
+	lock := sync.Mutex{}
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		n := atomic.AddInt32(&running, 1)
+		lock.Lock()
+		if n > peak {
+			peak = n
+		}
+		lock.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+
+	for i := 0; i < 10; i++ {
+		n, err := bus.Publish("test", i, Async)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}
+
+	assert.NoError(t, bus.Close())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&running), "close should wait for handlers")
+	assert.True(t, peak <= 2, "no more than two handlers should run at once")
+}
+
+func TestMaxConcurrencyUnbounded(t *testing.T) {
+	bus := NewBus(WithMaxConcurrency(0))
+	assert.Nil(t, bus.sem)
+}
+
+// TestSerialTopics checks that concurrent publishes are observed in the same
+// order by every handler.
+func TestSerialTopics(t *testing.T) {
+	bus := NewBus(WithSerialTopics())
+
+	var a, b []interface{}
+	bus.SubscribeFunc("test", func(_ *Bus, tp, v interface{}) {
+		a = append(a, v)
+		time.Sleep(time.Millisecond)
+	})
+	bus.SubscribeFunc("test", func(_ *Bus, tp, v interface{}) {
+		b = append(b, v)
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bus.Publish("test", i)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, a, 10)
+	assert.Equal(t, a, b, "handlers should observe the same order")
+}
+
+func TestCloneFunc(t *testing.T) {
+	clone := func(v interface{}) interface{} {
+		m := map[string]int{}
+		for k, n := range v.(map[string]int) {
+			m[k] = n
+		}
+		return m
+	}
+
+	for _, async := range []bool{false, true} {
+		bus := NewBus(WithCloneFunc(clone))
+		var lock sync.Mutex
+		var seen []int
+		for i := 0; i < 3; i++ {
+			bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+				m := v.(map[string]int)
+				lock.Lock()
+				seen = append(seen, m["n"])
+				lock.Unlock()
+				m["n"]++
+			})
+		}
+
+		value := map[string]int{"n": 0}
+		if async {
+			bus.Publish("test", value, Async)
+			bus.Drain()
+		} else {
+			bus.Publish("test", value)
+		}
+		assert.Equal(t, []int{0, 0, 0}, seen, "mutations should not leak between handlers")
+		assert.Equal(t, 0, value["n"], "published value should be untouched")
+	}
+}
+
+func TestJSONClone(t *testing.T) {
+	type item struct {
+		Tags   []string
+		Counts map[string]int
+	}
+	bus := NewBus(WithJSONClone())
+	for i := 0; i < 2; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+			it := v.(*item)
+			assert.Equal(t, []string{"a"}, it.Tags, "mutations should not leak between handlers")
+			assert.Equal(t, 1, it.Counts["a"])
+			it.Tags[0] = "b"
+			it.Counts["a"]++
+		})
+	}
+	value := &item{Tags: []string{"a"}, Counts: map[string]int{"a": 1}}
+	bus.Publish("test", value)
+	assert.Equal(t, "a", value.Tags[0], "published value should not be mutated")
+
+	// Values that cannot be encoded are passed as published
+	ch := make(chan int)
+	bus = NewBus(WithJSONClone())
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		assert.Equal(t, ch, v)
+	})
+	bus.Publish("test", ch)
+	assert.Nil(t, jsonClone(nil))
+	assert.Equal(t, 3, jsonClone(3))
+	assert.Equal(t, map[string]interface{}{"a": float64(1)}, jsonClone(map[string]interface{}{"a": 1}),
+		"numbers held in interface{} should be decoded as float64")
+}
+
+func TestClone(t *testing.T) {
+	o := &recordingObserver{}
+	bus := NewBus(WithMaxConcurrency(1), WithObserver(o), WithHistory("test", 2))
+	o.bus = bus
+	var orphans int
+	bus.OnNoSubscribers = func(topic, value interface{}) {
+		orphans++
+	}
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	bus.Publish("test", 1)
+
+	c := bus.Clone()
+	assert.Equal(t, 0, c.TotalSubscribers(), "clone should have no subscriptions")
+	assert.Empty(t, c.Stats()["test"].PublishCount)
+	assert.Equal(t, cap(bus.sem), cap(c.sem))
+
+	var replayed []interface{}
+	c.SubscribeReplay("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		replayed = append(replayed, v)
+	}))
+	assert.Empty(t, replayed, "history should not be shared")
+
+	c.Publish("other", 1)
+	assert.Equal(t, 1, orphans)
+	assert.Len(t, o.events, 4, "observers should be shared configuration")
+}
+
+func TestHandlerTimer(t *testing.T) {
+	var lock sync.Mutex
+	timed := map[Handler]time.Duration{}
+	bus := NewBus(WithHandlerTimer(func(topic interface{}, h Handler, d time.Duration) {
+		assert.Equal(t, "ns.test", topic)
+		lock.Lock()
+		timed[h] += d
+		lock.Unlock()
+	}))
+
+	slow := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		time.Sleep(5 * time.Millisecond)
+	})
+	fast := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	ns := bus.Namespace("ns")
+	ns.Subscribe("test", &slow)
+	ns.Subscribe("test", &fast)
+
+	ns.Publish("test", 1)
+	ns.Publish("test", 2, Async)
+	bus.Drain()
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Len(t, timed, 2, "handlers should be passed as subscribed")
+	assert.GreaterOrEqual(t, timed[&slow], 10*time.Millisecond)
+	assert.Contains(t, timed, Handler(&fast))
+}
+
+func TestSlowHandlerThreshold(t *testing.T) {
+	var lock sync.Mutex
+	var warned []Handler
+	bus := NewBus(WithSlowHandlerThreshold(2*time.Millisecond, func(topic interface{}, h Handler, d time.Duration) {
+		assert.Equal(t, "test", topic)
+		assert.Greater(t, d, 2*time.Millisecond)
+		lock.Lock()
+		warned = append(warned, h)
+		lock.Unlock()
+	}))
+
+	slow := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		time.Sleep(5 * time.Millisecond)
+	})
+	fast := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	bus.Subscribe("test", &slow)
+	bus.Subscribe("test", &fast)
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2, Async)
+	bus.Drain()
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []Handler{&slow, &slow}, warned, "only the slow handler should be reported")
+}
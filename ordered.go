@@ -0,0 +1,183 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// orderedQueueSize is the number of deliveries that may be waiting on a
+// topic's ordered queue before publishing with OrderedAsync blocks.
+const orderedQueueSize = 64
+
+// orderedJob is a single delivery waiting on an ordered queue.
+type orderedJob struct {
+	bus      *Bus
+	handlers []Handler
+	topic    interface{}
+	value    interface{}
+	epoch    uint64
+}
+
+// orderedQueue delivers values published to a topic with OrderedAsync. A
+// single goroutine consumes the queue, so values are delivered in the order
+// they were published, each to all of its handlers before the next.
+type orderedQueue struct {
+	lock   sync.Mutex
+	closed bool
+
+	// sending counts the publishers let in before the queue was closed that
+	// may still be waiting for room on it.
+	sending sync.WaitGroup
+
+	jobs    chan orderedJob
+	closing chan struct{}
+	done    chan struct{}
+
+	// worker is the ID of the goroutine consuming the queue.
+	worker atomic.Uint64
+}
+
+// orderedQueue returns the ordered queue for the given topic state, starting
+// its worker if this is the first publish with OrderedAsync. No worker is
+// started once the Bus has been closed.
+func (b *Bus) orderedQueue(st *topicState) (*orderedQueue, error) {
+	b.lock.RLock()
+	q := st.ordered
+	b.lock.RUnlock()
+	if q != nil {
+		return q, nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if st.ordered == nil {
+		if b.closed {
+			return nil, ErrBusClosed
+		}
+		st.ordered = b.newOrderedQueue()
+	}
+	return st.ordered, nil
+}
+
+// newOrderedQueue creates an ordered queue and starts its worker.
+func (b *Bus) newOrderedQueue() *orderedQueue {
+	q := &orderedQueue{
+		jobs:    make(chan orderedJob, orderedQueueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go b.runOrdered(q)
+	return q
+}
+
+// runOrdered delivers each job on the queue in turn until it is closed, then
+// delivers those still queued.
+func (b *Bus) runOrdered(q *orderedQueue) {
+	defer close(q.done)
+	q.worker.Store(goroutineID())
+	for {
+		select {
+		case j := <-q.jobs:
+			b.runOrderedJob(j)
+		case <-q.closing:
+			// Publishers still waiting for room give up once the queue
+			// is closed, so after they have left nothing more is queued
+			q.sending.Wait()
+			for {
+				select {
+				case j := <-q.jobs:
+					b.runOrderedJob(j)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// runOrderedJob delivers a job taken from an ordered queue.
+func (b *Bus) runOrderedJob(j orderedJob) {
+	_, err := b.dispatcher.Dispatch(j.bus, j.handlers, j.topic, j.value, false)
+	b.reportAsync(err)
+	b.async.done(j.epoch)
+}
+
+// enqueue adds the delivery to the topic's ordered queue, blocking if the
+// queue is full. The handlers are passed db as their Bus. It returns
+// ErrBusClosed if the queue has been closed.
+func (b *Bus) enqueue(st *topicState, db *Bus, hs []Handler, t, v interface{}) error {
+	q, err := b.orderedQueue(st)
+	if err != nil {
+		return err
+	}
+	return b.push(q, db, hs, t, v)
+}
+
+// push adds the delivery to the queue, blocking if the queue is full until
+// there is room or the queue is closed. The lock is not held while waiting,
+// so the queue can be closed meanwhile. It returns ErrBusClosed if the queue
+// has been closed, and ErrOrderedQueueFull if called by the queue's own
+// worker while the queue is full.
+func (b *Bus) push(q *orderedQueue, db *Bus, hs []Handler, t, v interface{}) error {
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return ErrBusClosed
+	}
+	q.sending.Add(1)
+	q.lock.Unlock()
+	defer q.sending.Done()
+
+	j := orderedJob{bus: db, handlers: hs, topic: t, value: v, epoch: b.async.add()}
+	select {
+	case q.jobs <- j:
+		return nil
+	default:
+	}
+	if goroutineID() == q.worker.Load() {
+		// The worker would be waiting for itself
+		b.async.done(j.epoch)
+		return ErrOrderedQueueFull
+	}
+	select {
+	case q.jobs <- j:
+		return nil
+	case <-q.closing:
+		b.async.done(j.epoch)
+		return ErrBusClosed
+	}
+}
+
+// close stops the queue accepting deliveries and waits for its worker to
+// deliver those already queued and exit.
+func (q *orderedQueue) close() {
+	q.stop()
+	<-q.done
+}
+
+// stop stops the queue accepting deliveries, leaving its worker to deliver
+// those already queued and exit.
+func (q *orderedQueue) stop() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.closing)
+	}
+}
+
+// closeOrdered closes the ordered queues of every topic on the Bus.
+func (b *Bus) closeOrdered() {
+	b.lock.RLock()
+	var qs []*orderedQueue
+	for _, st := range b.states {
+		if st.ordered != nil {
+			qs = append(qs, st.ordered)
+		}
+	}
+	b.lock.RUnlock()
+
+	for _, q := range qs {
+		q.close()
+	}
+}
@@ -0,0 +1,147 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedAsync(t *testing.T) {
+	bus := NewBus()
+
+	var lock sync.Mutex
+	var got []interface{}
+	record := func(b *Bus, tp, v interface{}) {
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		got = append(got, v)
+		lock.Unlock()
+	}
+	bus.SubscribeFunc("test", record)
+	bus.SubscribeFunc("test", record)
+
+	var want []interface{}
+	for i := 0; i < 10; i++ {
+		n, err := bus.Publish("test", i, OrderedAsync)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n, "count should be taken at enqueue time")
+		want = append(want, i, i)
+	}
+
+	bus.Drain()
+	lock.Lock()
+	assert.Equal(t, want, got, "values should be delivered in publish order")
+	lock.Unlock()
+}
+
+func TestOrderedAsyncReturnsImmediately(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		<-release
+	})
+
+	done := make(chan struct{})
+	go func() {
+		bus.Publish("test", 1, OrderedAsync)
+		bus.Publish("test", 2, OrderedAsync)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish should not wait for handlers")
+	}
+	close(release)
+	assert.Equal(t, 2, bus.Drain())
+}
+
+func TestOrderedAsyncClose(t *testing.T) {
+	bus := NewBus()
+	var lock sync.Mutex
+	count := 0
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		count++
+		lock.Unlock()
+	})
+
+	for i := 0; i < 5; i++ {
+		bus.Publish("test", i, OrderedAsync)
+	}
+	assert.NoError(t, bus.Close())
+
+	lock.Lock()
+	assert.Equal(t, 5, count, "close should deliver queued values")
+	lock.Unlock()
+
+	n, err := bus.Publish("test", 6, OrderedAsync)
+	assert.Equal(t, ErrBusClosed, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestOrderedAsyncCloseTopicBlocked(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		<-release
+	})
+
+	// Fill the queue behind the blocked handler
+	bus.Publish("test", 0, OrderedAsync)
+	<-entered
+	for i := 0; i < orderedQueueSize; i++ {
+		bus.Publish("test", i, OrderedAsync)
+	}
+	blocked := make(chan error)
+	go func() {
+		_, err := bus.Publish("test", "blocked", OrderedAsync)
+		blocked <- err
+	}()
+	time.Sleep(10 * time.Millisecond) // Let the publisher block
+
+	closed := make(chan error)
+	go func() {
+		closed <- bus.CloseTopic("test")
+	}()
+	select {
+	case err := <-blocked:
+		// The publisher gives up once the queue is closed, or is refused
+		// if it only arrives once the topic has been closed
+		assert.Contains(t, []error{ErrBusClosed, ErrTopicClosed}, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("closing the topic should not wait for a blocked publisher")
+	}
+	close(release)
+	assert.NoError(t, <-closed)
+	assert.Equal(t, 0, bus.Drain())
+}
+
+func TestOrderedAsyncPublishFromWorker(t *testing.T) {
+	bus := NewBus()
+	var errs []error
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		if v != "first" {
+			return
+		}
+		for i := 0; i <= orderedQueueSize; i++ {
+			if _, err := b.Publish("test", i, OrderedAsync); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	bus.Publish("test", "first", OrderedAsync)
+	bus.Drain()
+	assert.Equal(t, []error{ErrOrderedQueueFull}, errs, "the worker should not wait for itself")
+	assert.NoError(t, bus.Close())
+}
@@ -0,0 +1,77 @@
+package bus
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// matchTopic reports whether the topic matches the pattern. Both are split
+// into segments at each '.', and match if they have the same number of
+// segments, with each segment of the pattern either equal to that of the
+// topic or "*", which matches any single segment.
+func matchTopic(pattern, topic string) bool {
+	for {
+		pi := strings.IndexByte(pattern, '.')
+		ti := strings.IndexByte(topic, '.')
+		if (pi < 0) != (ti < 0) {
+			return false
+		}
+
+		p, t := pattern, topic
+		if pi >= 0 {
+			p, t = pattern[:pi], topic[:ti]
+		}
+		if p != "*" && p != t {
+			return false
+		}
+		if pi < 0 {
+			return true
+		}
+		pattern, topic = pattern[pi+1:], topic[ti+1:]
+	}
+}
+
+// PublishMatching publishes the value, as if by Publish with the same flags,
+// to each string topic on this Bus that has handlers subscribed to it and that
+// matches the pattern. Topics are divided into segments at each '.', and
+// "*" in the pattern matches any single segment, so "metrics.*" matches
+// "metrics.cpu" but not "metrics" or "metrics.cpu.user". Topics are published
+// to in order, and the total number of handlers called is returned, with any
+// errors joined together.
+func (b *Bus) PublishMatching(pattern string, value interface{}, flags ...PublishFlag) (int, error) {
+	pattern, _ = b.qualify(pattern).(string)
+
+	b.lock.RLock()
+	if b.closed {
+		b.lock.RUnlock()
+		return 0, ErrBusClosed
+	}
+	var topics []string
+	for t, ss := range b.topics {
+		if s, ok := t.(string); ok && len(ss) > 0 && matchTopic(pattern, s) {
+			topics = append(topics, s)
+		}
+	}
+	b.lock.RUnlock()
+	sort.Strings(topics)
+
+	// Topics are already qualified, so publish to them from the root Bus
+	root := &Bus{core: b.core}
+	c := 0
+	var errs []error
+	for _, t := range topics {
+		n, err := root.Publish(t, value, flags...)
+		c += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return c, errors.Join(errs...)
+}
+
+// PublishMatching publishes the value to each string topic on the default
+// Bus that has handlers subscribed to it and that matches the pattern.
+func PublishMatching(pattern string, value interface{}, flags ...PublishFlag) (int, error) {
+	return getDefaultBus().PublishMatching(pattern, value, flags...)
+}
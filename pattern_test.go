@@ -0,0 +1,66 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchTopic(t *testing.T) {
+	for _, c := range []struct {
+		pattern, topic string
+		match          bool
+	}{
+		{"metrics.*", "metrics.cpu", true},
+		{"metrics.*", "metrics", false},
+		{"metrics.*", "metrics.cpu.user", false},
+		{"*.cpu", "metrics.cpu", true},
+		{"metrics.*.user", "metrics.cpu.user", true},
+		{"metrics.cpu", "metrics.cpu", true},
+		{"metrics.cpu", "metrics.mem", false},
+		{"*", "metrics", true},
+		{"*", "", true},
+	} {
+		assert.Equal(t, c.match, matchTopic(c.pattern, c.topic), "%q against %q", c.pattern, c.topic)
+	}
+}
+
+func TestPublishMatching(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	record := func(b *Bus, tp, v interface{}) {
+		got = append(got, tp)
+	}
+	bus.SubscribeFunc("metrics.mem", record)
+	bus.SubscribeFunc("metrics.cpu", record)
+	bus.SubscribeFunc("metrics.cpu", record)
+	bus.SubscribeFunc("metrics", record)
+	bus.SubscribeFunc("other.cpu", record)
+	bus.SubscribeFunc(42, record)
+
+	n, err := bus.PublishMatching("metrics.*", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []interface{}{"metrics.cpu", "metrics.cpu", "metrics.mem"}, got)
+
+	bus.Close()
+	_, err = bus.PublishMatching("metrics.*", 1)
+	assert.Equal(t, ErrBusClosed, err)
+}
+
+func TestPublishMatchingNamespace(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	ns := bus.Namespace("ns")
+	ns.SubscribeFunc("metrics.cpu", func(b *Bus, tp, v interface{}) {
+		got = append(got, tp)
+	})
+	bus.SubscribeFunc("metrics.cpu", func(b *Bus, tp, v interface{}) {
+		t.Error("topic outside the namespace should not match")
+	})
+
+	n, err := ns.PublishMatching("metrics.*", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{"metrics.cpu"}, got, "handler should be passed its own topic")
+}
@@ -0,0 +1,94 @@
+package bus
+
+import (
+	"errors"
+	"sync"
+)
+
+// pendingPublish is a publish queued on a batch until it is committed.
+type pendingPublish struct {
+	topic interface{}
+	value interface{}
+	flags []PublishFlag
+}
+
+// PendingPublishes collects publishes made during a unit of work, delivering
+// them only if the work is committed. It is safe for concurrent use.
+type PendingPublishes struct {
+	bus   *Bus
+	lock  sync.Mutex
+	queue []pendingPublish
+	done  bool
+}
+
+// BeginBatch returns a batch on which publishes to this Bus can be queued.
+// Values published to the batch are not seen by any handler until Commit is
+// called, and are discarded by Rollback.
+func (b *Bus) BeginBatch() *PendingPublishes {
+	return &PendingPublishes{bus: b}
+}
+
+// Publish queues the value to be published to the named topic with the given
+// flags when the batch is committed. It returns ErrBatchDone if the batch has
+// already been committed or rolled back.
+func (p *PendingPublishes) Publish(topic, value interface{}, flags ...PublishFlag) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.done {
+		return ErrBatchDone
+	}
+	p.queue = append(p.queue, pendingPublish{topic: topic, value: value, flags: flags})
+	return nil
+}
+
+// Len returns the number of publishes queued on the batch.
+func (p *PendingPublishes) Len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.queue)
+}
+
+// Commit publishes each queued value in the order it was queued, returning
+// the total number of handlers the values were delivered to. Every value is
+// published even if an earlier one fails; their errors are joined. It
+// returns ErrBatchDone if the batch has already been committed or rolled
+// back.
+func (p *PendingPublishes) Commit() (int, error) {
+	p.lock.Lock()
+	if p.done {
+		p.lock.Unlock()
+		return 0, ErrBatchDone
+	}
+	p.done = true
+	queue := p.queue
+	p.queue = nil
+	p.lock.Unlock()
+
+	c := 0
+	var errs []error
+	for _, pp := range queue {
+		n, err := p.bus.Publish(pp.topic, pp.value, pp.flags...)
+		c += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return c, errors.Join(errs...)
+}
+
+// Rollback discards the queued values without publishing them. It has no
+// effect if the batch has already been committed or rolled back.
+func (p *PendingPublishes) Rollback() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.done = true
+	p.queue = nil
+}
+
+// BeginBatch returns a batch on which publishes to the default Bus can be
+// queued until it is committed.
+func BeginBatch() *PendingPublishes {
+	return getDefaultBus().BeginBatch()
+}
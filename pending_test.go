@@ -0,0 +1,59 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeginBatchCommit(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) { got = append(got, v) })
+	bus.SubscribeFunc("b", func(b *Bus, tp, v interface{}) { got = append(got, v) })
+
+	batch := bus.BeginBatch()
+	assert.NoError(t, batch.Publish("a", 1))
+	assert.NoError(t, batch.Publish("b", 2))
+	assert.NoError(t, batch.Publish("a", 3))
+	assert.Equal(t, 3, batch.Len())
+	assert.Empty(t, got, "values should not be delivered before commit")
+
+	n, err := batch.Commit()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []interface{}{1, 2, 3}, got)
+
+	assert.ErrorIs(t, batch.Publish("a", 4), ErrBatchDone)
+	n, err = batch.Commit()
+	assert.ErrorIs(t, err, ErrBatchDone)
+	assert.Zero(t, n)
+	assert.Len(t, got, 3)
+}
+
+func TestBeginBatchRollback(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) { got = append(got, v) })
+
+	batch := bus.BeginBatch()
+	batch.Publish("a", 1)
+	batch.Rollback()
+	assert.Zero(t, batch.Len())
+
+	_, err := batch.Commit()
+	assert.ErrorIs(t, err, ErrBatchDone)
+	assert.Empty(t, got, "rolled back values should not be delivered")
+}
+
+func TestBeginBatchCommitErrors(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("a", func(b *Bus, tp, v interface{}) {})
+	batch := bus.BeginBatch()
+	batch.Publish([]int{}, 1)
+	batch.Publish("a", 2)
+
+	n, err := batch.Commit()
+	assert.ErrorIs(t, err, ErrInvalidTopic)
+	assert.Equal(t, 1, n, "later values should be published after a failure")
+}
@@ -0,0 +1,81 @@
+package bus
+
+// pipeHop identifies a topic on a particular Bus that a value has been piped
+// from, for loop protection.
+type pipeHop struct {
+	core  *core
+	topic interface{}
+}
+
+// pipeHandler republishes each value it receives to the same topic on
+// another Bus.
+type pipeHandler struct {
+	from, to pipeHop
+}
+
+func (h *pipeHandler) On(b *Bus, t, v interface{}) {
+	for _, seen := range b.teed {
+		if seen == h.to {
+			// The value has already passed through the destination
+			return
+		}
+	}
+
+	teed := append(b.teed[:len(b.teed):len(b.teed)], h.from)
+	(&Bus{core: h.to.core, teed: teed, origin: b.originOf(h.from.topic)}).Publish(h.to.topic, v)
+}
+
+// PipeTo causes each value published to any of the given topics on this Bus
+// to be published to the same topic on other as well, returning a function
+// that stops it. Values are republished synchronously by a handler on this
+// Bus, as with Tee, and likewise are never piped into a Bus they have already
+// been piped from, so pipes that form a cycle deliver each value to every Bus
+// in the cycle once rather than looping forever.
+func (b *Bus) PipeTo(other *Bus, topics ...interface{}) UnsubscribeFunc {
+	unsubs := make([]UnsubscribeFunc, len(topics))
+	for i, t := range topics {
+		h := &pipeHandler{
+			from: pipeHop{core: b.core, topic: b.qualify(t)},
+			to:   pipeHop{core: other.core, topic: other.qualify(t)},
+		}
+		unsubs[i] = b.Subscribe(t, h)
+	}
+	return func() bool {
+		ok := false
+		for _, unsub := range unsubs {
+			if unsub() {
+				ok = true
+			}
+		}
+		return ok
+	}
+}
+
+// Pipe connects each of the given topics on this Bus to the same topic on
+// other in both directions, so that values published to them on either Bus
+// are also published on the other, returning a function that disconnects
+// them. A value is never piped back to a Bus it has already been piped from,
+// so each value reaches the handlers on both Buses exactly once.
+func (b *Bus) Pipe(other *Bus, topics ...interface{}) UnsubscribeFunc {
+	there := b.PipeTo(other, topics...)
+	back := other.PipeTo(b, topics...)
+	return func() bool {
+		ok := there()
+		if back() {
+			ok = true
+		}
+		return ok
+	}
+}
+
+// Pipe connects each of the given topics on the default Bus to the same
+// topic on other in both directions.
+func Pipe(other *Bus, topics ...interface{}) UnsubscribeFunc {
+	return getDefaultBus().Pipe(other, topics...)
+}
+
+// PipeTo causes each value published to any of the given topics on the
+// default Bus to be published to the same topic on other as well.
+func PipeTo(other *Bus, topics ...interface{}) UnsubscribeFunc {
+	return getDefaultBus().PipeTo(other, topics...)
+}
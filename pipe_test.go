@@ -0,0 +1,69 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipe(t *testing.T) {
+	host, plugin := NewBus(), NewBus()
+	var hostGot, pluginGot []interface{}
+	host.SubscribeFunc("event", func(b *Bus, tp, v interface{}) {
+		hostGot = append(hostGot, v)
+	})
+	plugin.SubscribeFunc("event", func(b *Bus, tp, v interface{}) {
+		pluginGot = append(pluginGot, v)
+	})
+	plugin.SubscribeFunc("private", func(b *Bus, tp, v interface{}) {
+		t.Error("unpiped topics should not be forwarded")
+	})
+
+	unpipe := host.Pipe(plugin, "event")
+	host.Publish("event", 1)
+	plugin.Publish("event", 2)
+	host.Publish("private", 3)
+	assert.Equal(t, []interface{}{1, 2}, hostGot, "each value should be delivered once")
+	assert.Equal(t, []interface{}{1, 2}, pluginGot, "each value should be delivered once")
+
+	assert.True(t, unpipe())
+	assert.False(t, unpipe())
+	host.Publish("event", 4)
+	assert.Len(t, pluginGot, 2)
+}
+
+func TestPipeTo(t *testing.T) {
+	a, b := NewBus(), NewBus()
+	var got []interface{}
+	b.Namespace("plugin").SubscribeFunc("event", func(_ *Bus, tp, v interface{}) {
+		got = append(got, tp, v)
+	})
+	a.SubscribeFunc("event", func(_ *Bus, tp, v interface{}) {
+		t.Error("pipe should only forward one way")
+	})
+
+	defer a.Namespace("host").PipeTo(b.Namespace("plugin"), "event")()
+	n, err := a.Publish("host.event", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{"event", 1}, got)
+	b.Publish("plugin.event", 2)
+	assert.Len(t, got, 4)
+}
+
+func TestPipeChain(t *testing.T) {
+	a, b, c := NewBus(), NewBus(), NewBus()
+	counts := map[*Bus]int{}
+	for _, bus := range []*Bus{a, b, c} {
+		bus := bus
+		bus.SubscribeFunc("event", func(_ *Bus, tp, v interface{}) {
+			counts[bus]++
+		})
+	}
+	a.PipeTo(b, "event")
+	b.PipeTo(c, "event")
+	c.PipeTo(a, "event")
+
+	a.Publish("event", 1)
+	assert.Equal(t, map[*Bus]int{a: 1, b: 1, c: 1}, counts, "cycle of pipes should not loop")
+}
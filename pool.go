@@ -0,0 +1,94 @@
+package bus
+
+import (
+	"sync"
+)
+
+// asyncJob is a handler to be called asynchronously with a published value.
+type asyncJob struct {
+	bus   *Bus
+	h     Handler
+	topic interface{}
+	value interface{}
+	epoch uint64
+
+	// tsem, if set, is the topic's concurrency limit, a slot of which was
+	// acquired for the job.
+	tsem chan struct{}
+}
+
+// run calls the job's handler, then releases everything acquired for it by
+// goAsync.
+func (j asyncJob) run() {
+	b := j.bus
+	defer b.async.done(j.epoch)
+	defer b.inflight.dec()
+	if j.tsem != nil {
+		defer func() { <-j.tsem }()
+	}
+	if b.sem != nil {
+		defer func() { <-b.sem }()
+	}
+	b.reportAsync(b.Deliver(j.h, j.topic, j.value))
+}
+
+// asyncPool is a fixed set of long-lived goroutines that run asynchronous
+// jobs, for Buses created with WithPooledAsync.
+type asyncPool struct {
+	lock   sync.RWMutex
+	closed bool
+	jobs   chan asyncJob
+	wg     sync.WaitGroup
+}
+
+// newAsyncPool starts a pool of the given number of workers, with room for
+// queue jobs to wait for them, or for as many jobs as workers if queue is 0
+// or less.
+func newAsyncPool(workers, queue int) *asyncPool {
+	if queue <= 0 {
+		queue = workers
+	}
+	p := &asyncPool{jobs: make(chan asyncJob, queue)}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// work runs the jobs submitted to the pool until it is closed.
+func (p *asyncPool) work() {
+	defer p.wg.Done()
+	for j := range p.jobs {
+		j.run()
+	}
+}
+
+// submit queues the job for a worker, returning false without queueing it if
+// the queue is full or the pool has been closed.
+func (p *asyncPool) submit(j asyncJob) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.closed {
+		return false
+	}
+	select {
+	case p.jobs <- j:
+		return true
+	default:
+		return false
+	}
+}
+
+// close stops the workers once they have run the jobs already queued, and
+// waits for them to exit.
+func (p *asyncPool) close() {
+	p.lock.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.lock.Unlock()
+	p.wg.Wait()
+}
@@ -0,0 +1,119 @@
+package bus
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPooledAsync(t *testing.T) {
+	bus := NewBus(WithPooledAsync(2))
+	var n atomic.Int64
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		n.Add(int64(v.(int)))
+	})
+	bus.SubscribeAsync("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		n.Add(int64(v.(int)))
+	}))
+
+	for i := 1; i <= 100; i++ {
+		c, err := bus.Publish("test", i, Async)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, c)
+	}
+	bus.Drain()
+	assert.Equal(t, int64(2*5050), n.Load())
+}
+
+func TestPooledAsyncFull(t *testing.T) {
+	bus := NewBus(WithPooledAsync(1))
+	release := make(chan struct{})
+	entered := make(chan interface{}, 3)
+	var n atomic.Int64
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		entered <- v
+		<-release
+		n.Add(1)
+	})
+
+	// The first value is taken by the worker and the second is queued for
+	// it, so the third must be run in a goroutine of its own
+	bus.Publish("test", 1, Async)
+	assert.Equal(t, 1, <-entered)
+	bus.Publish("test", 2, Async)
+	bus.Publish("test", 3, Async)
+	assert.Equal(t, 3, <-entered)
+	assert.Equal(t, 3, bus.InFlightAsync(), "queued handlers should be counted")
+
+	close(release)
+	bus.Drain()
+	assert.Equal(t, int64(3), n.Load())
+	assert.Equal(t, 0, bus.InFlightAsync())
+}
+
+func TestPooledAsyncQueue(t *testing.T) {
+	bus := NewBus(WithPooledAsync(1), WithPooledAsyncQueue(3))
+	assert.Equal(t, 3, cap(bus.pool.jobs))
+	release := make(chan struct{})
+	entered := make(chan interface{}, 5)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		entered <- v
+		<-release
+	})
+
+	// The worker takes the first value and the next three are queued, so
+	// only the fifth is run in a goroutine of its own
+	for i := 1; i <= 5; i++ {
+		bus.Publish("test", i, Async)
+		if i == 1 {
+			assert.Equal(t, 1, <-entered)
+		}
+	}
+	assert.Equal(t, 5, <-entered)
+	assert.Empty(t, entered, "queued handlers should wait for the worker")
+	close(release)
+	bus.Drain()
+
+	assert.Equal(t, 1, cap(NewBus(WithPooledAsync(1)).pool.jobs))
+}
+
+func TestPooledAsyncClose(t *testing.T) {
+	bus := NewBus(WithPooledAsync(4))
+	var n atomic.Int64
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		n.Add(1)
+	})
+	for i := 0; i < 10; i++ {
+		bus.Publish("test", i, Async)
+	}
+	assert.NoError(t, bus.Close())
+	assert.Equal(t, int64(10), n.Load(), "Close should run the queued handlers")
+	assert.True(t, bus.pool.closed)
+}
+
+// benchmarkAsync publishes asynchronously from several goroutines at once.
+// With WithPooledAsync, publishes that find the pool's queue full fall back
+// to starting a goroutine, so the pooled benchmark measures a mix of the two
+// unless the queue is large enough to absorb the publishers.
+func benchmarkAsync(b *testing.B, opts ...BusOption) {
+	bus := NewBus(opts...)
+	for i := 0; i < 10; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			bus.Publish("test", "value", Async)
+		}
+	})
+	bus.Drain()
+}
+
+func BenchmarkPublishAsync(b *testing.B)       { benchmarkAsync(b) }
+func BenchmarkPublishAsyncPooled(b *testing.B) { benchmarkAsync(b, WithPooledAsync(64)) }
+func BenchmarkPublishAsyncPooledQueue(b *testing.B) {
+	benchmarkAsync(b, WithPooledAsync(64), WithPooledAsyncQueue(4096))
+}
@@ -0,0 +1,70 @@
+package bus
+
+import (
+	"strings"
+)
+
+// SubscribePrefix causes the passed Handler to be called whenever data is
+// published to a string topic on this Bus that starts with the given prefix,
+// so that SubscribePrefix("orders.", h) receives "orders.created" and
+// "orders.shipped". The handler is passed the topic the data was published
+// to. Non-string topics never match.
+//
+// Prefix handlers are called after the handlers subscribed to the topic
+// itself, in the order they were subscribed, and are counted as deliveries
+// by Publish. It returns a function that can be called to unsubscribe the
+// handler.
+func (b *Bus) SubscribePrefix(prefix string, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	if b.prefix != "" {
+		h = &nsHandler{b: b, h: h}
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	b.lastID++
+	s := &subscription{
+		id:      b.lastID,
+		topic:   b.qualify(prefix),
+		handler: h,
+		list:    &b.prefixed,
+		meta:    wantsMeta(h),
+		created: b.clock.Now(),
+	}
+	b.prefixed = append(b.prefixed, s)
+	b.ids[s.id] = s
+	b.observeLocked(true, s)
+	return b.unsubscribeFunc(s)
+}
+
+// prefixLocked appends the subscriptions whose prefix the topic starts with
+// to ss. It must be called with the lock held.
+func (b *Bus) prefixLocked(topic interface{}, ss []*subscription) []*subscription {
+	t, ok := topic.(string)
+	if !ok {
+		return ss
+	}
+
+	copied := false
+	for _, s := range b.prefixed {
+		if !strings.HasPrefix(t, s.topic.(string)) {
+			continue
+		}
+		if !copied {
+			// Never append to the topic's own list
+			ss = append(ss[:len(ss):len(ss)], s)
+			copied = true
+		} else {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
+// SubscribePrefix causes the passed Handler to be called whenever data is
+// published to a string topic on the default Bus that starts with the given
+// prefix.
+func SubscribePrefix(prefix string, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribePrefix(prefix, h)
+}
@@ -0,0 +1,51 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribePrefix(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	unsub := bus.SubscribePrefix("orders.", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, tp)
+	}))
+	bus.SubscribeFunc("orders.created", func(b *Bus, tp, v interface{}) {
+		got = append(got, "own")
+	})
+
+	n, err := bus.Publish("orders.created", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n, "prefix handlers should be counted")
+	assert.Equal(t, []interface{}{"own", "orders.created"}, got, "prefix handlers should follow the topic's own")
+
+	got = nil
+	n, _ = bus.Publish("orders.shipped.late", 1)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{"orders.shipped.late"}, got)
+
+	type topic string
+	for _, tp := range []interface{}{"orders", "users.created", topic("orders.created"), 1} {
+		n, _ = bus.Publish(tp, 1)
+		assert.Equal(t, 0, n, "%v should not match", tp)
+	}
+
+	assert.True(t, unsub())
+	n, _ = bus.Publish("orders.shipped", 1)
+	assert.Equal(t, 0, n)
+}
+
+func TestSubscribePrefixNamespace(t *testing.T) {
+	bus := NewBus()
+	ns := bus.Namespace("app")
+	var got []interface{}
+	ns.SubscribePrefix("orders.", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, tp)
+	}))
+
+	ns.Publish("orders.created", 1)
+	bus.Publish("orders.created", 2)
+	assert.Equal(t, []interface{}{"orders.created"}, got, "prefix should be qualified by the namespace")
+}
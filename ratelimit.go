@@ -0,0 +1,198 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitedHandler wraps a Handler so that it is called at most once per
+// interval. Values arriving before the interval has elapsed since the last
+// delivery are dropped, and are not counted as deliveries by Publish.
+type RateLimitedHandler struct {
+	lock     sync.Mutex
+	interval time.Duration
+	last     time.Time
+	dropped  atomic.Uint64
+	h        Handler
+}
+
+// NewRateLimitedHandler creates a RateLimitedHandler that calls h at most once
+// per minInterval.
+func NewRateLimitedHandler(minInterval time.Duration, h Handler) *RateLimitedHandler {
+	return &RateLimitedHandler{
+		interval: minInterval,
+		h:        h,
+	}
+}
+
+func (h *RateLimitedHandler) accept(b *Bus, t, v interface{}) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	now := b.clock.Now()
+	if !h.last.IsZero() && now.Sub(h.last) < h.interval {
+		h.dropped.Add(1)
+		return false
+	}
+	h.last = now
+	return true
+}
+
+// On calls the wrapped handler.
+func (h *RateLimitedHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+// OnErr calls the wrapped handler, returning its error if it is an
+// ErrHandler.
+func (h *RateLimitedHandler) OnErr(b *Bus, t, v interface{}) error {
+	return call(b, h.h, t, v)
+}
+
+func (h *RateLimitedHandler) unwrap() Handler {
+	return h.h
+}
+
+// Dropped returns the number of values dropped for arriving too soon after
+// the previous delivery.
+func (h *RateLimitedHandler) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// SubscribeRateLimited causes the passed Handler to be called when data is
+// published to the named topic on this Bus, but no more than once per
+// minInterval; values published in between are dropped. Use
+// NewRateLimitedHandler and Subscribe directly to monitor the number of
+// dropped values.
+func (b *Bus) SubscribeRateLimited(topic interface{}, minInterval time.Duration, h Handler) UnsubscribeFunc {
+	return b.Subscribe(topic, NewRateLimitedHandler(minInterval, h))
+}
+
+// RateLimitMode determines what Publish does when a topic limited by
+// SetTopicRateLimitMode has used up its budget.
+type RateLimitMode int
+
+const (
+	// RateLimitReject fails the publish with ErrRateLimited, without
+	// calling any handlers.
+	RateLimitReject RateLimitMode = iota
+
+	// RateLimitWait blocks the publish until the budget allows it.
+	// Publishes waiting together are let through in the order they were
+	// made.
+	RateLimitWait
+)
+
+// tokenBucket limits the rate of publishes to a topic, allowing bursts of
+// up to burst publishes and refilling at rate publishes per second.
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mode   RateLimitMode
+}
+
+// take takes a token from the bucket, returning true if one was available.
+// In RateLimitWait mode, a token is always taken, and the bucket may be
+// overdrawn; the returned duration is then the time to wait until the token
+// would have been available.
+func (tb *tokenBucket) take(now time.Time) (time.Duration, bool) {
+	tb.lock.Lock()
+	defer tb.lock.Unlock()
+
+	if elapsed := now.Sub(tb.last).Seconds(); elapsed > 0 {
+		tb.tokens += elapsed * tb.rate
+		if tb.tokens > tb.burst {
+			tb.tokens = tb.burst
+		}
+	}
+	tb.last = now
+
+	if tb.tokens >= 1 {
+		tb.tokens--
+		return 0, true
+	}
+	if tb.mode != RateLimitWait {
+		return 0, false
+	}
+	tb.tokens--
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second)), true
+}
+
+// limit takes a token from the topic's bucket before a publish, waiting for
+// one if the bucket is in RateLimitWait mode, and returning ErrRateLimited if
+// there is none.
+func (b *Bus) limit(tb *tokenBucket) error {
+	wait, ok := tb.take(b.clock.Now())
+	if !ok {
+		return ErrRateLimited
+	}
+	if wait > 0 {
+		b.sleep(wait)
+	}
+	return nil
+}
+
+// SetTopicRateLimit limits the rate at which values can be published to the
+// named topic on this Bus, however many handlers it has, to perSecond values
+// per second, with bursts of up to burst values. It is equivalent to
+// SetTopicRateLimitMode with RateLimitReject, so publishes over the limit
+// fail with ErrRateLimited.
+func (b *Bus) SetTopicRateLimit(topic interface{}, perSecond float64, burst int) {
+	b.SetTopicRateLimitMode(topic, perSecond, burst, RateLimitReject)
+}
+
+// SetTopicRateLimitMode is like SetTopicRateLimit, but mode determines
+// whether publishes over the limit fail or wait. The limit is a token bucket
+// holding up to burst tokens, which starts full and refills at perSecond
+// tokens per second, with each publish taking a token. A burst less than 1
+// is treated as 1, and a perSecond of 0 or less removes the limit. Setting a
+// limit replaces any the topic already has. If the topic is an alias, the
+// limit applies to the topic it resolves to.
+func (b *Bus) SetTopicRateLimitMode(topic interface{}, perSecond float64, burst int, mode RateLimitMode) {
+	if perSecond <= 0 {
+		b.ClearTopicRateLimit(topic)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	tb := &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   b.clock.Now(),
+		mode:   mode,
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.stateLocked(b.resolveLocked(b.qualify(topic))).limiter = tb
+}
+
+// ClearTopicRateLimit removes the rate limit of the named topic on this Bus,
+// or of the topic it is an alias of, if any.
+func (b *Bus) ClearTopicRateLimit(topic interface{}) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if st := b.states[b.resolveLocked(b.qualify(topic))]; st != nil {
+		st.limiter = nil
+	}
+}
+
+// SetTopicRateLimit limits the rate at which values can be published to the
+// named topic on the default Bus.
+func SetTopicRateLimit(topic interface{}, perSecond float64, burst int) {
+	getDefaultBus().SetTopicRateLimit(topic, perSecond, burst)
+}
+
+// ClearTopicRateLimit removes the rate limit of the named topic on the
+// default Bus.
+func ClearTopicRateLimit(topic interface{}) {
+	getDefaultBus().ClearTopicRateLimit(topic)
+}
@@ -0,0 +1,155 @@
+package bus
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeRateLimited(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	rl := NewRateLimitedHandler(20*time.Millisecond, h)
+	defer bus.Subscribe("test", rl)()
+
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "first value should be delivered")
+
+	n, err = bus.Publish("test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "value within interval should be dropped")
+	assert.Equal(t, 1, h.v)
+	assert.Equal(t, uint64(1), rl.Dropped())
+
+	time.Sleep(25 * time.Millisecond)
+	n, err = bus.Publish("test", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "value after interval should be delivered")
+	assert.Equal(t, 3, h.v)
+}
+
+func TestSubscribeRateLimitedAsync(t *testing.T) {
+	bus := NewBus()
+	defer bus.SubscribeRateLimited("test", time.Hour, HandlerFunc(func(b *Bus, tp, v interface{}) {}))()
+
+	total := 0
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, _ := bus.Publish("test", "hello", Async)
+			lock.Lock()
+			total += n
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, total, "only one delivery should occur within interval")
+}
+
+func TestSetTopicRateLimit(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	bus := NewBus(WithClock(clock))
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+	bus.SetTopicRateLimit("test", 2, 2)
+
+	// The burst is allowed through, then the topic must wait for tokens
+	for i := 1; i <= 2; i++ {
+		n, err := bus.Publish("test", i)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+	}
+	n, err := bus.Publish("test", 3)
+	assert.Equal(t, ErrRateLimited, err)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 2, h.v, "handlers should not be called when rate limited")
+
+	clock.Advance(499 * time.Millisecond)
+	_, err = bus.Publish("test", 4)
+	assert.Equal(t, ErrRateLimited, err)
+	clock.Advance(time.Millisecond)
+	_, err = bus.Publish("test", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, h.v)
+
+	// Tokens do not accumulate beyond the burst
+	clock.Advance(time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err = bus.Publish("test", i)
+		assert.NoError(t, err)
+	}
+	_, err = bus.Publish("test", 3)
+	assert.Equal(t, ErrRateLimited, err)
+
+	_, err = bus.Publish("other", 1)
+	assert.NoError(t, err, "other topics should not be limited")
+
+	bus.ClearTopicRateLimit("test")
+	_, err = bus.Publish("test", 6)
+	assert.NoError(t, err)
+}
+
+func TestSetTopicRateLimitWait(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	bus := NewBus(WithClock(clock))
+	got := make(chan interface{}, 3)
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		got <- v
+	})
+	bus.SetTopicRateLimitMode("test", 10, 1, RateLimitWait)
+
+	bus.Publish("test", 1)
+	assert.Equal(t, 1, <-got)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, err := bus.Publish("test", 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	}()
+	waitPending(t, clock, 1)
+	assert.Empty(t, got, "publish should wait for a token")
+	clock.Advance(100 * time.Millisecond)
+	<-done
+	assert.Equal(t, 2, <-got)
+}
+
+func TestSetTopicRateLimitRejected(t *testing.T) {
+	bus := NewBus(WithClock(NewFakeClock(time.Unix(0, 0))))
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+	bus.RegisterTopicType("test", reflect.TypeOf(0))
+	bus.SetTransform("test", func(v interface{}) interface{} {
+		if v == 0 {
+			return nil
+		}
+		return v
+	})
+	assert.NoError(t, bus.Alias("alias", "test"))
+	bus.SetTopicRateLimit("alias", 1, 1)
+
+	_, err := bus.Publish("test", "a")
+	assert.True(t, errors.Is(err, ErrPayloadType))
+	_, err = bus.Publish("test", 0)
+	assert.NoError(t, err)
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err, "values not published should not take a token")
+	assert.Equal(t, 1, n)
+	_, err = bus.Publish("alias", 2)
+	assert.Equal(t, ErrRateLimited, err, "the limit should apply to the aliased topic")
+
+	bus.ClearTopicRateLimit("alias")
+	_, err = bus.Publish("test", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, h.v)
+}
@@ -0,0 +1,101 @@
+package bus
+
+import (
+	"log"
+	"runtime/debug"
+)
+
+// PanicPolicy determines what a Bus does when a handler panics.
+type PanicPolicy int
+
+const (
+	// PanicPropagate lets the panic continue, crashing the program unless
+	// it is recovered by the caller of Publish. Handlers called with the
+	// Async flag have no such caller. This is the default.
+	PanicPropagate PanicPolicy = iota
+
+	// PanicRecover recovers from the panic and carries on as if the handler
+	// had returned normally.
+	PanicRecover
+
+	// PanicLog recovers from the panic like PanicRecover, but first logs it
+	// along with the handler's stack.
+	PanicLog
+)
+
+// callHandler calls the handler with the value, as call does, applying the
+// Bus's PanicPolicy if the handler panics.
+func (b *Bus) callHandler(h Handler, t, v interface{}) (err error) {
+	if b.panics != PanicPropagate {
+		defer func() {
+			if r := recover(); r != nil {
+				if b.panics == PanicLog {
+					b.logPanic(t, subscribed(h), r)
+				}
+				err = nil
+			}
+		}()
+	}
+	return call(b, h, t, v)
+}
+
+// logPanic logs a panic recovered from the handler, using the function
+// passed to WithPanicPolicy if there is one.
+func (b *Bus) logPanic(t interface{}, h Handler, recovered interface{}) {
+	if b.panicLog != nil {
+		b.panicLog(t, h, recovered)
+		return
+	}
+	log.Printf("bus: handler %T for topic %v panicked: %v\n%s", h, t, recovered, debug.Stack())
+}
+
+// recoverHandler calls its handler, recovering from any panic and passing
+// the recovered value to onPanic.
+type recoverHandler struct {
+	onPanic func(recovered interface{})
+	h       Handler
+}
+
+func (h *recoverHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *recoverHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *recoverHandler) OnErr(b *Bus, t, v interface{}) error {
+	defer func() {
+		if r := recover(); r != nil {
+			h.onPanic(r)
+		}
+	}()
+	return call(b, h.h, t, v)
+}
+
+func (h *recoverHandler) unwrap() Handler {
+	return h.h
+}
+
+// SubscribeRecover causes the passed Handler to be called when data is
+// published to the named topic on this Bus, recovering from any panic in the
+// handler and passing the recovered value to onPanic. The remaining handlers
+// are then called as if the handler had returned normally. Only this handler
+// is protected; a panic in any other handler is not recovered.
+//
+// The handler is protected whether it is called synchronously or with the
+// Async flag, in which case onPanic is called from the handler's goroutine.
+func (b *Bus) SubscribeRecover(topic interface{}, onPanic func(recovered interface{}), h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	return b.Subscribe(topic, &recoverHandler{onPanic: onPanic, h: h})
+}
+
+// SubscribeRecover causes the passed Handler to be called when data is
+// published to the named topic on the default Bus, recovering from any panic
+// in the handler and passing the recovered value to onPanic.
+func SubscribeRecover(topic interface{}, onPanic func(recovered interface{}), h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeRecover(topic, onPanic, h)
+}
@@ -0,0 +1,148 @@
+package bus
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeRecover(t *testing.T) {
+	bus := NewBus()
+	var recovered []interface{}
+	bus.SubscribeRecover("test", func(r interface{}) {
+		recovered = append(recovered, r)
+	}, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		panic(v)
+	}))
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+
+	n, err := bus.Publish("test", "boom")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "boom", h.v, "handlers after the panic should still be called")
+	assert.Equal(t, []interface{}{"boom"}, recovered)
+}
+
+func TestSubscribeRecoverAccept(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	bus.SubscribeRecover("test", func(r interface{}) {}, NewRateLimitedHandler(time.Hour, h))
+
+	bus.Publish("test", 1)
+	n, _ := bus.Publish("test", 2)
+	assert.Equal(t, 0, n, "values declined by the handler should not be counted")
+	assert.Equal(t, 1, h.v)
+}
+
+func TestSubscribeRecoverAsync(t *testing.T) {
+	bus := NewBus()
+	recovered := make(chan interface{}, 1)
+	bus.SubscribeRecover("test", func(r interface{}) {
+		recovered <- r
+	}, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		panic(v)
+	}))
+
+	n, err := bus.Publish("test", "boom", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	bus.Drain()
+	assert.Equal(t, "boom", <-recovered)
+}
+
+// subscribePanicking subscribes a handler that panics with each value it is
+// passed, followed by a mockHandler that is returned.
+func subscribePanicking(bus *Bus) *mockHandler {
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		panic(v)
+	})
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+	return h
+}
+
+func TestPanicPropagate(t *testing.T) {
+	bus := NewBus(WithPanicPolicy(PanicPropagate, nil))
+	subscribePanicking(bus)
+	assert.PanicsWithValue(t, "boom", func() {
+		bus.Publish("test", "boom")
+	})
+}
+
+func TestPanicPropagateAsync(t *testing.T) {
+	if os.Getenv("BUS_PANIC_CHILD") == "1" {
+		bus := NewBus()
+		subscribePanicking(bus)
+		bus.Publish("test", "boom", Async)
+		bus.Drain()
+		return
+	}
+
+	// The panic happens in a goroutine of the Bus, so it can only be seen
+	// to crash the program from another process
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPanicPropagateAsync$")
+	cmd.Env = append(os.Environ(), "BUS_PANIC_CHILD=1")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr, "the program should crash")
+	assert.Contains(t, string(out), "panic: boom")
+}
+
+func TestPanicRecover(t *testing.T) {
+	bus := NewBus(WithPanicPolicy(PanicRecover, func(interface{}, Handler, interface{}) {
+		t.Error("PanicRecover should not log")
+	}))
+	h := subscribePanicking(bus)
+
+	n, err := bus.Publish("test", "boom")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "boom", h.v, "handlers after the panic should still be called")
+
+	n, err = bus.Publish("test", "bang", Async)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	bus.Drain()
+	assert.Equal(t, "bang", h.v)
+}
+
+func TestPanicLog(t *testing.T) {
+	var lock sync.Mutex
+	var logged []interface{}
+	bus := NewBus(WithPanicPolicy(PanicLog, func(topic interface{}, h Handler, r interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		assert.Equal(t, "test", topic)
+		logged = append(logged, r)
+	}))
+	h := subscribePanicking(bus)
+
+	n, err := bus.Publish("test", "boom")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "boom", h.v)
+
+	bus.Publish("test", "bang", Async)
+	bus.Drain()
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []interface{}{"boom", "bang"}, logged)
+}
+
+func TestPanicLogDefault(t *testing.T) {
+	var sb strings.Builder
+	log.SetOutput(&sb)
+	defer log.SetOutput(os.Stderr)
+
+	bus := NewBus(WithPanicPolicy(PanicLog, nil))
+	subscribePanicking(bus)
+	bus.Publish("test", "boom")
+	assert.Contains(t, sb.String(), "bus: handler *bus.HandlerFunc for topic test panicked: boom")
+}
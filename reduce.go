@@ -0,0 +1,147 @@
+package bus
+
+import (
+	"sync"
+)
+
+// ReduceHandler is implemented by handlers that transform the values
+// published to a topic with PublishReduce. Such handlers must be subscribed
+// with SubscribeReduce.
+type ReduceHandler interface {
+	// On is called with the value produced by the previous handler, or the
+	// initial value for the first, and returns the value to pass to the
+	// next.
+	On(b *Bus, t, v interface{}) interface{}
+}
+
+// ReduceFunc is a function that can be used as a ReduceHandler.
+type ReduceFunc func(b *Bus, t, v interface{}) interface{}
+
+// On calls the function.
+func (f ReduceFunc) On(b *Bus, t, v interface{}) interface{} {
+	return f(b, t, v)
+}
+
+// reduceHandler adapts a ReduceHandler to a Handler. Values published other
+// than by PublishReduce are passed to the ReduceHandler, and its result
+// discarded.
+type reduceHandler struct {
+	h ReduceHandler
+}
+
+func (h *reduceHandler) On(b *Bus, t, v interface{}) {
+	h.h.On(b, t, v)
+}
+
+// SubscribeReduce causes the passed ReduceHandler to be called when data is
+// published to the named topic on this Bus. When the value is published with
+// PublishReduce, the value returned by the handler is passed on to the next
+// handler; otherwise it is discarded. It returns a function that can be
+// called to unsubscribe the handler.
+func (b *Bus) SubscribeReduce(topic interface{}, h ReduceHandler) UnsubscribeFunc {
+	if h == nil {
+		panic(ErrNilHandler.Error())
+	}
+	return b.Subscribe(topic, &reduceHandler{h: h})
+}
+
+// PublishReduce passes initial through the handlers of the named topic on
+// this Bus, calling each in turn synchronously, in the order Publish would
+// call them, and returns the value produced by the last of them. Handlers
+// subscribed with SubscribeReduce are passed the value returned by the
+// previous handler and may replace it; other handlers are passed the current
+// value without changing it. Handlers that decline the value, such as those
+// subscribed with SubscribeFilter, are skipped. The value is delivered as
+// Publish would deliver it, so settings such as WithMaxDepth and a custom
+// Dispatcher apply, except that handlers subscribed with SubscribeAsync are
+// called synchronously too, as with PublishSync.
+//
+// Errors reported by ErrHandlers are returned as for Publish, without
+// stopping the remaining handlers from being called.
+func (b *Bus) PublishReduce(topic interface{}, initial interface{}) (interface{}, error) {
+	d, ok, err := b.prepare(topic, initial, nil)
+	if !ok {
+		return nil, err
+	}
+
+	d.reduction = &reduction{v: d.value}
+	rb := &Bus{core: b.core, prefix: b.prefix, teed: b.teed, origin: b.origin, sync: true}
+	_, err = rb.publish(d, 0)
+	return d.reduction.value(), err
+}
+
+// reduction holds the value being passed from handler to handler by
+// PublishReduce.
+type reduction struct {
+	lock sync.Mutex
+	v    interface{}
+}
+
+// value returns the value produced by the handlers so far.
+func (r *reduction) value() interface{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.v
+}
+
+// reduceStep passes the value produced by the handlers before it to its
+// handler in place of the published value, keeping the result if the handler
+// was subscribed with SubscribeReduce.
+type reduceStep struct {
+	h Handler
+	r *reduction
+}
+
+func (h *reduceStep) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *reduceStep) OnErr(b *Bus, t, v interface{}) error {
+	h.r.lock.Lock()
+	defer h.r.lock.Unlock()
+
+	if rv, ok := reduce(b, h.h, t, h.r.v); ok {
+		h.r.v = rv
+		return nil
+	}
+	v = h.r.v
+	if b.clone != nil {
+		v = b.clone(v)
+	}
+	return call(b, h.h, t, v)
+}
+
+func (h *reduceStep) unwrap() Handler {
+	return h.h
+}
+
+// reduce passes the value to the handler, or the first handler it wraps, if
+// it was subscribed with SubscribeReduce, returning its result. It returns
+// false if there is no such handler.
+func reduce(b *Bus, h Handler, t, v interface{}) (interface{}, bool) {
+	for h != nil {
+		switch w := h.(type) {
+		case *reduceHandler:
+			return w.h.On(b, t, v), true
+		case *nsHandler:
+			b, t, h = w.b, w.b.unqualify(t), w.h
+		case wrapper:
+			h = w.unwrap()
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// SubscribeReduce causes the passed ReduceHandler to be called when data is
+// published to the named topic on the default Bus.
+func SubscribeReduce(topic interface{}, h ReduceHandler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeReduce(topic, h)
+}
+
+// PublishReduce passes initial through the handlers of the named topic on the
+// default Bus, returning the value produced by the last of them.
+func PublishReduce(topic interface{}, initial interface{}) (interface{}, error) {
+	return getDefaultBus().PublishReduce(topic, initial)
+}
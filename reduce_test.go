@@ -0,0 +1,87 @@
+package bus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishReduce(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeReduce("test", ReduceFunc(func(b *Bus, tp, v interface{}) interface{} {
+		return v.(string) + "a"
+	}))
+	h := &mockHandler{}
+	bus.Subscribe("test", h)
+	bus.SubscribeFilter("test", func(v interface{}) bool {
+		return false
+	}, &mockHandler{})
+	bus.SubscribeReduce("test", ReduceFunc(func(b *Bus, tp, v interface{}) interface{} {
+		return strings.ToUpper(v.(string))
+	}))
+
+	v, err := bus.PublishReduce("test", "x")
+	assert.NoError(t, err)
+	assert.Equal(t, "XA", v)
+	assert.Equal(t, "xa", h.v, "plain handlers should see the current value")
+	assert.Equal(t, uint64(3), bus.Stats()["test"].DeliverCount)
+
+	v, err = bus.PublishReduce("other", "x")
+	assert.NoError(t, err)
+	assert.Equal(t, "x", v, "initial value should be returned without handlers")
+}
+
+func TestPublishReduceErrors(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	bus.Subscribe("test", &failingHandler{err: errFail})
+	bus.SubscribeReduce("test", ReduceFunc(func(b *Bus, tp, v interface{}) interface{} {
+		return v.(int) + 1
+	}))
+
+	v, err := bus.PublishReduce("test", 1)
+	assert.True(t, errors.Is(err, errFail))
+	assert.Equal(t, 2, v, "handlers after an error should still be called")
+}
+
+func TestPublishReduceNamespace(t *testing.T) {
+	bus := NewBus()
+	ns := bus.Namespace("app")
+	var topic interface{}
+	ns.SubscribeReduce("test", ReduceFunc(func(b *Bus, tp, v interface{}) interface{} {
+		topic = tp
+		return v.(int) * 2
+	}))
+
+	v, err := bus.PublishReduce("app.test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, v)
+	assert.Equal(t, "test", topic)
+
+	n, err := bus.Publish("app.test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "reduce handlers should also receive published values")
+}
+
+func TestPublishReduceDelivery(t *testing.T) {
+	dispatched := 0
+	bus := NewBus(WithMaxDepth(3), WithDispatcher(DispatcherFunc(func(b *Bus, hs []Handler, tp, v interface{}, async bool) (int, error) {
+		dispatched++
+		return DefaultDispatcher.Dispatch(b, hs, tp, v, async)
+	})))
+	var nested error
+	bus.SubscribeReduce("test", ReduceFunc(func(b *Bus, tp, v interface{}) interface{} {
+		if _, err := b.PublishReduce("test", v); err != nil {
+			nested = err
+		}
+		return v.(int) + 1
+	}))
+
+	v, err := bus.PublishReduce("test", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.True(t, errors.Is(nested, ErrMaxDepth), "a reducer republishing should be stopped by the maximum depth")
+	assert.Equal(t, 3, dispatched, "the dispatcher should deliver the value")
+}
@@ -0,0 +1,74 @@
+package bus
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// replyTopic is a private topic key used to route replies back to the
+// originator of a request. Each request is assigned a unique id, so reply
+// topics never collide with each other or with user topics.
+type replyTopic struct {
+	id uint64
+}
+
+var lastReplyID atomic.Uint64
+
+// Message wraps a value published by Request, allowing handlers to respond to
+// the requester.
+type Message struct {
+	// Value is the value passed to Request.
+	Value interface{}
+
+	bus     *Bus
+	replyTo replyTopic
+}
+
+// Reply sends v back to the requester. Only the first reply is returned from
+// Request; later replies are discarded.
+func (m *Message) Reply(v interface{}) (int, error) {
+	return m.bus.Publish(m.replyTo, v)
+}
+
+// Request publishes value to the named topic on this Bus wrapped in a
+// *Message, then waits for a handler to respond via Message.Reply. It returns
+// the first reply received, or ErrTimeout if no reply arrives within the
+// given timeout.
+func (b *Bus) Request(topic interface{}, value interface{}, timeout time.Duration) (interface{}, error) {
+	replyTo := replyTopic{id: lastReplyID.Add(1)}
+	replies, unsub := b.SubscribeChan(replyTo, 1)
+	defer b.forgetReply(replyTo, unsub)
+
+	msg := &Message{Value: value, bus: b, replyTo: replyTo}
+	if _, err := b.Publish(topic, msg); err != nil {
+		return nil, err
+	}
+
+	expired, stop := b.after(timeout)
+	defer stop()
+
+	select {
+	case v := <-replies:
+		return v, nil
+	case <-expired:
+		return nil, ErrTimeout
+	}
+}
+
+// forgetReply unsubscribes the requester from the reply topic, then discards
+// the topic's state, so that each request leaves nothing behind. Replies made
+// after the request has returned are published to a topic without handlers.
+func (b *Bus) forgetReply(replyTo replyTopic, unsub UnsubscribeFunc) {
+	unsub()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.states, replyTo)
+}
+
+// Request publishes value to the named topic on the default Bus and waits for
+// the first reply, or until the timeout elapses.
+func Request(topic interface{}, value interface{}, timeout time.Duration) (interface{}, error) {
+	return getDefaultBus().Request(topic, value, timeout)
+}
@@ -0,0 +1,59 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest(t *testing.T) {
+	bus := NewBus()
+	defer bus.SubscribeFunc("double", func(b *Bus, tp, v interface{}) {
+		msg := v.(*Message)
+		msg.Reply(msg.Value.(int) * 2)
+	})()
+
+	v, err := bus.Request("double", 21, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, v)
+	assert.Equal(t, 1, len(bus.topics), "reply subscription should be removed")
+}
+
+func TestRequestAsync(t *testing.T) {
+	bus := NewBus()
+	defer bus.SubscribeFunc("echo", func(b *Bus, tp, v interface{}) {
+		msg := v.(*Message)
+		go msg.Reply(msg.Value)
+	})()
+
+	v, err := bus.Request("echo", "hello", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", v)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	bus := NewBus()
+	defer bus.SubscribeFunc("ignore", func(b *Bus, tp, v interface{}) {
+	})()
+
+	v, err := bus.Request("ignore", "hello", 10*time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+	assert.Nil(t, v)
+	assert.Equal(t, 1, len(bus.topics), "reply subscription should be removed")
+}
+
+func TestRequestDeclaredTopics(t *testing.T) {
+	bus := NewBus(WithDeclaredTopics("double"))
+	defer bus.SubscribeFunc("double", func(b *Bus, tp, v interface{}) {
+		msg := v.(*Message)
+		msg.Reply(msg.Value.(int) * 2)
+	})()
+
+	for i := 0; i < 100; i++ {
+		v, err := bus.Request("double", i, time.Second)
+		assert.NoError(t, err, "reply topics should not need declaring")
+		assert.Equal(t, 2*i, v)
+	}
+	assert.Len(t, bus.Stats(), 1, "reply topics should leave no state behind")
+}
@@ -0,0 +1,169 @@
+package bus
+
+import (
+	"reflect"
+	"sync"
+)
+
+// HandlerStatus is the outcome of delivering a value to a handler.
+type HandlerStatus int
+
+const (
+	// Delivered means the handler was called. For handlers called
+	// asynchronously, it means only that the call was started.
+	Delivered HandlerStatus = iota
+
+	// Skipped means the handler declined the value, for example because it
+	// was rejected by a filter or the subscription was paused.
+	Skipped
+
+	// Errored means the handler was called and reported an error.
+	Errored
+)
+
+// String returns the name of the status.
+func (s HandlerStatus) String() string {
+	switch s {
+	case Delivered:
+		return "delivered"
+	case Skipped:
+		return "skipped"
+	case Errored:
+		return "errored"
+	}
+	return "unknown"
+}
+
+// HandlerResult is the outcome of delivering a value to one handler.
+type HandlerResult struct {
+	// HandlerType is the type of the handler, as passed to Subscribe or to
+	// the function, such as SubscribeFilter, that subscribed it.
+	HandlerType string
+
+	// Status is what happened when the value was delivered to the handler.
+	Status HandlerStatus
+
+	// Err is the error reported by the handler, if Status is Errored.
+	Err error
+}
+
+// Result describes what happened to a published value.
+type Result struct {
+	// Handlers holds the outcome for each handler the value was delivered
+	// to, in the order the handlers were considered.
+	Handlers []HandlerResult
+}
+
+// Count returns the number of handlers that were called, as returned by
+// Publish.
+func (r Result) Count() int {
+	n := 0
+	for _, h := range r.Handlers {
+		if h.Status != Skipped {
+			n++
+		}
+	}
+	return n
+}
+
+// resultRecorder collects the Result of a publish until it is returned to
+// the publisher, after which handlers still running are no longer recorded.
+type resultRecorder struct {
+	lock   sync.Mutex
+	result Result
+	done   bool
+}
+
+// record adds an entry for the handler to the result, returning its index.
+func (r *resultRecorder) record(h Handler, s HandlerStatus) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.result.Handlers = append(r.result.Handlers, HandlerResult{
+		HandlerType: innerType(h),
+		Status:      s,
+	})
+	return len(r.result.Handlers) - 1
+}
+
+// fail records that the handler with the given index reported an error,
+// unless the result has already been returned.
+func (r *resultRecorder) fail(i int, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.done {
+		r.result.Handlers[i].Status = Errored
+		r.result.Handlers[i].Err = err
+	}
+}
+
+// finish returns the result, after which it is no longer changed.
+func (r *resultRecorder) finish() Result {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.done = true
+	return r.result
+}
+
+// innerType returns the name of the type of the handler the caller
+// subscribed, looking through the wrappers added by the Bus and by functions
+// such as SubscribeFilter.
+func innerType(h Handler) string {
+	for {
+		w, ok := h.(wrapper)
+		if !ok {
+			return reflect.TypeOf(h).String()
+		}
+		h = w.unwrap()
+	}
+}
+
+// resultHandler records in a Result whether its handler reports an error.
+type resultHandler struct {
+	h      Handler
+	result *resultRecorder
+	i      int
+}
+
+func (h *resultHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *resultHandler) OnErr(b *Bus, t, v interface{}) error {
+	err := call(b, h.h, t, v)
+	if err != nil {
+		h.result.fail(h.i, err)
+	}
+	return err
+}
+
+func (h *resultHandler) unwrap() Handler {
+	return h.h
+}
+
+// PublishResult publishes the value to the named topic on this Bus as Publish
+// does, but also returns the outcome for each handler: whether it was called,
+// declined the value, or reported an error. Errors are only known for
+// handlers called synchronously; those called asynchronously are reported as
+// Delivered once they have been started, including those subscribed with
+// SubscribeAsync, and the Result is never changed after it is returned.
+func (b *Bus) PublishResult(topic, value interface{}, flags ...PublishFlag) (Result, error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return Result{}, err
+	}
+
+	fs := flagsOf(flags)
+	d.result = &resultRecorder{}
+	d.resultErrs = fs&(Async|OrderedAsync) == 0
+	_, err = b.publish(d, fs)
+	return d.result.finish(), err
+}
+
+// PublishResult publishes the value to the named topic on the default Bus,
+// returning the outcome for each handler.
+func PublishResult(topic, value interface{}, flags ...PublishFlag) (Result, error) {
+	return getDefaultBus().PublishResult(topic, value, flags...)
+}
@@ -0,0 +1,76 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishResult(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	bus.Subscribe("test", &mockHandler{})
+	bus.SubscribeFilter("test", func(v interface{}) bool {
+		return false
+	}, &mockHandler{})
+	bus.Subscribe("test", &failingHandler{err: errFail})
+
+	r, err := bus.PublishResult("test", 1)
+	assert.True(t, errors.Is(err, errFail))
+	assert.Equal(t, []HandlerResult{
+		{HandlerType: "*bus.mockHandler", Status: Delivered},
+		{HandlerType: "*bus.mockHandler", Status: Skipped},
+		{HandlerType: "*bus.failingHandler", Status: Errored, Err: errFail},
+	}, r.Handlers)
+	assert.Equal(t, 2, r.Count())
+}
+
+func TestPublishResultSingle(t *testing.T) {
+	bus := NewBus()
+	h := bus.SubscribeHandle("test", &mockHandler{})
+
+	r, err := bus.PublishResult("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []HandlerResult{{HandlerType: "*bus.mockHandler", Status: Delivered}}, r.Handlers)
+
+	h.Pause()
+	r, _ = bus.PublishResult("test", 1)
+	assert.Equal(t, Skipped, r.Handlers[0].Status, "paused handler should be skipped")
+	assert.Equal(t, 0, r.Count())
+}
+
+func TestPublishResultAsync(t *testing.T) {
+	bus := NewBus()
+	h := &failingHandler{err: errors.New("fail")}
+	bus.Subscribe("test", h)
+
+	r, err := bus.PublishResult("test", 1, Async)
+	assert.NoError(t, err)
+	assert.Equal(t, []HandlerResult{{HandlerType: "*bus.failingHandler", Status: Delivered}}, r.Handlers)
+	bus.Drain()
+	assert.Equal(t, 1, h.calls)
+
+	r, err = NewBus().PublishResult("test", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, r.Handlers)
+}
+
+func TestPublishResultSubscribeAsync(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	bus.SubscribeAsync("test", ErrHandlerFunc(func(b *Bus, t, v interface{}) error {
+		<-release
+		return errors.New("fail")
+	}))
+	bus.SubscribeTagged("plugin", "test", &mockHandler{})
+
+	r, err := bus.PublishResult("test", 1)
+	assert.NoError(t, err)
+	close(release)
+	bus.Drain()
+	assert.Equal(t, []HandlerResult{
+		{HandlerType: "bus.ErrHandlerFunc", Status: Delivered},
+		{HandlerType: "*bus.mockHandler", Status: Delivered},
+	}, r.Handlers, "the result should not change once returned")
+}
@@ -0,0 +1,121 @@
+package bus
+
+// Retain causes the named topic on this Bus to retain the last value
+// published to it, which is delivered to each handler subscribed to the
+// topic with SubscribeSticky as soon as it subscribes. Values published
+// before Retain is called are not retained.
+func (b *Bus) Retain(topic interface{}) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.stateLocked(b.qualify(topic)).retain = true
+}
+
+// SubscribeSticky causes the passed Handler to be called when data is
+// published to the named topic on this Bus, as Subscribe does. If the topic
+// has a retained value, the handler is first called with that value before
+// SubscribeSticky returns. The value is delivered as by Publish, so the
+// handler is passed a clone of it if the Bus has a clone function, and a
+// panic in the handler is dealt with according to the Bus's PanicPolicy.
+func (b *Bus) SubscribeSticky(topic interface{}, h Handler) UnsubscribeFunc {
+	s, v, ok := b.subscribeRetained(topic, h)
+	if ok {
+		if a, accepts := s.handler.(acceptor); !accepts || a.accept(b, s.topic, v) {
+			b.Deliver(s.handler, s.topic, v)
+		}
+	}
+	return b.unsubscribeFunc(s)
+}
+
+// subscribeRetained subscribes h to the topic as Subscribe does, returning
+// the subscription along with the topic's retained value, if it has one, as
+// of the moment h was subscribed.
+func (b *Bus) subscribeRetained(topic interface{}, h Handler) (*subscription, interface{}, bool) {
+	b.lock.Lock()
+	defer b.unlock()
+
+	s := b.subscribeLocked(topic, h)
+	st := b.states[s.topic]
+	return s, st.retained, st.hasRetained
+}
+
+// GetRetained returns the value retained by the named topic on this Bus,
+// without subscribing to it. It returns false if the topic is not retained
+// or has not been published to since Retain was called. If the Bus was
+// created with WithCloneFunc, a clone of the value is returned.
+func (b *Bus) GetRetained(topic interface{}) (interface{}, bool) {
+	if !b.validTopic(topic) {
+		return nil, false
+	}
+	v, ok := b.retainedValue(b.qualify(topic))
+	if ok && b.clone != nil {
+		v = b.clone(v)
+	}
+	return v, ok
+}
+
+// retainedValue returns the value retained by the topic, if any.
+func (b *Bus) retainedValue(topic interface{}) (interface{}, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	st := b.states[topic]
+	if st == nil || !st.retain || !st.hasRetained {
+		return nil, false
+	}
+	return st.retained, true
+}
+
+// ExportRetained returns the value retained by each retained topic on this
+// Bus, so that they can be saved and restored with ImportRetained. Topics
+// are those of the underlying Bus, including any namespace prefixes. Retained
+// topics that have not yet been published to are not included.
+//
+// The values are returned as published; it is the caller's responsibility to
+// serialize them, and to ensure that they can be.
+func (b *Bus) ExportRetained() map[interface{}]interface{} {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	m := make(map[interface{}]interface{})
+	for t, st := range b.states {
+		if st.retain && st.hasRetained {
+			m[t] = st.retained
+		}
+	}
+	return m
+}
+
+// ImportRetained retains each of the given values on its topic of this Bus,
+// as if it had been published to a topic passed to Retain, without
+// delivering it to any handlers. It is intended to restore the values
+// returned by ExportRetained, typically at startup.
+func (b *Bus) ImportRetained(m map[interface{}]interface{}) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for t, v := range m {
+		st := b.stateLocked(t)
+		st.retain = true
+		st.retained, st.hasRetained = v, true
+	}
+}
+
+// Retain causes the named topic on the default Bus to retain the last value
+// published to it.
+func Retain(topic interface{}) {
+	getDefaultBus().Retain(topic)
+}
+
+// GetRetained returns the value retained by the named topic on the default
+// Bus, without subscribing to it.
+func GetRetained(topic interface{}) (interface{}, bool) {
+	return getDefaultBus().GetRetained(topic)
+}
+
+// SubscribeSticky causes the passed Handler to be called with the retained
+// value of the named topic on the default Bus, if any, and then whenever
+// data is published to it.
+func SubscribeSticky(topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeSticky(topic, h)
+}
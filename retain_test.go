@@ -0,0 +1,138 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeSticky(t *testing.T) {
+	bus := NewBus()
+	bus.Retain("test")
+
+	var got []interface{}
+	record := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	})
+
+	bus.SubscribeSticky("test", record)
+	assert.Empty(t, got, "nothing should be retained before publishing")
+
+	bus.Publish("test", 1)
+	bus.Publish("test", 2)
+	got = nil
+
+	bus.SubscribeSticky("test", record)
+	assert.Equal(t, []interface{}{2}, got, "last value should be delivered")
+}
+
+func TestSubscribeStickyUnretained(t *testing.T) {
+	bus := NewBus()
+	bus.Publish("test", 1)
+
+	called := false
+	bus.SubscribeSticky("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		called = true
+	}))
+	assert.False(t, called)
+}
+
+func TestSubscribeStickyConcurrent(t *testing.T) {
+	bus := NewBus()
+	bus.Retain("test")
+	bus.Publish("test", 0)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 100; i++ {
+			bus.Publish("test", i)
+		}
+	}()
+
+	var lock sync.Mutex
+	var got []interface{}
+	bus.SubscribeSticky("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		lock.Lock()
+		got = append(got, v)
+		lock.Unlock()
+	}))
+	wg.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Contains(t, got, 100, "no value should be missed")
+}
+
+func TestExportImportRetained(t *testing.T) {
+	bus := NewBus()
+	bus.Retain("a")
+	bus.Retain("b")
+	bus.Publish("a", 1)
+	bus.Publish("c", 3)
+
+	m := bus.ExportRetained()
+	assert.Equal(t, map[interface{}]interface{}{"a": 1}, m)
+
+	restored := NewBus()
+	restored.ImportRetained(m)
+	var got interface{}
+	restored.SubscribeSticky("a", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = v
+	}))
+	assert.Equal(t, 1, got)
+
+	restored.Publish("a", 2)
+	assert.Equal(t, map[interface{}]interface{}{"a": 2}, restored.ExportRetained())
+}
+
+func TestGetRetained(t *testing.T) {
+	bus := NewBus(WithCloneFunc(func(v interface{}) interface{} {
+		return append([]int(nil), v.([]int)...)
+	}))
+	bus.Retain("a")
+
+	_, ok := bus.GetRetained("a")
+	assert.False(t, ok, "nothing has been published yet")
+	_, ok = bus.GetRetained("b")
+	assert.False(t, ok, "unretained topics should have no value")
+
+	published := []int{1, 2}
+	bus.Publish("a", published)
+	bus.Publish("b", []int{3})
+	v, ok := bus.GetRetained("a")
+	assert.True(t, ok)
+	assert.Equal(t, []int{1, 2}, v)
+	v.([]int)[0] = 9
+	assert.Equal(t, []int{1, 2}, published, "a clone should be returned")
+
+	_, ok = bus.GetRetained("b")
+	assert.False(t, ok)
+	assert.Equal(t, 0, bus.SubscriberCount("a"), "nothing should be subscribed")
+}
+
+func TestSubscribeStickyDeliver(t *testing.T) {
+	type item struct{ N int }
+	bus := NewBus(WithCloneFunc(func(v interface{}) interface{} {
+		c := *v.(*item)
+		return &c
+	}), WithPanicPolicy(PanicRecover, nil))
+	bus.Retain("test")
+	published := &item{N: 1}
+	bus.Publish("test", published)
+
+	var got *item
+	bus.SubscribeSticky("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = v.(*item)
+	}))
+	assert.Equal(t, 1, got.N)
+	assert.NotSame(t, published, got, "the retained value should be cloned")
+
+	assert.NotPanics(t, func() {
+		bus.SubscribeSticky("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+			panic("boom")
+		}))
+	}, "the panic policy should apply")
+}
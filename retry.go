@@ -0,0 +1,84 @@
+package bus
+
+import (
+	"time"
+)
+
+// retryHandler calls its handler until it succeeds, up to a fixed number of
+// attempts.
+type retryHandler struct {
+	attempts int
+	backoff  time.Duration
+	h        ErrHandler
+}
+
+func (h *retryHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *retryHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *retryHandler) OnErr(b *Bus, t, v interface{}) error {
+	var err error
+	for i := 0; i < h.attempts; i++ {
+		if i > 0 && h.backoff > 0 {
+			b.sleep(h.backoff)
+		}
+		if err = call(b, h.h, t, v); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (h *retryHandler) unwrap() Handler {
+	return h.h
+}
+
+// SubscribeRetry causes the passed ErrHandler to be called when data is
+// published to the named topic on this Bus, calling it again, after waiting
+// for backoff, each time it fails, until it succeeds or has been called
+// attempts times. The error from the last attempt is reported to the
+// publisher as usual. An attempts of less than 1 is treated as 1.
+//
+// Retries are made in the goroutine that called the handler, so a handler
+// retried while publishing synchronously holds up the publisher and the
+// topic's other handlers. Use SubscribeRetryAsync to avoid this.
+func (b *Bus) SubscribeRetry(topic interface{}, attempts int, backoff time.Duration, h ErrHandler) UnsubscribeFunc {
+	return b.Subscribe(topic, newRetryHandler(attempts, backoff, h))
+}
+
+// SubscribeRetryAsync is like SubscribeRetry, but the handler is always
+// called in its own goroutine, as if subscribed with SubscribeAsync, so that
+// retries never hold up the publisher. As with other asynchronously called
+// handlers, the final error is not reported to the publisher.
+func (b *Bus) SubscribeRetryAsync(topic interface{}, attempts int, backoff time.Duration, h ErrHandler) UnsubscribeFunc {
+	return b.SubscribeAsync(topic, newRetryHandler(attempts, backoff, h))
+}
+
+// newRetryHandler returns a handler retrying h as SubscribeRetry describes.
+func newRetryHandler(attempts int, backoff time.Duration, h ErrHandler) *retryHandler {
+	mustHandler(h)
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &retryHandler{attempts: attempts, backoff: backoff, h: h}
+}
+
+// SubscribeRetry causes the passed ErrHandler to be called when data is
+// published to the named topic on the default Bus, retrying it each time it
+// fails up to attempts times.
+func SubscribeRetry(topic interface{}, attempts int, backoff time.Duration, h ErrHandler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeRetry(topic, attempts, backoff, h)
+}
+
+// SubscribeRetryAsync is like SubscribeRetry, but the handler is always
+// called in its own goroutine.
+func SubscribeRetryAsync(topic interface{}, attempts int, backoff time.Duration, h ErrHandler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeRetryAsync(topic, attempts, backoff, h)
+}
@@ -0,0 +1,78 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyHandler is an ErrHandler that fails until it has been called a given
+// number of times.
+type flakyHandler struct {
+	failures int
+	calls    int
+}
+
+func (h *flakyHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *flakyHandler) OnErr(b *Bus, t, v interface{}) error {
+	h.calls++
+	if h.calls <= h.failures {
+		return errors.New("flaky")
+	}
+	return nil
+}
+
+func TestSubscribeRetry(t *testing.T) {
+	bus := NewBus()
+	h := &flakyHandler{failures: 2}
+	bus.SubscribeRetry("test", 3, time.Millisecond, h)
+
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err, "handler should succeed on its last attempt")
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 3, h.calls)
+}
+
+func TestSubscribeRetryAccept(t *testing.T) {
+	bus := NewBus()
+	h := &flakyHandler{}
+	bus.SubscribeRetry("test", 3, 0, NewRateLimitedHandler(time.Hour, h))
+
+	bus.Publish("test", 1)
+	n, err := bus.Publish("test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "values declined by the handler should not be counted")
+	assert.Equal(t, 1, h.calls)
+}
+
+func TestSubscribeRetryExhausted(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	h := &failingHandler{err: errFail}
+	bus.SubscribeRetry("test", 3, 0, h)
+
+	_, err := bus.Publish("test", 1)
+	var herr *HandlerError
+	assert.True(t, errors.As(err, &herr))
+	assert.Equal(t, errFail, herr.Err)
+	assert.Equal(t, 3, h.calls)
+}
+
+func TestSubscribeRetryAsync(t *testing.T) {
+	bus := NewBus()
+	h := &flakyHandler{failures: 10}
+	bus.SubscribeRetryAsync("test", 2, 100*time.Millisecond, h)
+
+	start := time.Now()
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err, "async errors are not reported")
+	assert.Equal(t, 1, n)
+	assert.Less(t, time.Since(start), 50*time.Millisecond, "publish should not wait for retries")
+	bus.Drain()
+	assert.Equal(t, 2, h.calls)
+}
@@ -0,0 +1,188 @@
+package bus
+
+import (
+	"sort"
+	"time"
+)
+
+// ScheduleID identifies a publish scheduled with PublishAfter.
+type ScheduleID uint64
+
+// ScheduledPublish describes a publish scheduled with PublishAfter that has
+// not yet been made.
+type ScheduledPublish struct {
+	// ID identifies the publish to CancelScheduled.
+	ID ScheduleID
+
+	// Topic is the topic the value is to be published to, as stored by the
+	// underlying Bus.
+	Topic interface{}
+
+	// FireAt is the time at which the publish is due to be made.
+	FireAt time.Time
+}
+
+// scheduledPublish is a publish waiting for its timer to fire.
+type scheduledPublish struct {
+	ScheduledPublish
+	timer Timer
+	epoch uint64
+}
+
+// PublishAfter publishes the value to the named topic on this Bus, as if by
+// Publish with the same flags, once the duration has elapsed. It returns a
+// function that cancels the publish if called before then.
+//
+// Drain waits for scheduled publishes to be made, and Close cancels any that
+// have not yet been made.
+func (b *Bus) PublishAfter(d time.Duration, topic, value interface{}, flags ...PublishFlag) (cancel func()) {
+	return b.PublishAfterFunc(d, topic, value, nil, flags...)
+}
+
+// PublishAfterFunc is like PublishAfter, but done, if not nil, is called with
+// the result of the publish once it has been made. It is not called if the
+// publish is cancelled, or if the Bus has already been closed.
+func (b *Bus) PublishAfterFunc(d time.Duration, topic, value interface{}, done func(n int, err error), flags ...PublishFlag) (cancel func()) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		return func() {}
+	}
+
+	b.lastSchedID++
+	sp := &scheduledPublish{
+		ScheduledPublish: ScheduledPublish{ID: b.lastSchedID, Topic: b.qualify(topic), FireAt: b.clock.Now().Add(d)},
+		epoch:            b.async.add(),
+	}
+	if b.scheduled == nil {
+		b.scheduled = make(map[*scheduledPublish]struct{})
+	}
+	b.scheduled[sp] = struct{}{}
+	sp.timer = b.clock.AfterFunc(d, func() {
+		if !b.unschedule(sp) {
+			return
+		}
+		defer b.async.done(sp.epoch)
+		n, err := b.Publish(topic, value, flags...)
+		if done != nil {
+			done(n, err)
+		}
+	})
+
+	return func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		b.cancelScheduledPublishLocked(sp)
+	}
+}
+
+// PendingScheduled returns the publishes scheduled on this Bus with
+// PublishAfter that have not yet been made or cancelled, in the order they
+// are due. As with Stats, a namespace reports those of the underlying Bus as
+// a whole.
+func (b *Bus) PendingScheduled() []ScheduledPublish {
+	b.lock.RLock()
+	sps := make([]ScheduledPublish, 0, len(b.scheduled))
+	for sp := range b.scheduled {
+		sps = append(sps, sp.ScheduledPublish)
+	}
+	b.lock.RUnlock()
+
+	sort.Slice(sps, func(i, j int) bool {
+		if !sps[i].FireAt.Equal(sps[j].FireAt) {
+			return sps[i].FireAt.Before(sps[j].FireAt)
+		}
+		return sps[i].ID < sps[j].ID
+	})
+	return sps
+}
+
+// CancelScheduled cancels the scheduled publish with the given ID, returning
+// false if it has already been made or cancelled.
+func (b *Bus) CancelScheduled(id ScheduleID) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for sp := range b.scheduled {
+		if sp.ID == id {
+			return b.cancelScheduledPublishLocked(sp)
+		}
+	}
+	return false
+}
+
+// CancelScheduledTopic cancels every scheduled publish to the given topic on
+// this Bus that has not yet been made, returning the number cancelled.
+func (b *Bus) CancelScheduledTopic(topic interface{}) int {
+	topic = b.qualify(topic)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	n := 0
+	for sp := range b.scheduled {
+		if sp.Topic == topic && b.cancelScheduledPublishLocked(sp) {
+			n++
+		}
+	}
+	return n
+}
+
+// cancelScheduledPublishLocked cancels the scheduled publish, reporting
+// whether it was still waiting to be made. It must be called with the write
+// lock held.
+func (b *Bus) cancelScheduledPublishLocked(sp *scheduledPublish) bool {
+	if _, ok := b.scheduled[sp]; !ok {
+		return false
+	}
+	delete(b.scheduled, sp)
+	sp.timer.Stop()
+	b.async.done(sp.epoch)
+	return true
+}
+
+// unschedule removes the scheduled publish from the Bus, reporting whether
+// it was still waiting to be made.
+func (b *Bus) unschedule(sp *scheduledPublish) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	_, ok := b.scheduled[sp]
+	delete(b.scheduled, sp)
+	return ok
+}
+
+// cancelScheduledLocked cancels every publish waiting to be made. It must be
+// called with the write lock held.
+func (b *Bus) cancelScheduledLocked() {
+	for sp := range b.scheduled {
+		sp.timer.Stop()
+		b.async.done(sp.epoch)
+	}
+	b.scheduled = nil
+}
+
+// PublishAfter publishes the value to the named topic on the default Bus once
+// the duration has elapsed, returning a function that cancels the publish.
+func PublishAfter(d time.Duration, topic, value interface{}, flags ...PublishFlag) (cancel func()) {
+	return getDefaultBus().PublishAfter(d, topic, value, flags...)
+}
+
+// PendingScheduled returns the publishes scheduled on the default Bus that
+// have not yet been made or cancelled.
+func PendingScheduled() []ScheduledPublish {
+	return getDefaultBus().PendingScheduled()
+}
+
+// CancelScheduled cancels the scheduled publish with the given ID on the
+// default Bus.
+func CancelScheduled(id ScheduleID) bool {
+	return getDefaultBus().CancelScheduled(id)
+}
+
+// CancelScheduledTopic cancels every scheduled publish to the given topic on
+// the default Bus.
+func CancelScheduledTopic(topic interface{}) int {
+	return getDefaultBus().CancelScheduledTopic(topic)
+}
@@ -0,0 +1,94 @@
+package bus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishAfter(t *testing.T) {
+	bus := NewBus()
+	var count atomic.Int32
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		count.Add(1)
+	})
+
+	result := make(chan int, 1)
+	start := time.Now()
+	bus.PublishAfterFunc(10*time.Millisecond, "test", 1, func(n int, err error) {
+		assert.NoError(t, err)
+		result <- n
+	})
+	assert.Equal(t, int32(0), count.Load(), "publish should be delayed")
+
+	assert.Equal(t, 1, bus.Drain(), "drain should wait for the publish")
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	assert.Equal(t, int32(1), count.Load())
+	assert.Equal(t, 1, <-result)
+}
+
+func TestPublishAfterCancel(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		t.Error("cancelled publish should not be made")
+	})
+
+	cancel := bus.PublishAfter(10*time.Millisecond, "test", 1)
+	cancel()
+	cancel()
+	assert.Equal(t, 0, bus.Drain())
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestPublishAfterClose(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		t.Error("publish should be cancelled by close")
+	})
+
+	cancel := bus.PublishAfter(time.Second, "test", 1)
+	start := time.Now()
+	assert.NoError(t, bus.Close())
+	assert.Less(t, time.Since(start), time.Second/2, "close should not wait for the timer")
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	bus.PublishAfter(0, "test", 2)
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestPendingScheduled(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	bus := NewBus(WithClock(c))
+	start := c.Now()
+	h := &mockHandler{}
+	bus.Subscribe("a", h)
+
+	bus.PublishAfter(2*time.Minute, "a", 1)
+	bus.PublishAfter(time.Minute, "b", 2)
+	bus.Namespace("ns").PublishAfter(3*time.Minute, "a", 3)
+	bus.PublishAfter(4*time.Minute, "a", 4)
+
+	sps := bus.PendingScheduled()
+	assert.Len(t, sps, 4)
+	assert.Equal(t, []interface{}{"b", "a", "ns.a", "a"}, []interface{}{sps[0].Topic, sps[1].Topic, sps[2].Topic, sps[3].Topic})
+	assert.Equal(t, start.Add(time.Minute), sps[0].FireAt)
+
+	assert.True(t, bus.CancelScheduled(sps[0].ID))
+	assert.False(t, bus.CancelScheduled(sps[0].ID))
+	assert.Equal(t, 2, bus.CancelScheduledTopic("a"))
+	assert.Equal(t, 0, bus.CancelScheduledTopic("a"))
+
+	sps = bus.PendingScheduled()
+	assert.Len(t, sps, 1)
+	assert.Equal(t, "ns.a", sps[0].Topic)
+
+	// Publishes that have been made are pruned
+	c.Advance(time.Hour)
+	assert.Empty(t, bus.PendingScheduled())
+	assert.Nil(t, h.v, "cancelled publishes should not be made")
+	assert.False(t, bus.CancelScheduled(sps[0].ID))
+	assert.Equal(t, 0, bus.Drain())
+}
@@ -0,0 +1,95 @@
+package bus
+
+// SeqPolicy determines what PublishSeq does with values published out of
+// order.
+type SeqPolicy int
+
+const (
+	// SeqFlag publishes values out of order, marking them as such in the
+	// PublishMeta passed to MetaHandlers.
+	SeqFlag SeqPolicy = iota
+
+	// SeqReject refuses to publish values out of order, returning
+	// ErrOutOfOrder.
+	SeqReject
+)
+
+// PublishSeq publishes the value to the named topic on this Bus as Publish
+// does with the same flags, along with a sequence number given by the
+// publisher, so that subscribers can detect gaps and values delivered out of
+// order. MetaHandlers are passed the number as the SourceSeq of the publish.
+//
+// A value is out of order if its number is not greater than that of the
+// last value published to the topic with PublishSeq. Such values are flagged
+// as OutOfOrder in the PublishMeta, or rejected with ErrOutOfOrder if the
+// Bus was created with WithSeqPolicy(SeqReject), and do not change the
+// topic's LastSeq. Nor do values that fail to be published, such as those
+// rejected by the topic's rate limit or payload type.
+func (b *Bus) PublishSeq(topic interface{}, seq uint64, value interface{}, flags ...PublishFlag) (int, error) {
+	if !b.validTopic(topic) {
+		return 0, ErrInvalidTopic
+	}
+
+	st := b.state(b.qualify(topic))
+	if b.seqPolicy == SeqReject && b.outOfOrder(st, seq, false) {
+		return 0, ErrOutOfOrder
+	}
+
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return 0, err
+	}
+
+	// The number is only taken once the publish is known to go ahead, and
+	// checked again in case another publish took it meanwhile
+	outOfOrder := b.outOfOrder(st, seq, true)
+	if outOfOrder && b.seqPolicy == SeqReject {
+		return 0, ErrOutOfOrder
+	}
+	if d.meta != nil {
+		d.meta.SourceSeq, d.meta.OutOfOrder = seq, outOfOrder
+	}
+	return b.publish(d, flagsOf(flags))
+}
+
+// outOfOrder reports whether seq is not greater than the last sequence number
+// published to the topic, making it the topic's last number if it is greater
+// and take is set.
+func (b *Bus) outOfOrder(st *topicState, seq uint64, take bool) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if st.hasSeq && seq <= st.lastSeq {
+		return true
+	}
+	if take {
+		st.lastSeq, st.hasSeq = seq, true
+	}
+	return false
+}
+
+// LastSeq returns the greatest sequence number published to the named topic
+// on this Bus with PublishSeq, or zero if there is none.
+func (b *Bus) LastSeq(topic interface{}) uint64 {
+	topic = b.qualify(topic)
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	if st := b.states[topic]; st != nil {
+		return st.lastSeq
+	}
+	return 0
+}
+
+// PublishSeq publishes the value to the named topic on the default Bus along
+// with a sequence number given by the publisher.
+func PublishSeq(topic interface{}, seq uint64, value interface{}, flags ...PublishFlag) (int, error) {
+	return getDefaultBus().PublishSeq(topic, seq, value, flags...)
+}
+
+// LastSeq returns the greatest sequence number published to the named topic
+// on the default Bus with PublishSeq.
+func LastSeq(topic interface{}) uint64 {
+	return getDefaultBus().LastSeq(topic)
+}
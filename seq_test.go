@@ -0,0 +1,70 @@
+package bus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishSeq(t *testing.T) {
+	bus := NewBus()
+	h := &metaRecorder{}
+	bus.Subscribe("test", h)
+
+	for _, seq := range []uint64{1, 2, 5, 3, 6} {
+		n, err := bus.PublishSeq("test", seq, seq)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n, "out of order values should be flagged, not dropped")
+	}
+	assert.Equal(t, uint64(6), bus.LastSeq("test"))
+	assert.Equal(t, uint64(0), bus.LastSeq("other"))
+
+	var seqs []uint64
+	var flagged []uint64
+	for _, m := range h.metas {
+		seqs = append(seqs, m.SourceSeq)
+		if m.OutOfOrder {
+			flagged = append(flagged, m.SourceSeq)
+		}
+	}
+	assert.Equal(t, []uint64{1, 2, 5, 3, 6}, seqs)
+	assert.Equal(t, []uint64{3}, flagged)
+
+	bus.Publish("test", 7)
+	assert.Equal(t, uint64(0), h.metas[len(h.metas)-1].SourceSeq, "plain publishes should have no source sequence")
+}
+
+func TestPublishSeqReject(t *testing.T) {
+	bus := NewBus(WithSeqPolicy(SeqReject))
+	h := &mockHandler{}
+	bus.Namespace("ns").Subscribe("test", h)
+
+	_, err := bus.PublishSeq("ns.test", 2, "a")
+	assert.NoError(t, err)
+	for _, seq := range []uint64{2, 1} {
+		n, err := bus.PublishSeq("ns.test", seq, "b")
+		assert.Equal(t, ErrOutOfOrder, err)
+		assert.Equal(t, 0, n)
+	}
+	assert.Equal(t, "a", h.v)
+	assert.Equal(t, uint64(2), bus.Namespace("ns").LastSeq("test"))
+
+	_, err = bus.PublishSeq([]int{}, 1, "c")
+	assert.Equal(t, ErrInvalidTopic, err)
+}
+
+func TestPublishSeqFailed(t *testing.T) {
+	bus := NewBus()
+	bus.RegisterTopicType("test", reflect.TypeOf(0))
+	bus.Subscribe("test", &mockHandler{})
+
+	_, err := bus.PublishSeq("test", 5, "a")
+	assert.True(t, errors.Is(err, ErrPayloadType))
+	assert.Equal(t, uint64(0), bus.LastSeq("test"), "a failed publish should not take its number")
+
+	_, err = bus.PublishSeq("test", 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), bus.LastSeq("test"))
+}
@@ -0,0 +1,66 @@
+package bus
+
+// TopicSettings holds the configuration of a single topic, as returned by
+// TopicConfig and applied by SetTopicConfig. All of the settings may be
+// changed at any time, taking effect from the next publish to the topic;
+// publishes already in progress are unaffected.
+type TopicSettings struct {
+	// HistorySize is the number of values published to the topic that are
+	// recorded for SubscribeReplay, as set by WithHistory. Changing it keeps
+	// as many of the most recent values as fit. Zero disables the history.
+	HistorySize int
+
+	// Serial serializes synchronous publishes to the topic, as
+	// WithSerialTopics does for every topic. It has no effect if the Bus
+	// was created with WithSerialTopics.
+	Serial bool
+
+	// MaxConcurrency limits the number of the topic's handlers that may run
+	// concurrently as a result of publishing with the Async flag, in
+	// addition to any limit set with WithMaxConcurrency. Zero means the
+	// topic's handlers are not limited.
+	MaxConcurrency int
+}
+
+// TopicConfig returns the settings of the named topic on this Bus. The
+// returned settings are a copy; use SetTopicConfig to change them.
+func (b *Bus) TopicConfig(topic interface{}) *TopicSettings {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	s := &TopicSettings{}
+	if st := b.states[b.qualify(topic)]; st != nil {
+		if st.history != nil {
+			s.HistorySize = len(st.history.values)
+		}
+		s.Serial = st.serialize
+		s.MaxConcurrency = cap(st.sem)
+	}
+	return s
+}
+
+// SetTopicConfig applies the settings to the named topic on this Bus.
+func (b *Bus) SetTopicConfig(topic interface{}, s *TopicSettings) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	st := b.stateLocked(b.qualify(topic))
+	st.history = st.history.resize(s.HistorySize)
+	st.serialize = s.Serial
+	if s.MaxConcurrency <= 0 {
+		st.sem = nil
+	} else if cap(st.sem) != s.MaxConcurrency {
+		// Handlers already running release the semaphore they acquired
+		st.sem = make(chan struct{}, s.MaxConcurrency)
+	}
+}
+
+// TopicConfig returns the settings of the named topic on the default Bus.
+func TopicConfig(topic interface{}) *TopicSettings {
+	return getDefaultBus().TopicConfig(topic)
+}
+
+// SetTopicConfig applies the settings to the named topic on the default Bus.
+func SetTopicConfig(topic interface{}, s *TopicSettings) {
+	getDefaultBus().SetTopicConfig(topic, s)
+}
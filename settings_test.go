@@ -0,0 +1,67 @@
+package bus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicConfig(t *testing.T) {
+	bus := NewBus(WithHistory("test", 3))
+	assert.Equal(t, &TopicSettings{HistorySize: 3}, bus.TopicConfig("test"))
+	assert.Equal(t, &TopicSettings{}, bus.TopicConfig("other"))
+
+	for i := 1; i <= 3; i++ {
+		bus.Publish("test", i)
+	}
+	cfg := bus.TopicConfig("test")
+	cfg.HistorySize = 2
+	cfg.Serial = true
+	cfg.MaxConcurrency = 4
+	bus.SetTopicConfig("test", cfg)
+	assert.Equal(t, cfg, bus.TopicConfig("test"))
+
+	var got []interface{}
+	bus.SubscribeReplay("test", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+	assert.Equal(t, []interface{}{2, 3}, got, "shrinking the history should keep the latest values")
+
+	bus.SetTopicConfig("test", &TopicSettings{})
+	assert.Equal(t, &TopicSettings{}, bus.TopicConfig("test"))
+}
+
+func TestTopicConfigMaxConcurrency(t *testing.T) {
+	bus := NewBus()
+	bus.SetTopicConfig("test", &TopicSettings{MaxConcurrency: 2})
+
+	var running, peak atomic.Int32
+	for i := 0; i < 5; i++ {
+		bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+
+			// Nested publishes are not limited by this topic
+			b.Publish("other", v, Async)
+		})
+	}
+	other := make(chan struct{}, 5)
+	bus.SubscribeFunc("other", func(b *Bus, tp, v interface{}) {
+		other <- struct{}{}
+	})
+
+	bus.Publish("test", 1, Async)
+	bus.Drain()
+	bus.Drain() // nested publishes
+	assert.Equal(t, int32(2), peak.Load())
+	assert.Len(t, other, 5)
+}
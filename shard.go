@@ -0,0 +1,87 @@
+package bus
+
+// shardHandler partitions the values it receives between a number of ordered
+// queues by key.
+type shardHandler struct {
+	key    func(v interface{}) uint64
+	queues []*orderedQueue
+	shards [][]Handler
+}
+
+func (h *shardHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+// OnErr queues the value on its shard, failing with ErrBusClosed if the
+// shards have been stopped, or ErrOrderedQueueFull if called from the
+// shard's own goroutine while its queue is full, in which case the value is
+// counted as dropped.
+func (h *shardHandler) OnErr(b *Bus, t, v interface{}) error {
+	i := h.key(v) % uint64(len(h.queues))
+	if err := b.push(h.queues[i], b, h.shards[i], t, v); err != nil {
+		b.state(b.qualify(t)).stats.drop(1)
+		return err
+	}
+	return nil
+}
+
+// stop stops the shards accepting values, leaving their goroutines to handle
+// those already queued and exit.
+func (h *shardHandler) stop() {
+	for _, q := range h.queues {
+		q.stop()
+	}
+}
+
+// SubscribeSharded causes h to be called for each value published to the
+// named topic on this Bus, spreading values between the given number of
+// shards by key. Each shard has its own goroutine, which calls h for the
+// values of that shard one at a time, in the order they were published, so
+// values with the same key are never handled concurrently or out of order.
+// If shards is less than 1, a single shard is used.
+//
+// Publishing blocks while the queue of a value's shard is full, so h should
+// not publish to the topic itself. Values queued when the returned function
+// is called are handled before it returns, after which the shard goroutines
+// exit. Removing the subscription by other means, such as UnsubscribeID,
+// RemoveTopic or Reset, also stops the shards, leaving their goroutines to
+// handle the values already queued before exiting. Values queued on the
+// shards are waited for by Drain and Close, which then stops them.
+func (b *Bus) SubscribeSharded(topic interface{}, shards int, key func(v interface{}) uint64, h func(shard int, v interface{})) UnsubscribeFunc {
+	if shards < 1 {
+		shards = 1
+	}
+
+	sh := &shardHandler{
+		key:    key,
+		queues: make([]*orderedQueue, shards),
+		shards: make([][]Handler, shards),
+	}
+	for i := range sh.queues {
+		sh.queues[i] = b.newOrderedQueue()
+		sh.shards[i] = []Handler{shardFunc(i, h)}
+	}
+
+	unsub := b.subscribeStopping(topic, sh, sh.stop)
+	return func() bool {
+		ok := unsub()
+		for _, q := range sh.queues {
+			q.close()
+		}
+		return ok
+	}
+}
+
+// shardFunc returns a handler that calls h with the given shard.
+func shardFunc(shard int, h func(shard int, v interface{})) Handler {
+	return HandlerFunc(func(b *Bus, t, v interface{}) {
+		h(shard, v)
+	})
+}
+
+// SubscribeSharded causes h to be called for each value published to the
+// named topic on the default Bus, spreading values between the given number
+// of shards by key.
+func SubscribeSharded(topic interface{}, shards int, key func(v interface{}) uint64, h func(shard int, v interface{})) UnsubscribeFunc {
+	return getDefaultBus().SubscribeSharded(topic, shards, key, h)
+}
@@ -0,0 +1,96 @@
+package bus
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type keyed struct {
+	key uint64
+	seq int
+}
+
+func TestSubscribeSharded(t *testing.T) {
+	bus := NewBus()
+
+	var lock sync.Mutex
+	got := map[uint64][]int{}
+	shardOf := map[uint64]int{}
+	unsub := bus.SubscribeSharded("test", 4, func(v interface{}) uint64 {
+		return v.(keyed).key
+	}, func(shard int, v interface{}) {
+		k := v.(keyed)
+		time.Sleep(time.Microsecond)
+		lock.Lock()
+		defer lock.Unlock()
+		got[k.key] = append(got[k.key], k.seq)
+		if s, ok := shardOf[k.key]; ok {
+			assert.Equal(t, s, shard, "key should always go to the same shard")
+		}
+		shardOf[k.key] = shard
+	})
+
+	for i := 0; i < 20; i++ {
+		for k := uint64(0); k < 8; k++ {
+			n, err := bus.Publish("test", keyed{key: k, seq: i})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, n)
+		}
+	}
+	bus.Drain()
+
+	lock.Lock()
+	for k := uint64(0); k < 8; k++ {
+		if assert.Len(t, got[k], 20) {
+			for i, seq := range got[k] {
+				assert.Equal(t, i, seq, "values with the same key should stay in order")
+			}
+		}
+	}
+	lock.Unlock()
+
+	assert.True(t, unsub())
+	assert.Equal(t, 0, bus.SubscriberCount("test"))
+}
+
+func TestSubscribeShardedUnsubscribe(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	bus := NewBus()
+	count := 0
+	unsub := bus.SubscribeSharded("test", 3, func(v interface{}) uint64 {
+		return uint64(v.(int))
+	}, func(shard int, v interface{}) {
+		count++
+	})
+	bus.Publish("test", 1)
+	unsub()
+	assert.Equal(t, 1, count, "queued values should be handled on unsubscribe")
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, before, runtime.NumGoroutine(), "shard workers should exit")
+}
+
+func TestSubscribeShardedRemoveTopic(t *testing.T) {
+	bus := NewBus()
+	before := runtime.NumGoroutine()
+	bus.SubscribeSharded("test", 4, func(v interface{}) uint64 {
+		return uint64(v.(int))
+	}, func(shard int, v interface{}) {})
+	h := bus.ListSubscriptions()[0].Handler
+
+	assert.Equal(t, 1, bus.RemoveTopic("test"))
+	waitGoroutines(before)
+	assert.True(t, runtime.NumGoroutine() <= before, "removing the topic should stop the shards")
+
+	// A value reaching the stopped shards is reported rather than lost
+	assert.ErrorIs(t, bus.Deliver(h, "test", 1), ErrBusClosed)
+	assert.Equal(t, uint64(1), bus.Stats()["test"].DroppedCount)
+}
@@ -0,0 +1,126 @@
+package bus
+
+import (
+	"hash/maphash"
+)
+
+// ShardedBus spreads its topics between a number of Buses, so that
+// subscribing and publishing to different topics do not contend for the
+// same lock. Each topic belongs to the shard chosen by a hash of the topic,
+// so all of its handlers are subscribed on the same Bus.
+//
+// Handlers are passed the shard's Bus, and publishes made through it reach
+// only the topics of that shard; handlers that publish to other topics
+// should do so through the ShardedBus.
+type ShardedBus struct {
+	seed   maphash.Seed
+	shards []*Bus
+}
+
+// NewShardedBus creates a ShardedBus with the given number of shards, each a
+// Bus configured with the given options. If shards is less than 1, a single
+// shard is used.
+func NewShardedBus(shards int, opts ...BusOption) *ShardedBus {
+	if shards < 1 {
+		shards = 1
+	}
+	sb := &ShardedBus{seed: maphash.MakeSeed(), shards: make([]*Bus, shards)}
+	for i := range sb.shards {
+		sb.shards[i] = NewBus(opts...)
+	}
+	return sb
+}
+
+// Shard returns the Bus owning the given topic.
+func (sb *ShardedBus) Shard(topic interface{}) *Bus {
+	if len(sb.shards) == 1 || !validTopic(topic) {
+		// Invalid topics are rejected by the Bus they are passed to
+		return sb.shards[0]
+	}
+	var h uint64
+	switch t := topic.(type) {
+	case string:
+		h = maphash.String(sb.seed, t)
+	default:
+		h = maphash.Comparable(sb.seed, topic)
+	}
+	return sb.shards[h%uint64(len(sb.shards))]
+}
+
+// Subscribe causes the passed Handler to be called when data is published to
+// the named topic, returning a function that can be called to unsubscribe
+// the handler.
+func (sb *ShardedBus) Subscribe(topic interface{}, h Handler) UnsubscribeFunc {
+	return sb.Shard(topic).Subscribe(topic, h)
+}
+
+// SubscribeFunc registers the handler function on the given topic, returning
+// a function that can be called to deregister itself.
+func (sb *ShardedBus) SubscribeFunc(topic interface{}, h func(b *Bus, t, v interface{})) UnsubscribeFunc {
+	return sb.Shard(topic).SubscribeFunc(topic, h)
+}
+
+// SubscribeAll causes the passed Handler to be called whenever data is
+// published to any topic, by subscribing it on every shard. It returns a
+// function that can be called to unsubscribe the handler from all of them.
+func (sb *ShardedBus) SubscribeAll(h Handler) UnsubscribeFunc {
+	unsubs := make([]UnsubscribeFunc, len(sb.shards))
+	for i, b := range sb.shards {
+		unsubs[i] = b.SubscribeAll(h)
+	}
+	return func() bool {
+		ok := false
+		for _, unsub := range unsubs {
+			if unsub() {
+				ok = true
+			}
+		}
+		return ok
+	}
+}
+
+// Unsubscribe removes the specified handler from the given topic, returning
+// true on success.
+func (sb *ShardedBus) Unsubscribe(topic interface{}, h Handler) bool {
+	return sb.Shard(topic).Unsubscribe(topic, h)
+}
+
+// RemoveTopic unsubscribes all handlers from the given topic, returning the
+// number of handlers that were removed.
+func (sb *ShardedBus) RemoveTopic(topic interface{}) int {
+	return sb.Shard(topic).RemoveTopic(topic)
+}
+
+// Publish sends the given value to all handlers subscribed to the named
+// topic, as Bus.Publish does.
+func (sb *ShardedBus) Publish(topic interface{}, value interface{}, flags ...PublishFlag) (int, error) {
+	return sb.Shard(topic).Publish(topic, value, flags...)
+}
+
+// SubscriberCount returns the number of handlers subscribed to the given
+// topic.
+func (sb *ShardedBus) SubscriberCount(topic interface{}) int {
+	return sb.Shard(topic).SubscriberCount(topic)
+}
+
+// Drain blocks until the asynchronously called handlers of every shard have
+// returned, returning the number of handlers it waited for.
+func (sb *ShardedBus) Drain() int {
+	n := 0
+	for _, b := range sb.shards {
+		n += b.Drain()
+	}
+	return n
+}
+
+// Close closes every shard, as Bus.Close does. It returns ErrBusClosed if
+// any of the shards had already been closed.
+func (sb *ShardedBus) Close() error {
+	var err error
+	for _, b := range sb.shards {
+		if b.Close() != nil {
+			err = ErrBusClosed
+		}
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package bus
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedBus(t *testing.T) {
+	sb := NewShardedBus(4)
+	hs := make([]*mockHandler, 20)
+	for i := range hs {
+		hs[i] = &mockHandler{}
+		sb.Subscribe(fmt.Sprintf("topic%d", i), hs[i])
+	}
+	all := make(chan interface{}, 20)
+	unsubAll := sb.SubscribeAll(HandlerFunc(func(b *Bus, tp, v interface{}) {
+		all <- tp
+	}))
+
+	shards := map[*Bus]bool{}
+	for i, h := range hs {
+		topic := fmt.Sprintf("topic%d", i)
+		assert.Same(t, sb.Shard(topic), sb.Shard(topic), "topics should always map to the same shard")
+		shards[sb.Shard(topic)] = true
+
+		n, err := sb.Publish(topic, i)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, i, h.v)
+		assert.Equal(t, topic, <-all)
+	}
+	assert.Greater(t, len(shards), 1, "topics should be spread between shards")
+
+	assert.True(t, unsubAll())
+	assert.True(t, sb.Unsubscribe("topic0", hs[0]))
+	assert.Equal(t, 0, sb.SubscriberCount("topic0"))
+	assert.Equal(t, 1, sb.RemoveTopic("topic1"))
+
+	assert.NoError(t, sb.Close())
+	_, err := sb.Publish("topic2", 1)
+	assert.Equal(t, ErrBusClosed, err)
+	assert.Equal(t, ErrBusClosed, sb.Close())
+}
+
+func TestShardedBusTopics(t *testing.T) {
+	sb := NewShardedBus(3)
+	type key struct{ id int }
+	h := &mockHandler{}
+	sb.Subscribe(key{1}, h)
+	sb.Subscribe(42, h)
+
+	n, _ := sb.Publish(key{1}, "a")
+	assert.Equal(t, 1, n, "struct topics should be routed consistently")
+	n, _ = sb.Publish(42, "b")
+	assert.Equal(t, 1, n)
+
+	_, err := sb.Publish([]int{1}, "c")
+	assert.Equal(t, ErrInvalidTopic, err)
+}
+
+// benchmarkConcurrentTopics measures publishing and subscribing to many
+// topics from many goroutines.
+func benchmarkConcurrentTopics(b *testing.B, subscribe func(topic interface{}, h Handler) UnsubscribeFunc, publish func(topic, value interface{}) (int, error)) {
+	const topics = 64
+	hf := HandlerFunc(func(b *Bus, tp, v interface{}) {})
+	for i := 0; i < topics; i++ {
+		subscribe(i, &hf)
+	}
+
+	var next atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(1))
+		for pb.Next() {
+			topic := i % topics
+			if i%8 == 0 {
+				subscribe(topic, &hf)()
+			} else {
+				publish(topic, i)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkConcurrentTopicsBus(b *testing.B) {
+	bus := NewBus()
+	benchmarkConcurrentTopics(b, bus.Subscribe, func(topic, value interface{}) (int, error) {
+		return bus.Publish(topic, value)
+	})
+}
+
+func BenchmarkConcurrentTopicsShardedBus(b *testing.B) {
+	sb := NewShardedBus(16)
+	benchmarkConcurrentTopics(b, sb.Subscribe, func(topic, value interface{}) (int, error) {
+		return sb.Publish(topic, value)
+	})
+}
@@ -0,0 +1,69 @@
+package bus
+
+import (
+	"sync/atomic"
+)
+
+// TopicStats holds delivery statistics for a single topic.
+type TopicStats struct {
+	// PublishCount is the number of times a value was published to the topic.
+	PublishCount uint64
+	// DeliverCount is the number of times a handler was called with a value
+	// published to the topic.
+	DeliverCount uint64
+	// DroppedCount is the number of values declined or discarded by the
+	// topic's handlers, such as those rejected by a filter or dropped by a
+	// full channel.
+	DroppedCount uint64
+}
+
+// topicStats holds the live, atomically updated counters for a topic.
+type topicStats struct {
+	published atomic.Uint64
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+
+	// sink, if set, is also told about published and dropped values, using
+	// name as the topic.
+	sink MetricsSink
+	name string
+}
+
+// snapshot returns the current values of the counters.
+func (s *topicStats) snapshot() TopicStats {
+	return TopicStats{
+		PublishCount: s.published.Load(),
+		DeliverCount: s.delivered.Load(),
+		DroppedCount: s.dropped.Load(),
+	}
+}
+
+// reset zeroes the counters.
+func (s *topicStats) reset() {
+	s.published.Store(0)
+	s.delivered.Store(0)
+	s.dropped.Store(0)
+}
+
+// Stats returns the delivery statistics of each topic that has been
+// subscribed or published to on this Bus.
+func (b *Bus) Stats() map[interface{}]TopicStats {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	m := make(map[interface{}]TopicStats, len(b.states))
+	for t, st := range b.states {
+		m[t] = st.stats.snapshot()
+	}
+	return m
+}
+
+// ResetStats zeroes the delivery statistics of all topics on this Bus.
+func (b *Bus) ResetStats() {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for _, st := range b.states {
+		st.stats.reset()
+	}
+}
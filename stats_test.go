@@ -0,0 +1,60 @@
+package bus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("test", &mockHandler{})
+	bus.SubscribeFilter("test", func(v interface{}) bool {
+		return v == "pass"
+	}, &mockHandler{})
+
+	bus.Publish("test", "pass")
+	bus.Publish("test", "fail")
+	bus.Publish("empty", "hello")
+
+	stats := bus.Stats()
+	assert.Equal(t, TopicStats{
+		PublishCount: 2,
+		DeliverCount: 3,
+		DroppedCount: 1,
+	}, stats["test"])
+	assert.Equal(t, TopicStats{PublishCount: 1}, stats["empty"])
+
+	bus.ResetStats()
+	assert.Equal(t, TopicStats{}, bus.Stats()["test"])
+}
+
+func TestStatsChanDropped(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeChan("test", 0)
+	bus.Publish("test", "hello")
+	assert.Equal(t, uint64(1), bus.Stats()["test"].DroppedCount)
+}
+
+func TestStatsConcurrent(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bus.Publish("test", j)
+				bus.Publish("other", j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := bus.Stats()
+	assert.Equal(t, uint64(1000), stats["test"].PublishCount)
+	assert.Equal(t, uint64(1000), stats["other"].PublishCount)
+}
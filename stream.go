@@ -0,0 +1,72 @@
+package bus
+
+import (
+	"io"
+)
+
+// topicWriter is an io.Writer publishing each write to a topic.
+type topicWriter struct {
+	b     *Bus
+	topic interface{}
+}
+
+func (w *topicWriter) Write(p []byte) (int, error) {
+	// The caller may reuse p once Write returns, so handlers, which may
+	// hold on to the value, are given their own copy.
+	v := append([]byte(nil), p...)
+	if _, err := w.b.Publish(w.topic, v); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// TopicWriter returns an io.Writer that publishes a copy of each slice of
+// bytes written to it to the named topic on the given Bus, so that the topic
+// can be used as the destination of a logger or other stream. A write fails
+// with the error returned by Publish, if any.
+func TopicWriter(b *Bus, topic interface{}) io.Writer {
+	return &topicWriter{b: b, topic: topic}
+}
+
+// topicReader is an io.ReadCloser reading the values published to a topic.
+type topicReader struct {
+	c     <-chan interface{}
+	unsub UnsubscribeFunc
+	buf   []byte
+}
+
+func (r *topicReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		v, ok := <-r.c
+		if !ok {
+			return 0, io.EOF
+		}
+		switch v := v.(type) {
+		case []byte:
+			r.buf = v
+		case string:
+			r.buf = []byte(v)
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *topicReader) Close() error {
+	r.unsub()
+	return nil
+}
+
+// TopicReader returns an io.ReadCloser that reads the values published to the
+// named topic on the given Bus, such as by a TopicWriter, as a stream of
+// bytes. Values of type []byte and string are read in the order they were
+// published; values of other types are ignored. Up to buffer values are held
+// until they are read, with further values being dropped as for
+// SubscribeChan. Read blocks until a value is available. Closing the reader
+// unsubscribes it, after which Read returns io.EOF once the values already
+// held have been read.
+func TopicReader(b *Bus, topic interface{}, buffer int) io.ReadCloser {
+	c, unsub := b.SubscribeChan(topic, buffer)
+	return &topicReader{c: c, unsub: unsub}
+}
@@ -0,0 +1,54 @@
+package bus
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopicWriter(t *testing.T) {
+	bus := NewBus()
+	c, unsub := bus.SubscribeChan("log", 2)
+	defer unsub()
+
+	w := TopicWriter(bus, "log")
+	buf := []byte("hello")
+	n, err := w.Write(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	copy(buf, "world")
+	fmt.Fprintf(w, "%d", 42)
+
+	assert.Equal(t, []byte("hello"), <-c, "written bytes should be copied")
+	assert.Equal(t, []byte("42"), <-c)
+}
+
+func TestTopicWriterClosed(t *testing.T) {
+	bus := NewBus()
+	bus.Close()
+	n, err := TopicWriter(bus, "log").Write([]byte("hello"))
+	assert.Equal(t, ErrBusClosed, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestTopicReader(t *testing.T) {
+	bus := NewBus()
+	r := TopicReader(bus, "log", 4)
+	w := TopicWriter(bus, "log")
+	w.Write([]byte("hello "))
+	bus.Publish("log", 42)
+	bus.Publish("log", "world")
+
+	p := make([]byte, 4)
+	n, err := r.Read(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "hell", string(p[:n]))
+
+	assert.NoError(t, r.Close())
+	rest, err := io.ReadAll(r)
+	assert.NoError(t, err, "values published before Close should still be read")
+	assert.Equal(t, "o world", string(rest))
+	assert.Equal(t, 0, bus.SubscriberCount("log"))
+}
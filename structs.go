@@ -0,0 +1,146 @@
+package bus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// handlerMethods are the methods of the handler interfaces, which
+// SubscribeStruct does not treat as topic handlers.
+var handlerMethods = map[string]bool{"On": true, "OnErr": true, "OnMeta": true, "OnContext": true, "OnComplete": true}
+
+// busType is the type of the first parameter of a method handling any value.
+var busType = reflect.TypeOf((*Bus)(nil))
+
+// anyType is the type of the topic and value parameters of a method handling
+// any value.
+var anyType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// SubscribeStruct subscribes each exported method of obj whose name is "On"
+// followed by a word starting with an upper case letter to the topic named by
+// the rest of the method name, returning a function that unsubscribes all of
+// them; methods such as Online or Once are left alone. The topic is the name
+// split into words, lowercased and joined with '.', so OnUserSignup handles
+// "user.signup" and OnHTTPRequest handles "http.request".
+//
+// Each such method must take one of two forms:
+//
+//	func(b *Bus, t, v interface{})
+//	func(v T)
+//
+// The first is called with every value published to the topic, like a
+// HandlerFunc. The second is only called with values assignable to T; other
+// values are dropped and not counted as deliveries. The methods On, OnErr,
+// OnMeta, OnContext and OnComplete, which implement the handler interfaces,
+// are ignored.
+//
+// If any method naming a topic takes another form, SubscribeStruct
+// subscribes nothing and returns an error.
+func (b *Bus) SubscribeStruct(obj interface{}) (UnsubscribeFunc, error) {
+	v := reflect.ValueOf(obj)
+	if !v.IsValid() {
+		return nil, ErrNilHandler
+	}
+
+	var topics []string
+	var handlers []Handler
+	for i := 0; i < v.NumMethod(); i++ {
+		name := v.Type().Method(i).Name
+		if !topicMethod(name) {
+			continue
+		}
+		h, err := methodHandler(v.Method(i))
+		if err != nil {
+			return nil, fmt.Errorf("bus: method %s: %w", name, err)
+		}
+		topics = append(topics, methodTopic(name[2:]))
+		handlers = append(handlers, h)
+	}
+
+	unsubs := make([]UnsubscribeFunc, len(handlers))
+	for i, h := range handlers {
+		unsubs[i] = b.Subscribe(topics[i], h)
+	}
+	return func() bool {
+		ok := false
+		for _, unsub := range unsubs {
+			if unsub() {
+				ok = true
+			}
+		}
+		return ok
+	}, nil
+}
+
+// topicMethod reports whether the method with the given name handles a topic:
+// its name is "On" followed by a word starting with an upper case letter, such
+// as OnSignup but not Online, and it does not implement a handler interface.
+func topicMethod(name string) bool {
+	if !strings.HasPrefix(name, "On") || handlerMethods[name] {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[2:])
+	return unicode.IsUpper(r)
+}
+
+// methodHandler returns a Handler that calls the method, which must take
+// one of the forms accepted by SubscribeStruct.
+func methodHandler(m reflect.Value) (Handler, error) {
+	mt := m.Type()
+	if mt.NumOut() != 0 {
+		return nil, fmt.Errorf("unsupported signature %v: must not return values", mt)
+	}
+
+	switch mt.NumIn() {
+	case 3:
+		if mt.In(0) != busType || mt.In(1) != anyType || mt.In(2) != anyType {
+			break
+		}
+		fn := m.Interface().(func(b *Bus, t, v interface{}))
+		return HandlerFunc(fn), nil
+	case 1:
+		typ := mt.In(0)
+		return &filterHandler{
+			filter: func(v interface{}) bool {
+				return assignable(v, typ)
+			},
+			h: HandlerFunc(func(b *Bus, t, v interface{}) {
+				arg := reflect.Zero(typ)
+				if v != nil {
+					arg = reflect.ValueOf(v)
+				}
+				m.Call([]reflect.Value{arg})
+			}),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported signature %v", mt)
+}
+
+// methodTopic converts the name of a method, without its "On" prefix, into
+// a topic, splitting it into lowercase words separated by '.'.
+func methodTopic(name string) string {
+	rs := []rune(name)
+	var sb strings.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			// A word starts at an upper case letter following a lower case
+			// letter or digit, or at the last letter of an acronym
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// SubscribeStruct subscribes each exported method of obj whose name starts
+// with "On" to the matching topic on the default Bus.
+func SubscribeStruct(obj interface{}) (UnsubscribeFunc, error) {
+	return getDefaultBus().SubscribeStruct(obj)
+}
@@ -0,0 +1,82 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signups struct {
+	users []string
+	any   []interface{}
+}
+
+func (s *signups) OnUserSignup(name string) {
+	s.users = append(s.users, name)
+}
+
+func (s *signups) OnHTTPRequest(b *Bus, t, v interface{}) {
+	s.any = append(s.any, v)
+}
+
+func (s *signups) On(b *Bus, t, v interface{}) {
+	panic("handler interface methods should be ignored")
+}
+
+func (s *signups) Other(v int) {}
+
+func (s *signups) Online() bool { return true }
+
+func (s *signups) Once(v int) {}
+
+type badSignature struct{}
+
+func (badSignature) OnUserSignup(a, b string) {}
+
+func TestMethodTopic(t *testing.T) {
+	assert.Equal(t, "user.signup", methodTopic("UserSignup"))
+	assert.Equal(t, "http.request", methodTopic("HTTPRequest"))
+	assert.Equal(t, "get.url", methodTopic("GetURL"))
+	assert.Equal(t, "v2.event", methodTopic("V2Event"))
+	assert.Equal(t, "x", methodTopic("X"))
+}
+
+func TestTopicMethod(t *testing.T) {
+	assert.True(t, topicMethod("OnUserSignup"))
+	assert.True(t, topicMethod("OnÉvénement"))
+	assert.False(t, topicMethod("On"))
+	assert.False(t, topicMethod("OnErr"))
+	assert.False(t, topicMethod("Online"))
+	assert.False(t, topicMethod("Once"))
+	assert.False(t, topicMethod("Other"))
+}
+
+func TestSubscribeStruct(t *testing.T) {
+	bus := NewBus()
+	s := &signups{}
+	unsub, err := bus.SubscribeStruct(s)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, bus.TotalSubscribers(), "only topic methods should be subscribed")
+
+	n, _ := bus.Publish("user.signup", "alice")
+	assert.Equal(t, 1, n)
+	n, _ = bus.Publish("user.signup", 42)
+	assert.Equal(t, 0, n, "values of the wrong type should be dropped")
+	bus.Publish("http.request", 42)
+	assert.Equal(t, []string{"alice"}, s.users)
+	assert.Equal(t, []interface{}{42}, s.any)
+
+	assert.True(t, unsub())
+	assert.False(t, bus.Has("user.signup"))
+	assert.False(t, bus.Has("http.request"))
+	assert.False(t, unsub())
+}
+
+func TestSubscribeStructBadSignature(t *testing.T) {
+	bus := NewBus()
+	unsub, err := bus.SubscribeStruct(badSignature{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "OnUserSignup")
+	assert.Nil(t, unsub)
+	assert.False(t, bus.Has("user.signup"), "nothing should be subscribed")
+}
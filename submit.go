@@ -0,0 +1,51 @@
+package bus
+
+// submitHandler hands each call of its handler to a function that decides
+// where and when it runs.
+type submitHandler struct {
+	submit func(func())
+	h      Handler
+}
+
+func (h *submitHandler) accept(b *Bus, t, v interface{}) bool {
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *submitHandler) On(b *Bus, t, v interface{}) {
+	epoch := b.async.add()
+	h.submit(func() {
+		defer b.async.done(epoch)
+		if err := call(b, h.h, t, v); err != nil {
+			b.reportAsync(&HandlerError{Topic: t, Value: v, Err: err})
+		}
+	})
+}
+
+func (h *submitHandler) unwrap() Handler {
+	return h.h
+}
+
+// SubscribeOn causes the passed Handler to be called when data is published
+// to the named topic on this Bus, but instead of calling it directly, passes
+// a function that calls it to submit, which decides which goroutine runs it,
+// for example by queueing it to be run on a GUI's main loop. Values published
+// without the Async flag are still submitted from the publishing goroutine,
+// so submit may hold up the publisher, but the publisher does not wait for
+// the handler itself. Errors reported by the handler are therefore not
+// returned by Publish, but are reported as for asynchronous handlers.
+// Submitted calls that have not yet returned are waited for by Drain and
+// Close.
+func (b *Bus) SubscribeOn(topic interface{}, submit func(func()), h Handler) UnsubscribeFunc {
+	mustHandler(h)
+	return b.Subscribe(topic, &submitHandler{submit: submit, h: h})
+}
+
+// SubscribeOn causes the passed Handler to be called when data is published
+// to the named topic on the default Bus, passing a function that calls it to
+// submit.
+func SubscribeOn(topic interface{}, submit func(func()), h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeOn(topic, submit, h)
+}
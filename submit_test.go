@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeOn(t *testing.T) {
+	bus := NewBus()
+	loop := make(chan func(), 10)
+	var got []interface{}
+	bus.SubscribeOn("test", func(fn func()) {
+		loop <- fn
+	}, HandlerFunc(func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	}))
+
+	n, err := bus.Publish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	bus.Publish("test", 2, Async)
+
+	// Run the loop until both calls have been made
+	done := make(chan struct{})
+	go func() {
+		bus.Drain()
+		close(done)
+	}()
+	for len(got) < 2 {
+		(<-loop)()
+	}
+	<-done
+	assert.ElementsMatch(t, []interface{}{1, 2}, got)
+}
+
+func TestSubscribeOnAccept(t *testing.T) {
+	bus := NewBus()
+	var submitted int
+	h := &mockHandler{}
+	bus.SubscribeOn("test", func(fn func()) {
+		submitted++
+		fn()
+	}, NewRateLimitedHandler(time.Hour, h))
+
+	bus.Publish("test", 1)
+	n, _ := bus.Publish("test", 2)
+	assert.Equal(t, 0, n, "values declined by the handler should not be counted")
+	assert.Equal(t, 1, submitted, "declined values should not be submitted")
+	assert.Equal(t, 1, h.v)
+}
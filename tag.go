@@ -0,0 +1,129 @@
+package bus
+
+import (
+	"sync/atomic"
+)
+
+// tagHandler wraps a handler subscribed with SubscribeTagged, so that all
+// the handlers with a tag can be paused together.
+type tagHandler struct {
+	paused atomic.Bool
+	h      Handler
+}
+
+func (h *tagHandler) accept(b *Bus, t, v interface{}) bool {
+	if h.paused.Load() {
+		return false
+	}
+	if a, ok := h.h.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *tagHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *tagHandler) OnErr(b *Bus, t, v interface{}) error {
+	return call(b, h.h, t, v)
+}
+
+func (h *tagHandler) unwrap() Handler {
+	return h.h
+}
+
+// SubscribeTagged causes the passed Handler to be called when data is
+// published to the named topic on this Bus, recording the subscription under
+// the given tag, such as the name of the plugin making it. All the handlers
+// subscribed with a tag, across every topic, can then be paused, resumed or
+// removed together with PauseTagged, ResumeTagged and RemoveTagged. It
+// returns a function that can be called to unsubscribe the handler alone.
+func (b *Bus) SubscribeTagged(tag string, topic interface{}, h Handler) UnsubscribeFunc {
+	mustHandler(h)
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	s := b.subscribeLocked(topic, &tagHandler{h: h})
+	s.tag = tag
+	return b.unsubscribeFunc(s)
+}
+
+// RemoveTagged unsubscribes every handler subscribed to this Bus with the
+// given tag, returning the number of handlers removed.
+func (b *Bus) RemoveTagged(tag string) int {
+	b.lock.Lock()
+	defer b.unlock()
+
+	n := 0
+	for _, s := range b.ids {
+		if s.tag == tag && b.removeLocked(s) {
+			n++
+		}
+	}
+	return n
+}
+
+// PauseTagged stops every handler subscribed to this Bus with the given tag
+// being called until ResumeTagged is called with the tag, returning the
+// number of handlers paused. Values published meanwhile are not delivered to
+// the handlers, nor counted as deliveries by Publish. Handlers subscribed
+// with the tag later are not paused.
+func (b *Bus) PauseTagged(tag string) int {
+	return b.pauseTagged(tag, true)
+}
+
+// ResumeTagged causes every handler subscribed to this Bus with the given
+// tag to be called again after PauseTagged, returning the number of handlers
+// resumed.
+func (b *Bus) ResumeTagged(tag string) int {
+	return b.pauseTagged(tag, false)
+}
+
+// pauseTagged pauses or resumes the handlers subscribed with the tag.
+func (b *Bus) pauseTagged(tag string, paused bool) int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	n := 0
+	for _, s := range b.ids {
+		if s.tag != tag {
+			continue
+		}
+		h := s.handler
+		if nh, ok := h.(*nsHandler); ok {
+			h = nh.h
+		}
+		if th, ok := h.(*tagHandler); ok {
+			th.paused.Store(paused)
+			n++
+		}
+	}
+	return n
+}
+
+// SubscribeTagged causes the passed Handler to be called when data is
+// published to the named topic on the default Bus, recording the
+// subscription under the given tag.
+func SubscribeTagged(tag string, topic interface{}, h Handler) UnsubscribeFunc {
+	return getDefaultBus().SubscribeTagged(tag, topic, h)
+}
+
+// RemoveTagged unsubscribes every handler subscribed to the default Bus with
+// the given tag.
+func RemoveTagged(tag string) int {
+	return getDefaultBus().RemoveTagged(tag)
+}
+
+// PauseTagged pauses every handler subscribed to the default Bus with the
+// given tag.
+func PauseTagged(tag string) int {
+	return getDefaultBus().PauseTagged(tag)
+}
+
+// ResumeTagged resumes every handler subscribed to the default Bus with the
+// given tag.
+func ResumeTagged(tag string) int {
+	return getDefaultBus().ResumeTagged(tag)
+}
@@ -0,0 +1,51 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeTagged(t *testing.T) {
+	bus := NewBus()
+	calls := map[string]int{}
+	handler := func(name string) Handler {
+		return HandlerFunc(func(b *Bus, tp, v interface{}) { calls[name]++ })
+	}
+	bus.SubscribeTagged("plugin", "a", handler("pa"))
+	unsub := bus.SubscribeTagged("plugin", "b", handler("pb"))
+	bus.Namespace("ns").SubscribeTagged("plugin", "a", handler("pns"))
+	bus.SubscribeTagged("other", "a", handler("oa"))
+
+	bus.Publish("a", 1)
+	bus.Publish("b", 1)
+	bus.Publish("ns.a", 1)
+	assert.Equal(t, map[string]int{"pa": 1, "pb": 1, "pns": 1, "oa": 1}, calls)
+
+	assert.Equal(t, 3, bus.PauseTagged("plugin"))
+	n, _ := bus.Publish("a", 2)
+	assert.Equal(t, 1, n, "paused handlers should not be counted")
+	bus.Publish("b", 2)
+	bus.Publish("ns.a", 2)
+	assert.Equal(t, map[string]int{"pa": 1, "pb": 1, "pns": 1, "oa": 2}, calls)
+
+	assert.Equal(t, 3, bus.ResumeTagged("plugin"))
+	bus.Publish("a", 3)
+	assert.Equal(t, 2, calls["pa"])
+
+	assert.True(t, unsub(), "tagged handlers can be unsubscribed alone")
+	assert.Equal(t, 2, bus.RemoveTagged("plugin"))
+	assert.Equal(t, 0, bus.RemoveTagged("plugin"))
+	assert.Equal(t, 1, bus.SubscriberCount("a"))
+	assert.Equal(t, 0, bus.SubscriberCount("ns.a"))
+	assert.Equal(t, 0, bus.PauseTagged("plugin"))
+}
+
+func TestSubscribeTaggedResolve(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	bus.SubscribeTagged("plugin", "test", h)
+
+	assert.Equal(t, []Handler{h}, bus.Resolve("test"), "the handler should be returned as subscribed")
+	assert.Equal(t, []Handler{h}, bus.RemoveTopicReturning("test"))
+}
@@ -0,0 +1,101 @@
+package bus
+
+// teeHandler republishes each value it receives to another topic.
+type teeHandler struct {
+	from, to interface{}
+}
+
+func (h *teeHandler) On(b *Bus, t, v interface{}) {
+	for _, seen := range b.teed {
+		if seen == h.to {
+			// The value has already passed through the destination topic
+			return
+		}
+	}
+
+	b.forwardView(h.from).Publish(h.to, v)
+}
+
+// forwardView returns the view of the Bus through which a value received
+// from the topic from is republished. It is a copy of this view, so that the
+// value is still delivered as it was published, such as by PublishSync, but
+// records the topic the value is forwarded from and drops what only applies
+// to the delivery from it. Forwarded topics are already qualified.
+func (b *Bus) forwardView(from interface{}) *Bus {
+	fb := *b
+	fb.prefix = ""
+	fb.teed = append(b.teed[:len(b.teed):len(b.teed)], from)
+	fb.origin = b.originOf(from)
+	fb.meta, fb.topicSem = nil, nil
+	return &fb
+}
+
+// originOf returns the topic that the value being forwarded from the topic
+// from was first published to, so that it can be reported as the
+// OriginalTopic of the publishes it is forwarded by.
+func (b *Bus) originOf(from interface{}) interface{} {
+	if b.origin != nil {
+		return b.origin
+	}
+	if b.meta != nil {
+		return b.qualify(b.meta.OriginalTopic)
+	}
+	return from
+}
+
+// Tee causes each value published to the topic from on this Bus to be
+// published to the topic to as well, returning a function that stops it.
+// Values are republished synchronously by a handler on from, so they reach
+// the handlers of to before Publish returns, unless published with Async.
+//
+// A value is never forwarded into a topic it has already been forwarded
+// from, so tees that form a cycle, such as A to B and B to A, deliver each
+// value to every topic in the cycle once rather than looping forever.
+func (b *Bus) Tee(from, to interface{}) UnsubscribeFunc {
+	return b.Subscribe(from, &teeHandler{from: b.qualify(from), to: b.qualify(to)})
+}
+
+// Tee causes each value published to the topic from on the default Bus to
+// be published to the topic to as well, returning a function that stops it.
+func Tee(from, to interface{}) UnsubscribeFunc {
+	return getDefaultBus().Tee(from, to)
+}
+
+// transformHandler publishes a value derived from each value it receives to
+// another topic.
+type transformHandler struct {
+	teeHandler
+	f func(v interface{}) (interface{}, bool)
+}
+
+func (h *transformHandler) On(b *Bus, t, v interface{}) {
+	for _, seen := range b.teed {
+		if seen == h.to {
+			// The value has already passed through the destination topic
+			return
+		}
+	}
+
+	if v, ok := h.f(v); ok {
+		b.forwardView(h.from).Publish(h.to, v)
+	}
+}
+
+// SubscribeTransform causes f to be called with each value published to the
+// topic from on this Bus, publishing the value it returns to the topic to if
+// it also returns true, and nothing otherwise. It returns a function that
+// stops it. Like Tee, values are republished synchronously, and transforms
+// that form a cycle publish to each topic in the cycle once rather than
+// looping forever.
+func (b *Bus) SubscribeTransform(from, to interface{}, f func(v interface{}) (interface{}, bool)) UnsubscribeFunc {
+	return b.Subscribe(from, &transformHandler{
+		teeHandler: teeHandler{from: b.qualify(from), to: b.qualify(to)},
+		f:          f,
+	})
+}
+
+// SubscribeTransform causes f to be called with each value published to the
+// topic from on the default Bus, publishing its result to the topic to.
+func SubscribeTransform(from, to interface{}, f func(v interface{}) (interface{}, bool)) UnsubscribeFunc {
+	return getDefaultBus().SubscribeTransform(from, to, f)
+}
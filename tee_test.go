@@ -0,0 +1,116 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTee(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.SubscribeFunc("audit", func(b *Bus, tp, v interface{}) {
+		got = append(got, tp, v)
+	})
+
+	unsub := bus.Tee("orders.new", "audit")
+	n, err := bus.Publish("orders.new", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "tee should count as a handler")
+	assert.Equal(t, []interface{}{"audit", 1}, got)
+
+	assert.True(t, unsub())
+	bus.Publish("orders.new", 2)
+	assert.Len(t, got, 2, "value should not be forwarded after unsubscribing")
+}
+
+func TestTeeCycle(t *testing.T) {
+	bus := NewBus()
+	counts := map[interface{}]int{}
+	record := func(b *Bus, tp, v interface{}) {
+		counts[tp]++
+	}
+	bus.SubscribeFunc("a", record)
+	bus.SubscribeFunc("b", record)
+	bus.SubscribeFunc("c", record)
+
+	bus.Tee("a", "b")
+	bus.Tee("b", "c")
+	bus.Tee("c", "a")
+
+	bus.Publish("a", 1)
+	assert.Equal(t, map[interface{}]int{"a": 1, "b": 1, "c": 1}, counts)
+
+	bus.Publish("b", 2)
+	assert.Equal(t, map[interface{}]int{"a": 2, "b": 2, "c": 2}, counts)
+}
+
+func TestTeeNamespace(t *testing.T) {
+	bus := NewBus()
+	ns := bus.Namespace("ns")
+	counts := map[interface{}]int{}
+	ns.SubscribeFunc("b", func(b *Bus, tp, v interface{}) {
+		counts[tp]++
+	})
+
+	ns.Tee("a", "b")
+	ns.Tee("b", "a")
+
+	bus.Publish("ns.a", 1)
+	assert.Equal(t, map[interface{}]int{"b": 1}, counts)
+}
+
+func TestSubscribeTransform(t *testing.T) {
+	bus := NewBus()
+	h := &mockHandler{}
+	bus.Subscribe("doubled", h)
+
+	unsub := bus.SubscribeTransform("numbers", "doubled", func(v interface{}) (interface{}, bool) {
+		n, ok := v.(int)
+		return n * 2, ok
+	})
+	bus.Publish("numbers", 2)
+	assert.Equal(t, 4, h.v)
+
+	bus.Publish("numbers", "two")
+	assert.Equal(t, 4, h.v, "rejected values should not be published")
+
+	assert.True(t, unsub())
+	bus.Publish("numbers", 3)
+	assert.Equal(t, 4, h.v)
+}
+
+func TestSubscribeTransformCycle(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.SubscribeFunc("b", func(b *Bus, tp, v interface{}) {
+		got = append(got, v)
+	})
+
+	inc := func(v interface{}) (interface{}, bool) {
+		return v.(int) + 1, true
+	}
+	bus.SubscribeTransform("a", "b", inc)
+	bus.SubscribeTransform("b", "a", inc)
+
+	bus.Publish("a", 1)
+	assert.Equal(t, []interface{}{2}, got, "cycle should not loop")
+}
+
+func TestTeePublishSync(t *testing.T) {
+	bus := NewBus()
+	id := goroutineID()
+	calls := 0
+	record := HandlerFunc(func(b *Bus, tp, v interface{}) {
+		assert.Equal(t, id, goroutineID(), "handler should be called by the publisher")
+		calls++
+	})
+	bus.Tee("a", "b")
+	bus.SubscribeTransform("a", "c", func(v interface{}) (interface{}, bool) { return v, true })
+	bus.SubscribeAsync("b", record)
+	bus.SubscribeAsync("c", record)
+
+	_, err := bus.PublishSync("a", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "forwarded values should be delivered synchronously too")
+}
@@ -0,0 +1,96 @@
+package bus
+
+import (
+	"reflect"
+)
+
+// Topic is a topic name that carries the type of the values published to it,
+// so that publishers and subscribers can be checked at compile time:
+//
+//	const Kills bus.Topic[Kill] = "kills"
+//
+//	Kills.Subscribe(b, func(k Kill) {
+//		log.Printf("You killed %s", k.Victim)
+//	})
+//	Kills.Publish(b, Kill{Victim: "Breen"})
+//
+// Values are published under the topic's name, so they may also be published
+// and subscribed to via the untyped Bus methods using the plain string.
+type Topic[T any] string
+
+// String returns the name of the topic.
+func (t Topic[T]) String() string {
+	return string(t)
+}
+
+// Subscribe causes fn to be called with each value published to this topic
+// on the given Bus. Values that are not of type T, which can only be published
+// using the untyped Bus methods, are dropped and not counted as deliveries.
+// It returns a function that can be called to unsubscribe.
+func (t Topic[T]) Subscribe(b *Bus, fn func(v T)) UnsubscribeFunc {
+	return b.SubscribeFilter(string(t), func(v interface{}) bool {
+		_, ok := v.(T)
+		return ok
+	}, HandlerFunc(func(b *Bus, _, v interface{}) {
+		fn(v.(T))
+	}))
+}
+
+// Publish sends the given value to all handlers subscribed to this topic on
+// the given Bus.
+func (t Topic[T]) Publish(b *Bus, v T) (int, error) {
+	return b.Publish(string(t), v)
+}
+
+// RegisterTopicType causes values published to the named topic on this Bus to
+// be checked against the given type, with Publish failing with an error
+// wrapping ErrPayloadType for values that are not assignable to it. A nil
+// value is only accepted by types that can be nil, such as interfaces and
+// pointers. Passing a nil type removes the check.
+//
+// To register an interface type, use reflect.TypeOf((*I)(nil)).Elem().
+func (b *Bus) RegisterTopicType(topic interface{}, typ reflect.Type) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.stateLocked(b.qualify(topic)).typ = typ
+}
+
+// RegisterTopicType causes values published to the named topic on the
+// default Bus to be checked against the given type.
+func RegisterTopicType(topic interface{}, typ reflect.Type) {
+	getDefaultBus().RegisterTopicType(topic, typ)
+}
+
+// assignable reports whether the value may be published to a topic of the
+// given type.
+func assignable(v interface{}, typ reflect.Type) bool {
+	if v == nil {
+		switch typ.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+			return true
+		}
+		return false
+	}
+	return reflect.TypeOf(v).AssignableTo(typ)
+}
+
+// validTopic reports whether the topic can be used as a map key, and so
+// subscribed and published to. Topics of common types are accepted without
+// reflection.
+func validTopic(topic interface{}) bool {
+	switch topic.(type) {
+	case nil, string, int, int64, uint64:
+		return true
+	}
+	return reflect.ValueOf(topic).Comparable()
+}
+
+// validTopic reports whether the topic can be subscribed and published to on
+// this Bus, which also rejects nil if created with WithRejectNilTopic.
+func (b *Bus) validTopic(topic interface{}) bool {
+	if topic == nil {
+		return !b.rejectNil
+	}
+	return validTopic(topic)
+}
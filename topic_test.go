@@ -0,0 +1,87 @@
+package bus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testKills Topic[kill] = "kills"
+
+func TestTopic(t *testing.T) {
+	bus := NewBus()
+	var got []kill
+	unsub := testKills.Subscribe(bus, func(k kill) {
+		got = append(got, k)
+	})
+
+	n, err := testKills.Publish(bus, kill{Victim: "Breen"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = bus.Publish("kills", kill{Victim: "Vortigaunt"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n, "untyped publishes of the right type should be delivered")
+
+	n, err = bus.Publish("kills", "not a kill")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "mismatched types should be dropped")
+
+	assert.Equal(t, []kill{{Victim: "Breen"}, {Victim: "Vortigaunt"}}, got)
+	assert.Equal(t, "kills", testKills.String())
+
+	assert.True(t, unsub())
+	n, _ = testKills.Publish(bus, kill{})
+	assert.Equal(t, 0, n)
+}
+
+type orderTopic string
+
+func TestNamedTopicTypes(t *testing.T) {
+	bus := NewBus()
+	var got []interface{}
+	bus.SubscribeFunc(orderTopic("foo"), func(b *Bus, tp, v interface{}) {
+		got = append(got, tp)
+	})
+
+	n, _ := bus.Publish("foo", 1)
+	assert.Equal(t, 0, n, "plain string topic should be distinct")
+	n, _ = bus.Publish(orderTopic("foo"), 2)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []interface{}{orderTopic("foo")}, got)
+}
+
+func TestRegisterTopicType(t *testing.T) {
+	bus := NewBus()
+	bus.SubscribeFunc(orderTopic("orders"), func(b *Bus, tp, v interface{}) {})
+	bus.RegisterTopicType(orderTopic("orders"), reflect.TypeOf(kill{}))
+
+	n, err := bus.Publish(orderTopic("orders"), kill{Victim: "Breen"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = bus.Publish(orderTopic("orders"), "Breen")
+	assert.True(t, errors.Is(err, ErrPayloadType))
+	assert.Equal(t, 0, n)
+
+	_, err = bus.Publish(orderTopic("orders"), nil)
+	assert.True(t, errors.Is(err, ErrPayloadType), "nil should not be a struct")
+
+	bus.RegisterTopicType(orderTopic("orders"), nil)
+	_, err = bus.Publish(orderTopic("orders"), "Breen")
+	assert.NoError(t, err, "check should be removable")
+}
+
+func TestRegisterTopicTypeInterface(t *testing.T) {
+	bus := NewBus()
+	bus.RegisterTopicType("errors", reflect.TypeOf((*error)(nil)).Elem())
+
+	_, err := bus.Publish("errors", errors.New("oops"))
+	assert.NoError(t, err)
+	_, err = bus.Publish("errors", nil)
+	assert.NoError(t, err)
+	_, err = bus.Publish("errors", 1)
+	assert.True(t, errors.Is(err, ErrPayloadType))
+}
@@ -0,0 +1,84 @@
+package bus
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// errBackPressure is returned by call when a TryHandler cannot accept a
+// value immediately.
+var errBackPressure = errors.New("bus: back-pressure")
+
+// TryHandler is a Handler that can refuse a value it cannot accept without
+// blocking, such as a ChanHandler whose channel is full.
+type TryHandler interface {
+	Handler
+
+	// TryOn is called in place of On by TryPublish, returning false if the
+	// value could not be accepted immediately.
+	TryOn(b *Bus, t, v interface{}) bool
+}
+
+// tryHandler counts the values its handler refuses while they are delivered
+// by TryPublish, as dropped rather than failed.
+type tryHandler struct {
+	h       Handler
+	refused *atomic.Int64
+	stats   *topicStats
+}
+
+func (h *tryHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *tryHandler) OnErr(b *Bus, t, v interface{}) error {
+	err := call(b, h.h, t, v)
+	if errors.Is(err, errBackPressure) {
+		h.refused.Add(1)
+		h.stats.drop(1)
+		return nil
+	}
+	return err
+}
+
+func (h *tryHandler) unwrap() Handler {
+	return h.h
+}
+
+// TryPublish sends the given value to the handlers subscribed to the named
+// topic on this Bus that can accept it immediately, delivering it as Publish
+// does. TryHandlers are called through TryOn, and those that refuse the
+// value are counted as dropped rather than delivered; other handlers are
+// always considered able to accept it. Handlers subscribed with
+// SubscribeAsync are counted as delivered once they have been started, and
+// are only counted as dropped in the Stats of the topic if they refuse the
+// value. Errors reported by ErrHandlers are returned as by Publish.
+func (b *Bus) TryPublish(topic, value interface{}) (delivered int, dropped int, err error) {
+	d, ok, err := b.prepare(topic, value, nil)
+	if !ok {
+		return 0, 0, err
+	}
+	b.logPublish(&d)
+
+	var refused atomic.Int64
+	d.refused = &refused
+	tb := &Bus{core: b.core, prefix: b.prefix, teed: b.teed, try: true}
+	n, err := tb.publishDelivery(d, 0)
+
+	dropped = int(refused.Load())
+	if dropped > 0 {
+		// Refusing handlers were counted as delivered
+		d.state.stats.delivered.Add(^uint64(dropped - 1))
+	}
+	delivered = n - dropped
+	for _, o := range b.observers {
+		o.OnPublish(d.topic, d.value, delivered)
+	}
+	return delivered, dropped, err
+}
+
+// TryPublish sends the given value to the handlers subscribed to the named
+// topic on the default Bus that can accept it immediately.
+func TryPublish(topic, value interface{}) (delivered int, dropped int, err error) {
+	return getDefaultBus().TryPublish(topic, value)
+}
@@ -0,0 +1,92 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryPublish(t *testing.T) {
+	bus := NewBus()
+	ch := NewChanHandler(1)
+	bus.Subscribe("test", ch)
+	calls := 0
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		calls++
+	})
+
+	delivered, dropped, err := bus.TryPublish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, 0, dropped)
+
+	delivered, dropped, err = bus.TryPublish("test", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered, "plain handlers should always be deliverable")
+	assert.Equal(t, 1, dropped, "full channel should be dropped")
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, <-ch.C())
+	assert.Equal(t, uint64(1), ch.Dropped())
+	assert.Equal(t, uint64(1), bus.Stats()["test"].DroppedCount)
+}
+
+func TestTryPublishWrapped(t *testing.T) {
+	bus := NewBus()
+	c, unsub := bus.Namespace("ns").SubscribeChan("test", 0)
+	defer unsub()
+
+	delivered, dropped, _ := bus.TryPublish("ns.test", 1)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, 1, dropped, "back-pressure should be seen through namespaces")
+	assert.Len(t, c, 0)
+}
+
+func TestTryPublishErrors(t *testing.T) {
+	bus := NewBus()
+	errFail := errors.New("fail")
+	bus.Subscribe("test", &failingHandler{err: errFail})
+
+	delivered, dropped, err := bus.TryPublish("test", 1)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 0, dropped)
+	assert.True(t, errors.Is(err, errFail))
+}
+
+func TestTryPublishNested(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("other", NewChanHandler(0))
+	var nested error
+	bus.SubscribeFunc("test", func(b *Bus, tp, v interface{}) {
+		_, nested = b.Publish("other", v)
+	})
+
+	delivered, dropped, err := bus.TryPublish("test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, 0, dropped)
+	assert.NoError(t, nested, "a plain publish from a handler should not refuse values")
+}
+
+func TestTryPublishDelivery(t *testing.T) {
+	bus := NewBus(WithMaxDepth(2))
+	calls := 0
+	bus.SubscribeFunc("loop", func(b *Bus, tp, v interface{}) {
+		calls++
+		b.TryPublish("loop", v)
+	})
+	_, _, err := bus.TryPublish("loop", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "the maximum depth should apply")
+
+	release := make(chan struct{})
+	bus.SubscribeAsync("async", HandlerFunc(func(b *Bus, tp, v interface{}) {
+		<-release
+	}))
+	delivered, _, err := bus.TryPublish("async", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered, "async handlers should be started rather than waited for")
+	close(release)
+	bus.Drain()
+}
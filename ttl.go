@@ -0,0 +1,91 @@
+package bus
+
+import (
+	"time"
+)
+
+// idleLocked starts the idle time of the topic, which has just lost its last
+// handler, if the Bus has a topic TTL. It must be called with the write lock
+// held.
+func (b *Bus) idleLocked(topic interface{}) {
+	if st := b.states[topic]; st != nil && b.ttl > 0 {
+		st.idle = b.clock.Now()
+	}
+}
+
+// startSweeper arranges for idle topics to be swept from the Bus every half
+// of its TTL until it is closed.
+func (b *Bus) startSweeper() {
+	interval := b.ttl / 2
+	if interval <= 0 {
+		interval = b.ttl
+	}
+	var sweep func()
+	sweep = func() {
+		b.Sweep()
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		if !b.closed {
+			b.sweepTimer = b.clock.AfterFunc(interval, sweep)
+		}
+	}
+	b.sweepTimer = b.clock.AfterFunc(interval, sweep)
+}
+
+// Sweep discards the statistics of topics on this Bus that have had no
+// handlers for longer than the Bus's topic TTL, as set by WithTopicTTL,
+// returning the number of topics swept. On a Bus without a TTL, the
+// statistics of every topic without handlers are discarded.
+//
+// Topics that have been configured, such as with Retain, SetTransform,
+// RegisterTopicType, SetTopicRateLimit or PublishSeq, or that have values
+// being delivered to them, are never swept, so that sweeping does not change
+// how the Bus behaves.
+//
+// Sweep is called periodically on a Bus with a TTL, but may be called at any
+// time.
+func (b *Bus) Sweep() int {
+	now := b.clock.Now()
+
+	b.lock.Lock()
+	n := 0
+	for topic, st := range b.states {
+		if len(b.topics[topic]) > 0 {
+			st.idle = time.Time{}
+			continue
+		}
+		if st.idle.IsZero() {
+			st.idle = now
+		}
+		if now.Sub(st.idle) < b.ttl || st.configured() {
+			continue
+		}
+		delete(b.states, topic)
+		n++
+	}
+	b.lock.Unlock()
+	return n
+}
+
+// configured reports whether the topic has settings or data other than its
+// statistics, or is being delivered to. It must be called with the write
+// lock held.
+func (st *topicState) configured() bool {
+	if st.reserve > 0 || st.serialize || st.sem != nil ||
+		st.transform != nil || st.ordered != nil || st.retain ||
+		st.hasRetained || st.typ != nil || st.history != nil ||
+		st.limiter != nil || st.hasSeq {
+		return true
+	}
+	if !st.serial.TryLock() {
+		return true
+	}
+	st.serial.Unlock()
+	return false
+}
+
+// Sweep discards the statistics of topics on the default Bus that have had
+// no handlers for longer than its topic TTL.
+func Sweep() int {
+	return getDefaultBus().Sweep()
+}
@@ -0,0 +1,79 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweep(t *testing.T) {
+	bus := NewBus(WithTopicTTL(time.Hour))
+	defer bus.Close()
+	bus.Publish("idle", 1)
+
+	assert.Equal(t, 0, bus.Sweep(), "topics should not be swept before the TTL has passed")
+	assert.Contains(t, bus.Stats(), "idle")
+
+	// Without a TTL, every topic without handlers is swept
+	bus = NewBus()
+	bus.Publish("idle", 1)
+	unsub := bus.Subscribe("busy", &mockHandler{})
+	bus.Publish("busy", 1)
+	assert.Equal(t, 1, bus.Sweep())
+	assert.NotContains(t, bus.Stats(), "idle")
+	assert.Contains(t, bus.Stats(), "busy", "topics with handlers should be kept")
+
+	unsub()
+	assert.Equal(t, 1, bus.Sweep())
+	assert.Empty(t, bus.Stats())
+}
+
+func TestTopicTTL(t *testing.T) {
+	ttl := 20 * time.Millisecond
+	bus := NewBus(WithTopicTTL(ttl))
+	defer bus.Close()
+	unsub := bus.Subscribe("test", &mockHandler{})
+	bus.Publish("test", 1)
+
+	// Subscribed topics are kept however long they live
+	time.Sleep(2 * ttl)
+	assert.Contains(t, bus.Stats(), "test")
+
+	unsub()
+	assert.Eventually(t, func() bool {
+		_, ok := bus.Stats()["test"]
+		return !ok
+	}, time.Second, ttl/4, "idle topic should be swept in the background")
+}
+
+func TestTopicTTLResetBySubscribe(t *testing.T) {
+	ttl := 20 * time.Millisecond
+	bus := NewBus()
+	bus.ttl = ttl // Sweep by hand, without the background sweeper
+	bus.Publish("test", 1)
+	unsub := bus.Subscribe("test", &mockHandler{})
+	unsub()
+	time.Sleep(ttl)
+	bus.Subscribe("test", &mockHandler{})()
+	assert.Equal(t, 0, bus.Sweep(), "subscribing should reset the idle time")
+	time.Sleep(ttl)
+	assert.Equal(t, 1, bus.Sweep())
+}
+
+func TestSweepConfigured(t *testing.T) {
+	bus := NewBus()
+	bus.Retain("retained")
+	bus.Publish("retained", 1)
+	bus.SetTransform("transformed", func(v interface{}) interface{} { return v })
+	bus.SetTopicRateLimit("limited", 1, 1)
+	bus.PublishSeq("seq", 1, 1)
+	bus.Publish("plain", 1)
+
+	assert.Equal(t, 1, bus.Sweep(), "only topics without settings should be swept")
+	assert.Equal(t, map[interface{}]interface{}{"retained": 1}, bus.ExportRetained())
+	assert.Equal(t, uint64(1), bus.LastSeq("seq"))
+	bus.Publish("limited", 1)
+	_, err := bus.Publish("limited", 2)
+	assert.Equal(t, ErrRateLimited, err, "the rate limit should survive the sweep")
+}
@@ -0,0 +1,43 @@
+package bus
+
+import (
+	"fmt"
+)
+
+// As returns the value as a T, and whether it is one. It is shorthand for the
+// type assertion v.(T) that handlers otherwise repeat for each value.
+func As[T any](v interface{}) (T, bool) {
+	t, ok := v.(T)
+	return t, ok
+}
+
+// MustAs returns the value as a T, panicking if it is not one.
+func MustAs[T any](v interface{}) T {
+	t, ok := v.(T)
+	if !ok {
+		panic(fmt.Sprintf("bus: value of type %T is not a %T", v, t))
+	}
+	return t
+}
+
+// TypedHandlerFunc is an adaptor to allow the use of a function taking values
+// of a particular type as a Handler. Values that are not of type T are
+// dropped and not counted as deliveries, so the function is only called with
+// those that are:
+//
+//	b.Subscribe("kills", bus.TypedHandlerFunc[Kill](func(b *bus.Bus, t interface{}, k Kill) {
+//		log.Printf("You killed %s", k.Victim)
+//	}))
+type TypedHandlerFunc[T any] func(b *Bus, t interface{}, v T)
+
+func (f TypedHandlerFunc[T]) accept(b *Bus, t, v interface{}) bool {
+	_, ok := v.(T)
+	return ok
+}
+
+// On calls f(b, t, v) if v is of type T.
+func (f TypedHandlerFunc[T]) On(b *Bus, t, v interface{}) {
+	if tv, ok := v.(T); ok {
+		f(b, t, tv)
+	}
+}
@@ -0,0 +1,46 @@
+package bus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAs(t *testing.T) {
+	k, ok := As[kill](kill{Victim: "Breen"})
+	assert.True(t, ok)
+	assert.Equal(t, kill{Victim: "Breen"}, k)
+
+	k, ok = As[kill]("not a kill")
+	assert.False(t, ok)
+	assert.Zero(t, k)
+
+	_, ok = As[error](nil)
+	assert.False(t, ok, "nil should not match an interface type")
+}
+
+func TestMustAs(t *testing.T) {
+	assert.Equal(t, 42, MustAs[int](42))
+	assert.PanicsWithValue(t, "bus: value of type string is not a int", func() {
+		MustAs[int]("42")
+	})
+}
+
+func TestTypedHandlerFunc(t *testing.T) {
+	bus := NewBus()
+	var got []kill
+	bus.Subscribe("kills", TypedHandlerFunc[kill](func(b *Bus, topic interface{}, k kill) {
+		assert.Equal(t, "kills", topic)
+		got = append(got, k)
+	}))
+
+	n, err := bus.Publish("kills", kill{Victim: "Breen"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = bus.Publish("kills", "not a kill")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "mismatched types should be dropped")
+
+	assert.Equal(t, []kill{{Victim: "Breen"}}, got)
+}
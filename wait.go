@@ -0,0 +1,79 @@
+package bus
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WaitFor blocks until a value is next published to the named topic on this
+// Bus, returning that value, or ErrTimeout if no value is published within
+// the given timeout. Concurrent calls on the same topic each receive the next
+// value independently.
+func (b *Bus) WaitFor(topic interface{}, timeout time.Duration) (interface{}, error) {
+	c := make(chan interface{}, 1)
+	unsub := b.OnceFunc(topic, func(b *Bus, t, v interface{}) {
+		c <- v
+	})
+	defer unsub()
+
+	expired, stop := b.after(timeout)
+	defer stop()
+
+	select {
+	case v := <-c:
+		return v, nil
+	case <-expired:
+		return nil, ErrTimeout
+	}
+}
+
+// WaitAny blocks until a value is next published to any of the named topics
+// on this Bus, returning the topic, as passed to WaitAny, and the value, or
+// ErrTimeout if no value is published to any of them within the given
+// timeout. Exactly one value is received, even if values are published to
+// several of the topics concurrently; the others are declined and not
+// counted as deliveries. All of the topics are unsubscribed before WaitAny
+// returns.
+func (b *Bus) WaitAny(timeout time.Duration, topics ...interface{}) (interface{}, interface{}, error) {
+	type received struct {
+		topic, value interface{}
+	}
+	c := make(chan received, 1)
+	var claimed atomic.Bool
+	unsubs := make([]UnsubscribeFunc, len(topics))
+	for i, topic := range topics {
+		topic := topic
+		unsubs[i] = b.SubscribeFilter(topic, func(v interface{}) bool {
+			return claimed.CompareAndSwap(false, true)
+		}, HandlerFunc(func(b *Bus, t, v interface{}) {
+			c <- received{topic, v}
+		}))
+	}
+	defer func() {
+		for _, unsub := range unsubs {
+			unsub()
+		}
+	}()
+
+	expired, stop := b.after(timeout)
+	defer stop()
+
+	select {
+	case r := <-c:
+		return r.topic, r.value, nil
+	case <-expired:
+		return nil, nil, ErrTimeout
+	}
+}
+
+// WaitFor blocks until a value is next published to the named topic on the
+// default Bus, or until the timeout elapses.
+func WaitFor(topic interface{}, timeout time.Duration) (interface{}, error) {
+	return getDefaultBus().WaitFor(topic, timeout)
+}
+
+// WaitAny blocks until a value is next published to any of the named topics
+// on the default Bus, or until the timeout elapses.
+func WaitAny(timeout time.Duration, topics ...interface{}) (interface{}, interface{}, error) {
+	return getDefaultBus().WaitAny(timeout, topics...)
+}
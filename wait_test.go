@@ -0,0 +1,115 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitFor(t *testing.T) {
+	bus := NewBus()
+	go func() {
+		for bus.SubscriberCount("test") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		bus.Publish("test", "hello")
+	}()
+
+	v, err := bus.WaitFor("test", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", v)
+	assert.Equal(t, 0, bus.SubscriberCount("test"), "waiter should unsubscribe")
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	bus := NewBus()
+	v, err := bus.WaitFor("test", 10*time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+	assert.Nil(t, v)
+	assert.Equal(t, 0, bus.SubscriberCount("test"), "waiter should unsubscribe")
+}
+
+func TestWaitForConcurrent(t *testing.T) {
+	bus := NewBus()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := bus.WaitFor("test", time.Second)
+			assert.NoError(t, err)
+			assert.Equal(t, "hello", v)
+		}()
+	}
+
+	for bus.SubscriberCount("test") < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n, "each waiter should receive the value")
+	wg.Wait()
+}
+
+func TestWaitAny(t *testing.T) {
+	bus := NewBus()
+	go func() {
+		for bus.SubscriberCount("b") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		bus.Publish("b", "hello")
+	}()
+
+	topic, v, err := bus.WaitAny(time.Second, "a", "b", "c")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", topic)
+	assert.Equal(t, "hello", v)
+	for _, topic := range []string{"a", "b", "c"} {
+		assert.Equal(t, 0, bus.SubscriberCount(topic), "waiter should unsubscribe from %s", topic)
+	}
+}
+
+func TestWaitAnyTimeout(t *testing.T) {
+	bus := NewBus()
+	topic, v, err := bus.WaitAny(10*time.Millisecond, "a", "b")
+	assert.Equal(t, ErrTimeout, err)
+	assert.Nil(t, topic)
+	assert.Nil(t, v)
+	assert.Equal(t, 0, bus.SubscriberCount("a"))
+	assert.Equal(t, 0, bus.SubscriberCount("b"))
+}
+
+func TestWaitAnyConcurrent(t *testing.T) {
+	bus := NewBus()
+	topics := []interface{}{"a", "b", "c", "d"}
+	var total atomic.Int64
+	go func() {
+		for bus.SubscriberCount("d") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		wg := sync.WaitGroup{}
+		for _, topic := range topics {
+			wg.Add(1)
+			go func(topic interface{}) {
+				defer wg.Done()
+				n, _ := bus.Publish(topic, topic)
+				total.Add(int64(n))
+			}(topic)
+		}
+		wg.Wait()
+		bus.Publish("done", nil)
+	}()
+
+	waitDone := make(chan struct{})
+	bus.OnceFunc("done", func(b *Bus, t, v interface{}) {
+		close(waitDone)
+	})
+	topic, v, err := bus.WaitAny(time.Second, topics...)
+	assert.NoError(t, err)
+	assert.Equal(t, topic, v, "value should come from the reported topic")
+	<-waitDone
+	assert.Equal(t, int64(1), total.Load(), "exactly one value should be delivered")
+}
@@ -0,0 +1,79 @@
+package bus
+
+import (
+	"reflect"
+	"unsafe"
+	"weak"
+)
+
+// weakHandler refers to a handler without preventing it from being garbage
+// collected.
+type weakHandler struct {
+	typ reflect.Type
+	ptr weak.Pointer[byte]
+	id  SubscriptionID
+}
+
+// get returns the handler, or nil if it has been collected.
+func (h *weakHandler) get() Handler {
+	p := h.ptr.Value()
+	if p == nil {
+		return nil
+	}
+	return reflect.NewAt(h.typ.Elem(), unsafe.Pointer(p)).Interface().(Handler)
+}
+
+func (h *weakHandler) accept(b *Bus, t, v interface{}) bool {
+	hh := h.get()
+	if hh == nil {
+		// Handler has been collected, so prune its subscription
+		b.UnsubscribeID(h.id)
+		return false
+	}
+	if a, ok := hh.(acceptor); ok {
+		return a.accept(b, t, v)
+	}
+	return true
+}
+
+func (h *weakHandler) On(b *Bus, t, v interface{}) {
+	h.OnErr(b, t, v)
+}
+
+func (h *weakHandler) OnErr(b *Bus, t, v interface{}) error {
+	if hh := h.get(); hh != nil {
+		return call(b, hh, t, v)
+	}
+	return nil
+}
+
+func (h *weakHandler) unwrap() Handler {
+	return h.get()
+}
+
+// SubscribeWeak causes the passed Handler to be called when data is
+// published to the named topic on this Bus, without the Bus keeping the
+// handler alive. Once nothing else refers to the handler and it has been
+// garbage collected, it is no longer called, and its subscription is removed
+// the next time the topic is published to.
+//
+// The handler must be a non-nil pointer, or SubscribeWeak panics. As with
+// runtime.SetFinalizer, very small objects that contain no pointers may be
+// batched together by the allocator and never be collected.
+func (b *Bus) SubscribeWeak(topic interface{}, h Handler) UnsubscribeFunc {
+	rv := reflect.ValueOf(h)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		panic("bus: SubscribeWeak requires a non-nil pointer handler")
+	}
+	wh := &weakHandler{
+		typ: rv.Type(),
+		ptr: weak.Make((*byte)(rv.UnsafePointer())),
+	}
+
+	b.lock.Lock()
+	defer b.unlock()
+
+	s := b.subscribeLocked(topic, wh)
+	wh.id = s.id
+	return b.unsubscribeFunc(s)
+}
@@ -0,0 +1,60 @@
+package bus
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingHandler struct {
+	n    *int
+	name string
+}
+
+func (h *countingHandler) On(b *Bus, t, v interface{}) {
+	*h.n++
+}
+
+func TestSubscribeWeak(t *testing.T) {
+	bus := NewBus()
+	c := 0
+	h := &countingHandler{n: &c, name: "weak"}
+	bus.SubscribeWeak("test", h)
+
+	n, err := bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 1, c)
+	runtime.KeepAlive(h)
+
+	// Drop all references to the handler and collect it
+	h = nil
+	runtime.GC()
+	runtime.GC()
+
+	n, err = bus.Publish("test", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n, "collected handler should not be called")
+	assert.Equal(t, 1, c)
+	assert.Equal(t, 0, bus.SubscriberCount("test"), "collected handler should be pruned")
+}
+
+func TestSubscribeWeakUnsubscribe(t *testing.T) {
+	bus := NewBus()
+	c := 0
+	h := &countingHandler{n: &c}
+	unsub := bus.SubscribeWeak("test", h)
+	assert.True(t, unsub())
+
+	n, _ := bus.Publish("test", "hello")
+	assert.Equal(t, 0, n)
+	runtime.KeepAlive(h)
+}
+
+func TestSubscribeWeakNonPointer(t *testing.T) {
+	bus := NewBus()
+	assert.Panics(t, func() {
+		bus.SubscribeWeak("test", HandlerFunc(func(b *Bus, t, v interface{}) {}))
+	})
+}